@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"sync"
 	"unsafe"
@@ -48,6 +49,8 @@ type JSON struct {
 	RootNode     int
 	_intSlices   [][]int
 	_intSlicePos int
+	// sharedNodes is true for forks which haven't appended a node yet, see Forks
+	sharedNodes bool
 }
 
 func (j *JSON) Get(nodeRef int, path []string) int {
@@ -144,13 +147,12 @@ func (j *JSON) SetObjectField(nodeRef, setFieldNodeRef int, key string) bool {
 		}
 	}
 	j.storage = append(j.storage, key...)
-	j.Nodes = append(j.Nodes, Node{
+	objectFieldNodeRef := j.appendNode(Node{
 		Kind:             NodeKindObjectField,
 		ObjectFieldValue: setFieldNodeRef,
 		keyStart:         len(j.storage) - len(key),
 		keyEnd:           len(j.storage),
 	})
-	objectFieldNodeRef := len(j.Nodes) - 1
 	j.Nodes[nodeRef].ObjectFields = append(j.Nodes[nodeRef].ObjectFields, objectFieldNodeRef)
 	return false
 }
@@ -164,13 +166,12 @@ func (j *JSON) SetObjectFieldKeyBytes(nodeRef, setFieldNodeRef int, key []byte)
 		}
 	}
 	j.storage = append(j.storage, key...)
-	j.Nodes = append(j.Nodes, Node{
+	objectFieldNodeRef := j.appendNode(Node{
 		Kind:             NodeKindObjectField,
 		ObjectFieldValue: setFieldNodeRef,
 		keyStart:         len(j.storage) - len(key),
 		keyEnd:           len(j.storage),
 	})
-	objectFieldNodeRef := len(j.Nodes) - 1
 	j.Nodes[nodeRef].ObjectFields = append(j.Nodes[nodeRef].ObjectFields, objectFieldNodeRef)
 	return false
 }
@@ -270,6 +271,15 @@ func (j *JSON) AppendAnyJSONBytes(input []byte) (ref int, err error) {
 	start := len(j.storage)
 	j.storage = append(j.storage, input...)
 	jsonType := j.getJsonType(input)
+	if jsonType == jsonparser.String {
+		// string nodes reference the value without the surrounding quotes
+		trimmed := bytes.TrimSpace(input)
+		if len(trimmed) < 2 || trimmed[len(trimmed)-1] != '"' {
+			return -1, ErrParseJSONValue
+		}
+		start += bytes.IndexByte(input, '"') + 1
+		input = trimmed[1 : len(trimmed)-1]
+	}
 	return j.parseKnownValue(input, jsonType, start)
 }
 
@@ -372,6 +382,59 @@ func (j *JSON) ImportPrimitiveNode(from *JSON, ref int) (int, error) {
 	}), nil
 }
 
+// CopyNode appends a deep copy of the node at ref, so the copy is not affected by later changes of the original nodes.
+// The copy shares the bytes of keys and values with the original, so nothing is appended to the storage.
+func (j *JSON) CopyNode(ref int) int {
+	node := j.Nodes[ref]
+	switch node.Kind {
+	case NodeKindObject:
+		fields := j.getIntSlice()
+		for _, fieldRef := range node.ObjectFields {
+			field := j.Nodes[fieldRef]
+			field.ObjectFieldValue = j.CopyNode(field.ObjectFieldValue)
+			fields = append(fields, j.appendNode(field))
+		}
+		node.ObjectFields = fields
+	case NodeKindArray:
+		if node.ArrayValues != nil {
+			values := j.getIntSlice()
+			for _, valueRef := range node.ArrayValues {
+				values = append(values, j.CopyNode(valueRef))
+			}
+			node.ArrayValues = values
+		}
+	}
+	return j.appendNode(node)
+}
+
+// ImportNode appends a deep copy of the node at ref of from, including its keys and values,
+// e.g. to merge nodes appended to a fork back into the original JSON.
+func (j *JSON) ImportNode(from *JSON, ref int) int {
+	node := from.Nodes[ref]
+	switch node.Kind {
+	case NodeKindObject:
+		fields := j.getIntSlice()
+		for _, fieldRef := range node.ObjectFields {
+			fields = append(fields, j.ImportNode(from, fieldRef))
+		}
+		node.ObjectFields = fields
+	case NodeKindObjectField:
+		node.keyStart, node.keyEnd = j.appendBytes(from.storage[node.keyStart:node.keyEnd])
+		node.ObjectFieldValue = j.ImportNode(from, node.ObjectFieldValue)
+	case NodeKindArray:
+		if node.ArrayValues != nil {
+			values := j.getIntSlice()
+			for _, valueRef := range node.ArrayValues {
+				values = append(values, j.ImportNode(from, valueRef))
+			}
+			node.ArrayValues = values
+		}
+	default:
+		node.valueStart, node.valueEnd = j.appendBytes(from.storage[node.valueStart:node.valueEnd])
+	}
+	return j.appendNode(node)
+}
+
 func (j *JSON) AppendNull() int {
 	start := len(j.storage)
 	j.storage = append(j.storage, null...)
@@ -394,6 +457,42 @@ func (j *JSON) AppendInt(value int) int {
 	})
 }
 
+// Forks returns n copies of the JSON which can be read and appended to independently of j and of each other,
+// e.g. to walk the same document concurrently. j must not be modified while the forks are in use.
+// The forks share the nodes of j until they append a node, which copies the nodes,
+// so existing nodes must not be modified by a fork before it appended a node.
+// The object fields and array values of the nodes of j are clipped once for all forks,
+// so appending fields or values to an existing node of a fork copies them,
+// but elements must not be overwritten in place, e.g. by sorting, and the forks must not be Reset, because Reset reuses these slices.
+func (j *JSON) Forks(n int) []*JSON {
+	for i := range j.Nodes {
+		j.Nodes[i].ObjectFields = slices.Clip(j.Nodes[i].ObjectFields)
+		j.Nodes[i].ArrayValues = slices.Clip(j.Nodes[i].ArrayValues)
+	}
+	forks := make([]*JSON, n)
+	for i := range forks {
+		forks[i] = &JSON{
+			storage:     slices.Clip(j.storage),
+			Nodes:       slices.Clip(j.Nodes),
+			RootNode:    j.RootNode,
+			sharedNodes: true,
+		}
+	}
+	return forks
+}
+
+// Len returns the number of nodes and the size of the storage, e.g. to Truncate temporary nodes later
+func (j *JSON) Len() (nodes, storage int) {
+	return len(j.Nodes), len(j.storage)
+}
+
+// Truncate removes the nodes and the storage appended after Len returned nodes and storage.
+// Nodes appended before must not reference the removed nodes, e.g. as object field or array value.
+func (j *JSON) Truncate(nodes, storage int) {
+	j.Nodes = j.Nodes[:nodes]
+	j.storage = j.storage[:storage]
+}
+
 func (j *JSON) Reset() {
 	j.storage = j.storage[:0]
 	j._intSlices = j._intSlices[:0]
@@ -557,21 +656,19 @@ func (j *JSON) parseObject(object []byte, start int) (int, error) {
 		}
 		keyEnd := j.findKeyEnd(storageStart)
 		keyStart := keyEnd - len(key)
-		j.Nodes = append(j.Nodes, Node{
+		objectFieldRef := j.appendNode(Node{
 			Kind:             NodeKindObjectField,
 			ObjectFieldValue: valueNodeRef,
 			keyStart:         keyStart,
 			keyEnd:           keyEnd,
 		})
-		objectFieldRef := len(j.Nodes) - 1
 		node.ObjectFields = append(node.ObjectFields, objectFieldRef)
 		return nil
 	})
 	if err != nil {
 		return -1, errors.WithStack(ErrParseJSONObject)
 	}
-	j.Nodes = append(j.Nodes, node)
-	return len(j.Nodes) - 1, nil
+	return j.appendNode(node), nil
 }
 
 func (j *JSON) findKeyEnd(pos int) int {
@@ -612,8 +709,7 @@ func (j *JSON) parseArray(array []byte, start int) (ref int, parseArrayErr error
 	if err != nil {
 		return -1, errors.WithStack(ErrParseJSONArray)
 	}
-	j.Nodes = append(j.Nodes, node)
-	ref = len(j.Nodes) - 1
+	ref = j.appendNode(node)
 	return ref, parseArrayErr
 }
 
@@ -642,8 +738,7 @@ func (j *JSON) parseString(value []byte, start int) (int, error) {
 		valueStart: start,
 		valueEnd:   start + len(value),
 	}
-	j.Nodes = append(j.Nodes, node)
-	return len(j.Nodes) - 1, nil
+	return j.appendNode(node), nil
 }
 
 func (j *JSON) parseNumber(value []byte, offset int) (int, error) {
@@ -652,8 +747,7 @@ func (j *JSON) parseNumber(value []byte, offset int) (int, error) {
 		valueStart: offset,
 		valueEnd:   offset + len(value),
 	}
-	j.Nodes = append(j.Nodes, node)
-	return len(j.Nodes) - 1, nil
+	return j.appendNode(node), nil
 }
 
 func (j *JSON) parseBoolean(value []byte, offset int) (int, error) {
@@ -662,8 +756,7 @@ func (j *JSON) parseBoolean(value []byte, offset int) (int, error) {
 		valueStart: offset,
 		valueEnd:   offset + len(value),
 	}
-	j.Nodes = append(j.Nodes, node)
-	return len(j.Nodes) - 1, nil
+	return j.appendNode(node), nil
 }
 
 func (j *JSON) parseNull(value []byte, offset int) (int, error) {
@@ -672,8 +765,7 @@ func (j *JSON) parseNull(value []byte, offset int) (int, error) {
 		valueStart: offset,
 		valueEnd:   offset + len(value),
 	}
-	j.Nodes = append(j.Nodes, node)
-	return len(j.Nodes) - 1, nil
+	return j.appendNode(node), nil
 }
 
 func (j *JSON) PrintRoot(out io.Writer) error {
@@ -927,10 +1019,23 @@ func (j *JSON) buildObjectPath(path []string) (root, child int) {
 }
 
 func (j *JSON) appendNode(node Node) int {
+	if j.sharedNodes {
+		// copy the nodes shared with the JSON of Forks with room for as many new nodes,
+		// so the copy isn't copied again while building a response tree from the document
+		j.Nodes = slices.Grow(j.Nodes, len(j.Nodes))
+		j.sharedNodes = false
+	}
 	j.Nodes = append(j.Nodes, node)
 	return len(j.Nodes) - 1
 }
 
+func (j *JSON) appendBytes(b []byte) (start, end int) {
+	start = len(j.storage)
+	j.storage = append(j.storage, b...)
+	end = len(j.storage)
+	return start, end
+}
+
 func (j *JSON) appendString(str string) (start, end int) {
 	start = len(j.storage)
 	j.storage = append(j.storage, str...)
@@ -964,7 +1069,7 @@ func (j *JSON) AppendJSON(another *JSON) (nodeRef, storageOffset, nodeOffset int
 	j.storage = append(j.storage, another.storage...)
 	for _, node := range another.Nodes {
 		node.applyOffset(storageOffset, nodeOffset)
-		j.Nodes = append(j.Nodes, node)
+		j.appendNode(node)
 	}
 	return
 }
@@ -35,6 +35,22 @@ func TestJSON_ParseInvalidJSON(t *testing.T) {
 	assert.ErrorIs(t, err, ErrParseJSONValue)
 }
 
+func TestJSON_AppendAnyJSONBytesString(t *testing.T) {
+	js := &JSON{}
+	ref, err := js.AppendAnyJSONBytes([]byte(` "Jens" `))
+	assert.NoError(t, err)
+	assert.Equal(t, NodeKindString, js.Nodes[ref].Kind)
+	assert.Equal(t, "Jens", string(js.Nodes[ref].ValueBytes(js)))
+	out := &bytes.Buffer{}
+	err = js.PrintNode(js.Nodes[ref], out)
+	assert.NoError(t, err)
+	assert.Equal(t, `"Jens"`, out.String())
+
+	ref, err = js.AppendAnyJSONBytes([]byte(`"`))
+	assert.Equal(t, -1, ref)
+	assert.ErrorIs(t, err, ErrParseJSONValue)
+}
+
 func TestJSON_AddIntToObject(t *testing.T) {
 	js := &JSON{}
 	err := js.ParseObject([]byte(`{"name":"Jens"}`))
@@ -467,3 +483,122 @@ func BenchmarkJSON_MergeNodesWithPath(b *testing.B) {
 		}
 	}
 }
+
+func TestJSON_Forks(t *testing.T) {
+	js := &JSON{}
+	err := js.ParseObject([]byte(`{"a":1,"b":{"c":"d"}}`))
+	assert.NoError(t, err)
+
+	fork := js.Forks(1)[0]
+	// the nodes are copied when the fork appends a node
+	assert.Same(t, &js.Nodes[0], &fork.Nodes[0])
+	e, err := fork.AppendObject([]byte(`{"e":true}`))
+	assert.NotSame(t, &js.Nodes[0], &fork.Nodes[0])
+	assert.NoError(t, err)
+	merged, err := fork.AppendObject([]byte(`{}`))
+	assert.NoError(t, err)
+	fork.MergeNodes(merged, fork.Get(fork.RootNode, []string{"b"}))
+	fork.MergeNodes(merged, e)
+
+	out := &bytes.Buffer{}
+	err = fork.PrintNode(fork.Nodes[merged], out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"c":"d","e":true}`, out.String())
+
+	out.Reset()
+	err = js.PrintRoot(out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":{"c":"d"}}`, out.String())
+}
+
+func TestJSON_ForksAppendToExistingNode(t *testing.T) {
+	js := &JSON{}
+	err := js.ParseObject([]byte(`{"a":[1],"b":{"c":"d"}}`))
+	assert.NoError(t, err)
+	a := js.Get(js.RootNode, []string{"a"})
+	// leave room in the backing arrays, so appending without clipping would write to the arrays of js
+	js.Nodes[a].ArrayValues = append(make([]int, 0, 8), js.Nodes[a].ArrayValues...)
+
+	forks := js.Forks(2)
+	left, right := forks[0], forks[1]
+	left.AppendArrayValue(a, left.AppendInt(2))
+	// append another node first, so the refs of the appended values differ between the forks
+	right.AppendNull()
+	right.AppendArrayValue(a, right.AppendInt(3))
+	e, err := right.AppendObject([]byte(`{"e":true}`))
+	assert.NoError(t, err)
+	right.MergeNodes(right.Get(right.RootNode, []string{"b"}), e)
+
+	print := func(j *JSON) string {
+		out := &bytes.Buffer{}
+		assert.NoError(t, j.PrintRoot(out))
+		return out.String()
+	}
+	assert.Equal(t, `{"a":[1,2],"b":{"c":"d"}}`, print(left))
+	assert.Equal(t, `{"a":[1,3],"b":{"c":"d","e":true}}`, print(right))
+	assert.Equal(t, `{"a":[1],"b":{"c":"d"}}`, print(js))
+}
+
+func TestJSON_ImportNode(t *testing.T) {
+	js := &JSON{}
+	err := js.ParseObject([]byte(`{"a":1}`))
+	assert.NoError(t, err)
+	fork := js.Forks(1)[0]
+	ref, err := fork.AppendObject([]byte(`{"message":"failed","path":["a",0],"extensions":{"code":null,"retry":false}}`))
+	assert.NoError(t, err)
+
+	imported := js.ImportNode(fork, ref)
+	out := &bytes.Buffer{}
+	err = js.PrintNode(js.Nodes[imported], out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"message":"failed","path":["a",0],"extensions":{"code":null,"retry":false}}`, out.String())
+}
+
+func TestJSON_Truncate(t *testing.T) {
+	js := &JSON{}
+	err := js.ParseObject([]byte(`{"a":1}`))
+	assert.NoError(t, err)
+
+	nodes, storage := js.Len()
+	_, err = js.AppendObject([]byte(`{"b":{"c":"d"}}`))
+	assert.NoError(t, err)
+	js.Truncate(nodes, storage)
+	assert.Equal(t, nodes, len(js.Nodes))
+
+	b, err := js.AppendObject([]byte(`{"b":true}`))
+	assert.NoError(t, err)
+	out := &bytes.Buffer{}
+	err = js.PrintNode(js.Nodes[b], out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"b":true}`, out.String())
+
+	out.Reset()
+	err = js.PrintRoot(out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, out.String())
+}
+
+func TestJSON_CopyNode(t *testing.T) {
+	js := &JSON{}
+	err := js.ParseObject([]byte(`{"a":1,"b":{"c":"d","e":[true,null,{"f":1.5}]},"g":[]}`))
+	assert.NoError(t, err)
+
+	storageLen := len(js.storage)
+	copied := js.CopyNode(js.RootNode)
+	assert.Equal(t, storageLen, len(js.storage))
+
+	// changes of the original nodes don't affect the copy
+	js.Nodes[js.Get(js.RootNode, []string{"b", "c"})].Kind = NodeKindNull
+	e := js.Get(js.RootNode, []string{"b", "e"})
+	js.Nodes[e].ArrayValues = js.Nodes[e].ArrayValues[:1]
+
+	out := &bytes.Buffer{}
+	err = js.PrintNode(js.Nodes[copied], out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":{"c":"d","e":[true,null,{"f":1.5}]},"g":[]}`, out.String())
+
+	out.Reset()
+	err = js.PrintRoot(out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":{"c":null,"e":[true]},"g":[]}`, out.String())
+}
@@ -4284,6 +4284,7 @@ func TestGraphQLDataSource(t *testing.T) {
 						NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, ctx),
 					},
 					PostProcessing: DefaultPostProcessingConfiguration,
+					DataSourceID:   "ds-id",
 				},
 				Response: &resolve.GraphQLResponse{
 					Data: &resolve.Object{
@@ -4324,6 +4325,7 @@ func TestGraphQLDataSource(t *testing.T) {
 					client: NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, ctx),
 				},
 				PostProcessing: DefaultPostProcessingConfiguration,
+				DataSourceID:   "ds-id",
 			},
 			Response: &resolve.GraphQLResponse{
 				Data: &resolve.Object{
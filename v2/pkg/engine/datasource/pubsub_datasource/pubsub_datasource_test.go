@@ -404,6 +404,7 @@ func TestPubSub(t *testing.T) {
 					PostProcessing: resolve.PostProcessingConfiguration{
 						MergePath: []string{"helloSubscription"},
 					},
+					DataSourceID: "test",
 				},
 				Response: &resolve.GraphQLResponse{
 					Data: &resolve.Object{
@@ -463,6 +464,7 @@ func TestPubSub(t *testing.T) {
 					PostProcessing: resolve.PostProcessingConfiguration{
 						MergePath: []string{"subscriptionWithMultipleSubjects"},
 					},
+					DataSourceID: "test",
 				},
 				Response: &resolve.GraphQLResponse{
 					Data: &resolve.Object{
@@ -504,6 +506,7 @@ func TestPubSub(t *testing.T) {
 					PostProcessing: resolve.PostProcessingConfiguration{
 						MergePath: []string{"subscriptionWithStaticValues"},
 					},
+					DataSourceID: "test",
 				},
 				Response: &resolve.GraphQLResponse{
 					Data: &resolve.Object{
@@ -551,6 +554,7 @@ func TestPubSub(t *testing.T) {
 					PostProcessing: resolve.PostProcessingConfiguration{
 						MergePath: []string{"subscriptionWithArgTemplateAndStaticValue"},
 					},
+					DataSourceID: "test",
 				},
 				Response: &resolve.GraphQLResponse{
 					Data: &resolve.Object{
@@ -96,6 +96,9 @@ type FieldConfiguration struct {
 	UnescapeResponseJson bool
 	// HasAuthorizationRule needs to be set to true if the Authorizer should be called for this field
 	HasAuthorizationRule bool
+	// NullPlaceholder is printed instead of null for the field, see resolve.Field.NullPlaceholder
+	// Planning fails if it is not valid JSON or the field is not nullable
+	NullPlaceholder []byte
 
 	SubscriptionFilterCondition *SubscriptionFilterCondition
 }
@@ -750,6 +750,121 @@ func TestPlanner_Plan(t *testing.T) {
 			))
 		})
 	})
+
+	t.Run("deprecated fields", func(t *testing.T) {
+		schema := `
+			schema {
+				query: Query
+			}
+
+			type Query {
+				hero: Character
+			}
+
+			type Character {
+				name: String!
+				nickname: String @deprecated(reason: "use name")
+			}
+		`
+
+		dsConfig := dsb().Schema(schema).
+			RootNode("Query", "hero").
+			ChildNode("Character", "name", "nickname").
+			DS()
+
+		var report operationreport.Report
+		plan := testLogic(t, schema, `
+			{
+				hero {
+					name
+					nickname
+				}
+			}
+		`, "", Configuration{
+			DisableResolveFieldPositions: true,
+			IncludeInfo:                  true,
+			DataSources:                  []DataSource{dsConfig},
+		}, &report)
+		require.False(t, report.HasErrors(), report.Error())
+
+		hero := plan.(*SynchronousResponsePlan).Response.Data.Fields[0]
+		assert.False(t, hero.Info.IsDeprecated)
+		fields := hero.Value.(*resolve.Object).Fields
+		require.Len(t, fields, 2)
+		assert.Equal(t, "name", fields[0].Info.Name)
+		assert.False(t, fields[0].Info.IsDeprecated)
+		assert.Equal(t, "nickname", fields[1].Info.Name)
+		assert.True(t, fields[1].Info.IsDeprecated)
+	})
+
+	t.Run("null placeholders", func(t *testing.T) {
+		schema := `
+			schema {
+				query: Query
+			}
+
+			type Query {
+				hero: Character
+			}
+
+			type Character {
+				name: String!
+				nickname: String
+			}
+		`
+
+		dsConfig := dsb().Schema(schema).
+			RootNode("Query", "hero").
+			ChildNode("Character", "name", "nickname").
+			DS()
+
+		operation := `
+			{
+				hero {
+					name
+					nickname
+				}
+			}
+		`
+
+		planWithPlaceholder := func(t *testing.T, fieldName string, placeholder string, report *operationreport.Report) Plan {
+			return testLogic(t, schema, operation, "", Configuration{
+				DisableResolveFieldPositions: true,
+				DataSources:                  []DataSource{dsConfig},
+				Fields: FieldConfigurations{
+					{
+						TypeName:        "Character",
+						FieldName:       fieldName,
+						NullPlaceholder: []byte(placeholder),
+					},
+				},
+			}, report)
+		}
+
+		t.Run("nullable field", func(t *testing.T) {
+			var report operationreport.Report
+			plan := planWithPlaceholder(t, "nickname", `""`, &report)
+			require.False(t, report.HasErrors(), report.Error())
+			fields := plan.(*SynchronousResponsePlan).Response.Data.Fields[0].Value.(*resolve.Object).Fields
+			require.Len(t, fields, 2)
+			assert.Nil(t, fields[0].NullPlaceholder)
+			assert.Equal(t, []byte(`""`), fields[1].NullPlaceholder)
+		})
+		t.Run("non-nullable field", func(t *testing.T) {
+			var report operationreport.Report
+			plan := planWithPlaceholder(t, "name", `""`, &report)
+			assert.Nil(t, plan)
+			require.True(t, report.HasErrors())
+			assert.Contains(t, report.Error(), "null placeholder of field 'name' is set on a non-nullable field")
+		})
+		t.Run("invalid JSON", func(t *testing.T) {
+			var report operationreport.Report
+			plan := planWithPlaceholder(t, "nickname", `"`, &report)
+			assert.Nil(t, plan)
+			require.True(t, report.HasErrors())
+			assert.Contains(t, report.Error(), "null placeholder of field 'nickname' is not valid JSON")
+		})
+	})
 }
 
 var expectedMyHeroPlan = &SynchronousResponsePlan{
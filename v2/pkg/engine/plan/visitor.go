@@ -309,6 +309,7 @@ func (v *Visitor) mapFieldConfig(ref int) {
 		return
 	}
 	v.fieldConfigs[ref] = fieldConfig
+	v.currentField.NullPlaceholder = fieldConfig.NullPlaceholder
 }
 
 func (v *Visitor) resolveFieldInfo(ref, typeRef int, onTypeNames [][]byte) *resolve.FieldInfo {
@@ -353,9 +354,19 @@ func (v *Visitor) resolveFieldInfo(ref, typeRef int, onTypeNames [][]byte) *reso
 		},
 		ExactParentTypeName:  enclosingTypeName,
 		HasAuthorizationRule: fieldHasAuthorizationRule,
+		IsDeprecated:         v.fieldIsDeprecated(ref),
 	}
 }
 
+func (v *Visitor) fieldIsDeprecated(ref int) bool {
+	fieldDefinition, ok := v.Walker.FieldDefinition(ref)
+	if !ok {
+		return false
+	}
+	_, deprecated := v.Definition.FieldDefinitionDirectiveByName(fieldDefinition, []byte("deprecated"))
+	return deprecated
+}
+
 func (v *Visitor) fieldHasAuthorizationRule(typeName, fieldName string) bool {
 	fieldConfig := v.Config.Fields.ForTypeField(typeName, fieldName)
 	return fieldConfig != nil && fieldConfig.HasAuthorizationRule
@@ -816,6 +827,19 @@ func (v *Visitor) LeaveDocument(_, _ *ast.Document) {
 			v.configureObjectFetch(v.planners[i].ObjectFetchConfiguration())
 		}
 	}
+	if err := v.validateNullPlaceholders(); err != nil {
+		v.Walker.StopWithInternalErr(err)
+	}
+}
+
+func (v *Visitor) validateNullPlaceholders() error {
+	switch plan := v.plan.(type) {
+	case *SynchronousResponsePlan:
+		return resolve.ValidateNullPlaceholders(plan.Response.Data)
+	case *SubscriptionResponsePlan:
+		return resolve.ValidateNullPlaceholders(plan.Response.Response.Data)
+	}
+	return nil
 }
 
 var (
@@ -1041,6 +1065,7 @@ func (v *Visitor) configureSubscription(config *objectFetchConfiguration) {
 	config.trigger.Variables = subscription.Variables
 	config.trigger.Source = subscription.DataSource
 	config.trigger.PostProcessing = subscription.PostProcessing
+	config.trigger.DataSourceID = config.sourceID
 	v.resolveInputTemplates(config, &subscription.Input, &config.trigger.Variables)
 	config.trigger.Input = []byte(subscription.Input)
 }
@@ -0,0 +1,54 @@
+package resolve
+
+import (
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// asciiWriter escapes all non-ASCII characters written to out as \uXXXX, see Context.ASCIIOnlyStrings
+// Characters outside the Basic Multilingual Plane are escaped as surrogate pairs, invalid UTF-8 is replaced by �
+// JSON is ASCII outside of strings, so escaping the whole output only affects the content of strings.
+// Each write must contain complete UTF-8 sequences, which is the case for all writes of the Resolvable.
+type asciiWriter struct {
+	out io.Writer
+	buf []byte
+}
+
+func (w *asciiWriter) Write(p []byte) (n int, err error) {
+	start, escaped := 0, false
+	for i := 0; i < len(p); {
+		if p[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if !escaped {
+			w.buf = w.buf[:0]
+			escaped = true
+		}
+		w.buf = append(w.buf, p[start:i]...)
+		r, size := utf8.DecodeRune(p[i:])
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			w.buf = appendUnicodeEscape(w.buf, r1)
+			w.buf = appendUnicodeEscape(w.buf, r2)
+		} else {
+			w.buf = appendUnicodeEscape(w.buf, r)
+		}
+		i += size
+		start = i
+	}
+	if !escaped {
+		return w.out.Write(p)
+	}
+	w.buf = append(w.buf, p[start:]...)
+	if _, err = w.out.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func appendUnicodeEscape(buf []byte, r rune) []byte {
+	return append(buf, '\\', 'u', hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+}
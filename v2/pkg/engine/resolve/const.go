@@ -3,26 +3,38 @@ package resolve
 import "errors"
 
 var (
-	lBrace                    = []byte("{")
-	rBrace                    = []byte("}")
-	lBrack                    = []byte("[")
-	rBrack                    = []byte("]")
-	comma                     = []byte(",")
-	colon                     = []byte(":")
-	quote                     = []byte("\"")
-	null                      = []byte("null")
-	literalData               = []byte("data")
-	literalTrue               = []byte("true")
-	literalFalse              = []byte("false")
-	literalErrors             = []byte("errors")
-	literalMessage            = []byte("message")
-	literalLocations          = []byte("locations")
-	literalPath               = []byte("path")
-	literalUnderscoreEntities = []byte("_entities")
-	literalExtensions         = []byte("extensions")
-	literalTrace              = []byte("trace")
-	literalRateLimit          = []byte("rateLimit")
-	literalAuthorization      = []byte("authorization")
+	lBrace                     = []byte("{")
+	rBrace                     = []byte("}")
+	lBrack                     = []byte("[")
+	rBrack                     = []byte("]")
+	comma                      = []byte(",")
+	colon                      = []byte(":")
+	quote                      = []byte("\"")
+	newLine                    = []byte("\n")
+	null                       = []byte("null")
+	literalData                = []byte("data")
+	literalTrue                = []byte("true")
+	literalFalse               = []byte("false")
+	literalErrors              = []byte("errors")
+	literalError               = []byte("error")
+	literalMessage             = []byte("message")
+	literalLocations           = []byte("locations")
+	literalPath                = []byte("path")
+	literalUnderscoreEntities  = []byte("_entities")
+	literalExtensions          = []byte("extensions")
+	literalTrace               = []byte("trace")
+	literalRateLimit           = []byte("rateLimit")
+	literalAuthorization       = []byte("authorization")
+	literalWarnings            = []byte("warnings")
+	literalSoftErrors          = []byte("softErrors")
+	literalDataPresent         = []byte("dataPresent")
+	literalHasNext             = []byte("hasNext")
+	literalFieldSources        = []byte("fieldSources")
+	literalFieldStates         = []byte("fieldStates")
+	literalCacheStatus         = []byte("cacheStatus")
+	literalDeniedFields        = []byte("deniedFields")
+	literalRetryableFields     = []byte("retryableFields")
+	literalIntrospectionPrefix = []byte("__")
 
 	emptyArray  = []byte("[]")
 	emptyObject = []byte("{}")
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"go.uber.org/atomic"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
 )
 
 type Context struct {
@@ -23,6 +25,178 @@ type Context struct {
 	Stats            Stats
 	LoaderHooks      LoaderHooks
 
+	// ResponseExtensions are rendered into the extensions object of the response
+	ResponseExtensions []ResponseExtension
+	// DebugExtensions marks the request as internal/debug request
+	// ResponseExtensions with DebugOnly set are only rendered if DebugExtensions is true
+	DebugExtensions bool
+	// NullDataOnErrors renders "data":null if the upstream responses contained errors but no data at all
+	// By default, the data is walked, so nullable root fields are rendered as null
+	NullDataOnErrors bool
+	// SortErrorsByPath sorts the errors of the response by their path and message before printing
+	// This makes the order of errors stable, e.g. for snapshot tests
+	SortErrorsByPath bool
+	// NDJSONOutput renders each item of a top-level list as a standalone JSON line instead of the standard response envelope
+	// It applies if the root object has a single field with a list value, otherwise the standard envelope is rendered
+	// Errors and extensions are rendered as a trailing line, e.g. {"errors":[...]}
+	NDJSONOutput bool
+	// SoftErrorExtension renders errors for values not matching the type of a nullable field under extensions.softErrors
+	// All other errors are rendered as top-level errors
+	SoftErrorExtension bool
+	// SuppressSoftErrorsTopLevel renders the errors under extensions.softErrors instead of the top-level errors,
+	// if all of them are soft errors, i.e. errors for values not matching the type of a nullable field.
+	// Otherwise, soft errors are rendered together with the other errors. SoftErrorExtension takes precedence
+	SuppressSoftErrorsTopLevel bool
+	// SynthesizeEmptyResponseError adds the error "No data resolved." if the data is empty and there are no errors,
+	// so clients can tell a degenerate response from an empty result
+	SynthesizeEmptyResponseError bool
+	// PrefixSubgraphErrors prepends "[dataSourceID] " to the messages of errors merged from subgraph responses,
+	// e.g. to tell apart the errors of multiple subgraphs. It applies to fetches and subscription events, see Resolvable.InitSubscriptionFromDataSource
+	PrefixSubgraphErrors bool
+	// IncludeRetryableFields renders the paths of errors marked as transient by the subgraph, i.e. with "extensions":{"transient":true},
+	// as extensions.retryableFields, so clients know which fields are safe to retry
+	IncludeRetryableFields bool
+	// NodeEncoder serializes the nodes of the response, e.g. to benchmark alternative JSON encoders
+	// If nil, DefaultNodeEncoder is used
+	NodeEncoder NodeEncoder
+	// NonFiniteFloatMode configures how NaN and Infinity values of Float fields are handled
+	NonFiniteFloatMode NonFiniteFloatMode
+	// NullMode configures how null values of nullable fields are rendered, e.g. for legacy transports without null
+	NullMode NullMode
+	// TypeTransformers post-process the data of all objects of a type, keyed by the __typename of the object
+	// The transformer receives the serialized object and returns an object which is merged into a copy of the original data,
+	// so fields not returned by the transformer are kept
+	// Transformers are applied before the fields of the object are resolved, once per object and resolve,
+	// even if the same data is selected by multiple fields, e.g. using aliases. The data itself is not modified
+	TypeTransformers map[string]func(objectData []byte) ([]byte, error)
+	// DetectDuplicateKeys is a debug option which adds an error if an object selects multiple fields with the same response key
+	// Such fields are merged into a single key in the response, so this usually indicates a bug in the planner
+	DetectDuplicateKeys bool
+	// TrustedDataSourceIDs are data sources whose fields are not authorized, e.g. internal data sources
+	// Fields resolved by a trusted data source are allowed without calling the Authorizer
+	TrustedDataSourceIDs map[string]struct{}
+	// DefaultDeny denies all fields without FieldInfo.HasAuthorizationRule instead of allowing them, e.g. for zero-trust deployments
+	// Fields with an authorization rule are authorized by the Authorizer as usual, fields of trusted data sources are allowed
+	DefaultDeny bool
+	// DefaultDenyExemptMetaFields allows meta fields like __typename with DefaultDeny
+	DefaultDenyExemptMetaFields bool
+	// OnResolveObject is called once per object of the response with its concrete type name and path, e.g. "user.friends.0"
+	// The type name is taken from the __typename of the data, or the parent type of the selected fields
+	OnResolveObject func(typeName string, path string)
+	// IncludeOperationNameInErrorPath prefixes field paths in error messages with Request.OperationName instead of the operation type,
+	// e.g. 'MyQuery.user.name' instead of 'Query.user.name'. Anonymous operations keep the operation type prefix
+	IncludeOperationNameInErrorPath bool
+	// ErrorPathSeparator separates the segments of field paths in error messages, e.g. 'Query/user/name', it defaults to "."
+	// The path of the errors is not affected
+	ErrorPathSeparator string
+	// RootTypeName is the value of __typename selected on the root object when it is absent from the data, e.g. "Query" or "Subscription"
+	// The value is subject to the type name renames of the Resolvable
+	RootTypeName string
+	// SimplifySingleError prints responses with exactly one error and no data as {"error":"message"} instead of the errors array
+	// This is not compliant with the GraphQL specification and should only be enabled for clients expecting this shape
+	SimplifySingleError bool
+	// ParallelRootFields prints the root fields of the response concurrently, bounded by GOMAXPROCS
+	// Each root field is walked with its own fork of the data, so this only pays off for large responses with many root fields
+	// Responses which are printed directly from the data without building the response tree are not affected
+	// CustomNode.Resolve, FloatFormatter, TypeNameRewriter, NodeEncoder and ErrorSink are called concurrently while printing
+	ParallelRootFields bool
+	// FailFast aborts the walk at the first error, so the response contains only this error and no data
+	// Errors appended before Resolve, e.g. upstream errors, are still rendered
+	FailFast bool
+	// IncludeErrorCategory adds extensions.category to the errors generated while resolving, e.g. ErrorCategoryValidation
+	// Errors returned by subgraphs are rendered unchanged
+	IncludeErrorCategory bool
+	// OnDeprecatedFieldUsed is called for each resolved value of a field with FieldInfo.IsDeprecated
+	// Fields which are skipped or absent in the data are not reported
+	OnDeprecatedFieldUsed func(coordinate GraphCoordinate)
+	// ASCIIOnlyStrings escapes all non-ASCII characters of the response as \uXXXX, e.g. for transports which aren't UTF-8 safe
+	ASCIIOnlyStrings bool
+	// FeatureFlags enables or disables fields with a Field.FeatureFlag
+	// Fields with a disabled feature flag are omitted from the response like fields skipped with @skip
+	FeatureFlags map[string]bool
+	// IncludeUnknownFeatureFlags includes fields with a feature flag which is missing in FeatureFlags, by default they are excluded
+	IncludeUnknownFeatureFlags bool
+	// IncludeDataPresentExtension renders extensions.dataPresent, which is true if any selected root field has a non-null value
+	IncludeDataPresentExtension bool
+	// OneBasedArrayIndices renders list positions in the paths of errors generated while resolving starting at 1 instead of 0
+	// Errors returned by subgraphs and the data are not affected
+	OneBasedArrayIndices bool
+	// LargeIntAsString renders Int and BigInt values with an absolute value above LargeIntThreshold as strings,
+	// e.g. for JavaScript clients which lose precision on integers beyond 2^53
+	LargeIntAsString bool
+	// LargeIntThreshold is the largest integer rendered as number with LargeIntAsString, it defaults to 2^53-1
+	LargeIntThreshold int64
+	// BooleanAsInt renders Boolean values as 1 and 0 instead of true and false, e.g. for downstream systems without a boolean type
+	// Values which aren't booleans are still rejected
+	BooleanAsInt bool
+	// FloatFormatter reformats the number of each Float value when printing the response, e.g. to a fixed number of decimals
+	// The result must be a valid JSON number, otherwise resolving fails
+	FloatFormatter func(raw []byte) []byte
+	// StrictExtraFields adds a warning for each field of a subgraph response which isn't part of the selection set,
+	// e.g. to detect schema drift. Meta fields like __typename are ignored.
+	StrictExtraFields bool
+	// LazyCustomNodes invokes the resolvers of custom nodes only while printing instead of during each walk,
+	// so each resolver runs at most once. As the errors are printed before the data,
+	// an error of a resolver can't be added to the response and is returned by Resolve instead.
+	// In this case, the response is already partially written to the output, e.g. {"data":, and must be discarded.
+	LazyCustomNodes bool
+	// MaxArrayItems limits the number of items of each list in the response, 0 means unlimited.
+	// Items beyond the limit are neither resolved nor printed and a warning with the number of omitted items is added.
+	MaxArrayItems int
+	// MaxObjectFields limits the number of fields of each object in the response, 0 means unlimited.
+	// Fields beyond the limit are neither resolved nor printed and a warning is added for each truncated object.
+	MaxObjectFields int
+	// StrictTypeName adds an error for objects with a __typename which isn't a string, e.g. a number or null.
+	// By default, such objects are resolved as if the __typename was absent, so fields on type conditions are skipped silently.
+	StrictTypeName bool
+	// BeforeDataWalk is called by Resolve right before the data of the response is walked
+	BeforeDataWalk func()
+	// AfterDataWalk is called by Resolve right after the data of the response is walked and printed, also if walking failed.
+	// hadError is true if the data couldn't be resolved, e.g. due to a non-nullable field being null.
+	AfterDataWalk func(hadError bool)
+	// OnNullBubble is called when a nullable object or list is set to null, because a non-nullable child couldn't be resolved.
+	// path is the path of the object or list, e.g. user.friends.0, reason is the message of the error of the child.
+	OnNullBubble func(path string, reason string)
+	// IncrementalEnvelope prints "hasNext":false after the data of complete responses,
+	// so clients can handle responses with and without incremental delivery (@defer, @stream) the same way
+	IncrementalEnvelope bool
+	// FieldPresenceCollector records per coordinate whether the selected fields were present, null or absent in the data
+	FieldPresenceCollector *FieldPresenceCollector
+	// ErrorSink is called for each error generated while resolving as soon as it occurs, e.g. for structured logging
+	// This includes errors for null or mistyped values and authorization errors, but neither warnings nor subgraph errors
+	// Fields denied with ConsolidateAuthDenials are reported as well, even if they are only listed in extensions.deniedFields
+	ErrorSink func(err GraphQLError)
+	// NonNullFallback is called if the value of a non-nullable field is null or absent in the data, with the response path of the field, e.g. user.name
+	// If it returns true, the returned JSON is resolved as the value of the field instead of adding an error and nulling the parent,
+	// e.g. an empty object matching the shape of the field. Invalid JSON is ignored, so the error is added as usual
+	NonNullFallback func(path string, node Node) ([]byte, bool)
+	// TraceFieldSources renders extensions.fieldSources, which maps the response path of each field, e.g. users.0.name,
+	// to the FieldInfo.Source IDs of the datasources providing it. Fields without a FieldInfo are omitted
+	TraceFieldSources bool
+	// IncludeFieldStatesExtension renders extensions.fieldStates, which maps the response path of each field to the state of its value
+	// in the data: "present", "null" or "undefined" if the field is absent. Unlike FieldPresenceCollector, all fields are included
+	IncludeFieldStatesExtension bool
+	// IncludeCacheStatus renders extensions.cacheStatus, which maps the response path of each field to "hit" if FieldInfo.CacheHit is set,
+	// or "miss" otherwise. Fields without a FieldInfo are omitted
+	IncludeCacheStatus bool
+	// UniqueErrorPaths keeps only the first error generated while resolving for each path,
+	// e.g. if aliases of the same field fail for the same reason. Subgraph errors are not deduplicated
+	UniqueErrorPaths bool
+	// MaskInternalErrors replaces the message of validation and internal errors generated while resolving with a generic message,
+	// so that no internals are leaked to clients. Each masked error gets a generated extensions.referenceId,
+	// which is also passed to the ErrorSink together with the full message. Authorization errors are not masked
+	MaskInternalErrors bool
+	// TypeNameRewriter is called for each printed __typename value, e.g. to rename types per tenant
+	// The coordinate is the __typename field with the parent type of its FieldInfo, which may be an interface
+	// If it returns nil, RenameTypeNames is applied
+	TypeNameRewriter func(original []byte, coordinate GraphCoordinate) []byte
+	// ConsolidateAuthDenials lists the paths of fields denied by the Authorizer once in extensions.deniedFields,
+	// e.g. user.email, instead of adding an error for each field. The denied fields are set to null.
+	// Denied non-nullable fields still get an error, because the null value propagates to their parent
+	ConsolidateAuthDenials bool
+	// IntrospectionData is merged into the data of the response when the Resolvable is initialized
+	IntrospectionData *IntrospectionData
+
 	authorizer  Authorizer
 	rateLimiter RateLimiter
 
@@ -52,6 +226,25 @@ type Authorizer interface {
 	RenderResponseExtension(ctx *Context, out io.Writer) error
 }
 
+// AuthorizationTransformer can be implemented by an Authorizer to transform the values of allowed fields
+// This allows to partially mask a field (e.g. only show the last 4 digits) instead of denying it completely
+type AuthorizationTransformer interface {
+	// TransformObjectField is called after AuthorizeObjectField allowed a field
+	// The value argument is the JSON value of the field
+	// If replacement is nil, the value stays untouched, otherwise it must be a valid JSON value
+	TransformObjectField(ctx *Context, dataSourceID string, value json.RawMessage, coordinate GraphCoordinate) (replacement json.RawMessage, err error)
+}
+
+// AuthorizationRedactor can be implemented by an Authorizer to redact nested values of allowed fields
+// This allows to allow an object, but to hide some of its nested values without separate authorization rules
+type AuthorizationRedactor interface {
+	// RedactObjectField is called after AuthorizeObjectField allowed a field
+	// The value argument is the JSON value of the field
+	// Each redact path is relative to the value, e.g. []string{"address", "street"}, list items are addressed by their index in brackets, e.g. "[0]"
+	// Redacted values are handled like null values, so redacting a non-nullable value adds an error
+	RedactObjectField(ctx *Context, dataSourceID string, value json.RawMessage, coordinate GraphCoordinate) (redactPaths [][]string, err error)
+}
+
 func (c *Context) SetAuthorizer(authorizer Authorizer) {
 	c.authorizer = authorizer
 }
@@ -82,6 +275,69 @@ type RateLimiter interface {
 	RenderResponseExtension(ctx *Context, out io.Writer) error
 }
 
+// NodeEncoder writes a node of the storage as JSON to out
+// It's called with the value of each root field of the resolved data, and with the errors and warnings.
+// Within the value of a root field containing streamed arrays, it's called with each leaf value instead
+type NodeEncoder interface {
+	EncodeNode(storage *astjson.JSON, ref int, out io.Writer) error
+}
+
+// DefaultNodeEncoder encodes nodes using astjson.JSON.PrintNode
+type DefaultNodeEncoder struct{}
+
+func (DefaultNodeEncoder) EncodeNode(storage *astjson.JSON, ref int, out io.Writer) error {
+	return storage.PrintNode(storage.Nodes[ref], out)
+}
+
+// NonFiniteFloatMode configures the handling of non-finite Float values, e.g. "NaN", "Infinity" or numbers overflowing float64
+type NonFiniteFloatMode int
+
+const (
+	// NonFiniteFloatModeDefault keeps the default behavior:
+	// string values are rejected as non-float values, overflowing numbers are rendered as is
+	NonFiniteFloatModeDefault NonFiniteFloatMode = iota
+	// NonFiniteFloatModeError adds an error for non-finite values
+	NonFiniteFloatModeError
+	// NonFiniteFloatModeNull renders non-finite values as null, non-nullable fields are handled like null values
+	NonFiniteFloatModeNull
+)
+
+// NullMode configures how null values of nullable fields are rendered
+type NullMode int
+
+const (
+	// NullModeJSONNull renders null values as null
+	NullModeJSONNull NullMode = iota
+	// NullModeOmitKey omits fields with null values from the response
+	NullModeOmitKey
+	// NullModeEmptyString renders null values as empty string
+	NullModeEmptyString
+)
+
+// IntrospectionData is a pre-built introspection result, e.g. {"__schema":{...}} or {"__type":{...}}
+type IntrospectionData struct {
+	// Data is the introspection result as JSON object
+	Data []byte
+	// MergePath is the path in the response data where Data is merged into
+	// If empty, Data is merged into the root of the response data
+	MergePath []string
+}
+
+// ResponseExtension is a custom entry in the extensions object of the response
+type ResponseExtension struct {
+	// Key is the key of the entry in the extensions object
+	Key string
+	// DebugOnly renders the extension only if Context.DebugExtensions is true
+	// This allows to add verbose internals to the response without exposing them to production clients
+	DebugOnly bool
+	// Render writes the JSON value of the extension to out
+	Render func(ctx *Context, out io.Writer) error
+}
+
+func (e *ResponseExtension) enabled(ctx *Context) bool {
+	return !e.DebugOnly || ctx.DebugExtensions
+}
+
 func (c *Context) SetRateLimiter(limiter RateLimiter) {
 	c.rateLimiter = limiter
 }
@@ -113,6 +369,8 @@ func (s *Stats) Reset() {
 type Request struct {
 	ID     string
 	Header http.Header
+	// OperationName is the name of the executed operation, empty for anonymous operations
+	OperationName string
 }
 
 func NewContext(ctx context.Context) *Context {
@@ -146,13 +404,89 @@ func (c *Context) clone(ctx context.Context) *Context {
 	return &cpy
 }
 
+// changesPrintedData returns true if an option prints the data differently than it is stored,
+// so the response must be printed from the resolved tree instead of directly from the data, see passThroughEligible.
+// Options which change the printed data must be added here,
+// TestResolvable_PassThroughOptions compares the output with and without the fast path for each option.
+func (c *Context) changesPrintedData() bool {
+	return c.NodeEncoder != nil || // encodes the values of the resolved tree
+		c.LargeIntAsString ||
+		c.BooleanAsInt ||
+		c.FloatFormatter != nil ||
+		c.NullMode != NullModeJSONNull ||
+		c.MaxObjectFields > 0
+}
+
+// observesPrintWalk returns true if an option is called for each resolved object in the order of the print walk,
+// so the print walk can neither be skipped, see passThroughEligible, nor run concurrently, see parallelRootFieldsEligible
+func (c *Context) observesPrintWalk() bool {
+	return c.OnResolveObject != nil
+}
+
 func (c *Context) Free() {
 	c.ctx = nil
 	c.Variables = nil
 	c.Request.Header = nil
+	c.Request.OperationName = ""
 	c.RenameTypeNames = nil
 	c.TracingOptions.DisableAll()
 	c.Extensions = nil
+	c.ResponseExtensions = nil
+	c.DebugExtensions = false
+	c.NullDataOnErrors = false
+	c.SortErrorsByPath = false
+	c.NDJSONOutput = false
+	c.SoftErrorExtension = false
+	c.SuppressSoftErrorsTopLevel = false
+	c.SynthesizeEmptyResponseError = false
+	c.PrefixSubgraphErrors = false
+	c.IncludeRetryableFields = false
+	c.NodeEncoder = nil
+	c.NonFiniteFloatMode = NonFiniteFloatModeDefault
+	c.NullMode = NullModeJSONNull
+	c.TypeTransformers = nil
+	c.DetectDuplicateKeys = false
+	c.TrustedDataSourceIDs = nil
+	c.DefaultDeny = false
+	c.DefaultDenyExemptMetaFields = false
+	c.OnResolveObject = nil
+	c.IncludeOperationNameInErrorPath = false
+	c.ErrorPathSeparator = ""
+	c.RootTypeName = ""
+	c.SimplifySingleError = false
+	c.ParallelRootFields = false
+	c.FailFast = false
+	c.IncludeErrorCategory = false
+	c.OnDeprecatedFieldUsed = nil
+	c.ASCIIOnlyStrings = false
+	c.FeatureFlags = nil
+	c.IncludeUnknownFeatureFlags = false
+	c.IncludeDataPresentExtension = false
+	c.OneBasedArrayIndices = false
+	c.LargeIntAsString = false
+	c.LargeIntThreshold = 0
+	c.BooleanAsInt = false
+	c.FloatFormatter = nil
+	c.StrictExtraFields = false
+	c.LazyCustomNodes = false
+	c.MaxArrayItems = 0
+	c.MaxObjectFields = 0
+	c.StrictTypeName = false
+	c.BeforeDataWalk = nil
+	c.AfterDataWalk = nil
+	c.OnNullBubble = nil
+	c.IncrementalEnvelope = false
+	c.FieldPresenceCollector = nil
+	c.ErrorSink = nil
+	c.NonNullFallback = nil
+	c.TraceFieldSources = false
+	c.IncludeFieldStatesExtension = false
+	c.IncludeCacheStatus = false
+	c.UniqueErrorPaths = false
+	c.MaskInternalErrors = false
+	c.TypeNameRewriter = nil
+	c.ConsolidateAuthDenials = false
+	c.IntrospectionData = nil
 	c.Stats.Reset()
 	c.subgraphErrors = nil
 	c.authorizer = nil
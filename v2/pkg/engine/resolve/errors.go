@@ -14,6 +14,21 @@ type GraphQLError struct {
 	Extensions map[string]any `json:"extensions,omitempty"`
 }
 
+// Error categories rendered as extensions.category of errors generated while resolving, see Context.IncludeErrorCategory
+const (
+	// ErrorCategoryValidation is used for values which don't match the schema, e.g. a string for an Int field, and for input errors
+	ErrorCategoryValidation = "validation"
+	// ErrorCategoryAuthorization is used for fields rejected by the Authorizer
+	ErrorCategoryAuthorization = "authorization"
+	// ErrorCategoryResolver is used for errors returned by callbacks resolving values, e.g. ComputePageInfo of a ConnectionInfo
+	ErrorCategoryResolver = "resolver"
+	// ErrorCategoryInternal is used for all other errors, e.g. null values of non-nullable fields
+	ErrorCategoryInternal = "internal"
+)
+
+// maskedErrorMessage replaces the message of errors masked with Context.MaskInternalErrors
+const maskedErrorMessage = "Internal server error."
+
 type Location struct {
 	Line   uint32 `json:"line"`
 	Column uint32 `json:"column"`
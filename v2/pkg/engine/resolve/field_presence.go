@@ -0,0 +1,40 @@
+package resolve
+
+// FieldPresence counts how often the value of a selected field was present, null or absent in the data
+type FieldPresence struct {
+	Present int
+	Null    int
+	Absent  int
+}
+
+// FieldPresenceCollector records the FieldPresence of the selected fields per coordinate while resolving, e.g. for API analytics.
+// Only fields with a FieldInfo are recorded. The values are classified as returned by the subgraphs,
+// before values are set to null due to errors of their children.
+// A collector isn't safe for concurrent use, so each request needs its own collector.
+type FieldPresenceCollector struct {
+	fields map[GraphCoordinate]*FieldPresence
+}
+
+func NewFieldPresenceCollector() *FieldPresenceCollector {
+	return &FieldPresenceCollector{
+		fields: map[GraphCoordinate]*FieldPresence{},
+	}
+}
+
+// Fields returns the collected presence counts per coordinate
+func (c *FieldPresenceCollector) Fields() map[GraphCoordinate]FieldPresence {
+	fields := make(map[GraphCoordinate]FieldPresence, len(c.fields))
+	for coordinate, presence := range c.fields {
+		fields[coordinate] = *presence
+	}
+	return fields
+}
+
+func (c *FieldPresenceCollector) presence(coordinate GraphCoordinate) *FieldPresence {
+	presence, ok := c.fields[coordinate]
+	if !ok {
+		presence = &FieldPresence{}
+		c.fields[coordinate] = presence
+	}
+	return presence
+}
@@ -639,6 +639,7 @@ func (l *Loader) mergeErrors(res *result, ref int) error {
 	l.optionallyOmitErrorExtensions(ref)
 	l.optionallyOmitErrorLocations(ref)
 	l.optionallyRewriteErrorPaths(ref)
+	l.optionallyPrefixErrorMessages(ref, res.subgraphName)
 
 	if l.subgraphErrorPropagationMode == SubgraphErrorPropagationModePassThrough {
 		l.data.MergeArrays(l.errorsRoot, ref)
@@ -709,6 +710,36 @@ WithNextError:
 	}
 }
 
+// optionallyPrefixErrorMessages prepends "[dataSourceID] " to the messages of the subgraph errors, see Context.PrefixSubgraphErrors
+func (l *Loader) optionallyPrefixErrorMessages(ref int, dataSourceID string) {
+	if !l.ctx.PrefixSubgraphErrors {
+		return
+	}
+	prefixErrorMessages(l.data, ref, dataSourceID)
+}
+
+// prefixErrorMessages prepends "[dataSourceID] " to the messages of the errors in the array at ref
+func prefixErrorMessages(data *astjson.JSON, ref int, dataSourceID string) {
+	if dataSourceID == "" {
+		return
+	}
+	for _, i := range data.Nodes[ref].ArrayValues {
+		if data.Nodes[i].Kind != astjson.NodeKindObject {
+			continue
+		}
+		message := data.GetObjectFieldBytes(i, literalMessage)
+		if !data.NodeIsDefined(message) || data.Nodes[message].Kind != astjson.NodeKindString {
+			continue
+		}
+		prefixed := make([]byte, 0, len(dataSourceID)+3+len(data.Nodes[message].ValueBytes(data)))
+		prefixed = append(prefixed, '[')
+		prefixed = append(prefixed, dataSourceID...)
+		prefixed = append(prefixed, "] "...)
+		prefixed = append(prefixed, data.Nodes[message].ValueBytes(data)...)
+		data.SetObjectField(i, data.AppendStringBytes(prefixed), "message")
+	}
+}
+
 func (l *Loader) optionallyRewriteErrorPaths(ref int) {
 	if !l.rewriteSubgraphErrorPaths {
 		return
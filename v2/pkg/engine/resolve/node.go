@@ -14,6 +14,10 @@ const (
 	NodeKindCustom
 	NodeKindScalar
 	NodeKindStaticString
+	NodeKindConnectionInfo
+	NodeKindFileRef
+	NodeKindEnum
+	NodeKindStreamedArray
 )
 
 type Node interface {
@@ -0,0 +1,57 @@
+package resolve
+
+import (
+	"slices"
+)
+
+// PageInfo is the pagination metadata of a Relay connection
+type PageInfo struct {
+	HasNextPage bool `json:"hasNextPage"`
+	// EndCursor is the cursor of the last edge, nil renders null
+	EndCursor *string `json:"endCursor"`
+}
+
+// ComputePageInfo computes the PageInfo from the edges of a connection
+// Each edge is the JSON of one item of the edges array
+type ComputePageInfo func(ctx *Context, edges [][]byte) (PageInfo, error)
+
+// ConnectionInfo renders the pageInfo object of a Relay connection
+// It's used as the value of the pageInfo field of the connection object
+// and computes the pageInfo from the sibling edges array using ComputePageInfo
+type ConnectionInfo struct {
+	// EdgesPath is the path of the edges array relative to the connection object, e.g. []string{"edges"}
+	EdgesPath []string
+	Nullable  bool
+	// ComputePageInfo is called with the edges of the connection, missing edges are passed as an empty list
+	// With Context.MaxArrayItems, only the edges within the limit are passed, so the pageInfo matches the printed edges
+	ComputePageInfo ComputePageInfo `json:"-"`
+}
+
+func (_ *ConnectionInfo) NodeKind() NodeKind {
+	return NodeKindConnectionInfo
+}
+
+func (_ *ConnectionInfo) NodePath() []string {
+	return nil
+}
+
+func (c *ConnectionInfo) NodeNullable() bool {
+	return c.Nullable
+}
+
+func (c *ConnectionInfo) Equals(n Node) bool {
+	other, ok := n.(*ConnectionInfo)
+	if !ok {
+		return false
+	}
+
+	if c.Nullable != other.Nullable {
+		return false
+	}
+
+	if !slices.Equal(c.EdgesPath, other.EdgesPath) {
+		return false
+	}
+
+	return true
+}
@@ -12,6 +12,9 @@ type CustomNode struct {
 	CustomResolve
 	Nullable bool
 	Path     []string
+	// ID enables memoizing the results of Resolve per request: CustomNodes with the same ID resolving the same value
+	// reuse the first result, so Resolve must return the same result for the same value. An empty ID disables memoization
+	ID string
 }
 
 func (_ *CustomNode) NodeKind() NodeKind {
@@ -40,5 +43,13 @@ func (c *CustomNode) Equals(n Node) bool {
 		return false
 	}
 
+	if c.Nullable != other.Nullable {
+		return false
+	}
+
+	if c.ID != other.ID {
+		return false
+	}
+
 	return true
 }
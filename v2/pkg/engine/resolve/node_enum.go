@@ -0,0 +1,68 @@
+package resolve
+
+import (
+	"slices"
+)
+
+// EnumUnknownValueStrategy defines how Enum values which aren't in AllowedValues are resolved
+type EnumUnknownValueStrategy int
+
+const (
+	// EnumUnknownValueError adds an error for unknown values like for values which don't match the type of the field
+	EnumUnknownValueError EnumUnknownValueStrategy = iota
+	// EnumUnknownValueNull resolves unknown values to null, for non-nullable enums an error is added like for null values
+	EnumUnknownValueNull
+	// EnumUnknownValueDefault resolves unknown values to Enum.DefaultValue, e.g. for values added to the enum of a subgraph
+	// which aren't known to the client yet
+	EnumUnknownValueDefault
+)
+
+type Enum struct {
+	Path     []string
+	Nullable bool
+	// TypeName is the name of the enum type, used in error messages
+	TypeName string
+	// AllowedValues are the values of the enum, if empty all string values are allowed
+	AllowedValues []string
+	// UnknownValueStrategy defines how values which aren't in AllowedValues are resolved
+	UnknownValueStrategy EnumUnknownValueStrategy
+	// DefaultValue is the value unknown values are resolved to with EnumUnknownValueDefault
+	DefaultValue string
+}
+
+func (_ *Enum) NodeKind() NodeKind {
+	return NodeKindEnum
+}
+
+func (e *Enum) NodePath() []string {
+	return e.Path
+}
+
+func (e *Enum) NodeNullable() bool {
+	return e.Nullable
+}
+
+func (e *Enum) Equals(n Node) bool {
+	other, ok := n.(*Enum)
+	if !ok {
+		return false
+	}
+
+	if e.Nullable != other.Nullable || e.TypeName != other.TypeName {
+		return false
+	}
+
+	if e.UnknownValueStrategy != other.UnknownValueStrategy || e.DefaultValue != other.DefaultValue {
+		return false
+	}
+
+	if !slices.Equal(e.AllowedValues, other.AllowedValues) {
+		return false
+	}
+
+	if !slices.Equal(e.Path, other.Path) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+package resolve
+
+import (
+	"slices"
+)
+
+// FileRef is a leaf which is sent as separate binary part of a multipart response instead of inline
+// The value in the data is the reference of the file for the transport, e.g. a file ID or URL.
+// It's printed as placeholder token, which is the index of the file in Resolvable.FileRefs.
+type FileRef struct {
+	Path     []string
+	Nullable bool
+}
+
+func (_ *FileRef) NodeKind() NodeKind {
+	return NodeKindFileRef
+}
+
+func (f *FileRef) NodePath() []string {
+	return f.Path
+}
+
+func (f *FileRef) NodeNullable() bool {
+	return f.Nullable
+}
+
+func (f *FileRef) Equals(n Node) bool {
+	other, ok := n.(*FileRef)
+	if !ok {
+		return false
+	}
+
+	if f.Nullable != other.Nullable {
+		return false
+	}
+
+	if !slices.Equal(f.Path, other.Path) {
+		return false
+	}
+
+	return true
+}
+
+// FileRefPart is a file referenced by a FileRef node of the response, which the transport attaches as binary part
+type FileRefPart struct {
+	// Index is the placeholder token printed instead of the value
+	Index int
+	// Path is the path of the field in the response, with the same segments as the path of errors
+	Path []any
+	// Value is the reference of the file from the data
+	Value string
+}
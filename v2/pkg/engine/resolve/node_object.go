@@ -2,7 +2,11 @@ package resolve
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"slices"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/internal/unsafebytes"
 )
 
 type Object struct {
@@ -10,6 +14,13 @@ type Object struct {
 	Path     []string
 	Fields   []*Field
 	Fetch    Fetch
+	// FlattenInto lifts the fields of the object into the parent object when printing the response
+	// The name of the field holding the object is omitted
+	// On key collisions, the field printed last wins, e.g. a parent field selected after the flattened object
+	FlattenInto bool
+	// TypeDiscriminator computes the __typename of the object from its data, e.g. for interfaces whose subgraph doesn't provide it
+	// A non-empty result replaces the __typename of the data, so it's used for OnTypeNames matching and printed for __typename fields
+	TypeDiscriminator func(objectData []byte) string
 }
 
 func (_ *Object) NodeKind() NodeKind {
@@ -33,6 +44,10 @@ func (o *Object) Equals(n Node) bool {
 		return false
 	}
 
+	if o.FlattenInto != other.FlattenInto {
+		return false
+	}
+
 	if !slices.Equal(o.Path, other.Path) {
 		return false
 	}
@@ -68,6 +83,8 @@ func (_ *EmptyObject) Equals(n Node) bool {
 }
 
 type Field struct {
+	// Name is the key of the field in the response, which is the alias if the field is aliased
+	// The key of the field in the data is the path of the value, e.g. multiple aliases of the same field can share a path
 	Name                    []byte
 	Value                   Node
 	Position                Position
@@ -79,6 +96,65 @@ type Field struct {
 	IncludeDirectiveDefined bool
 	IncludeVariableName     string
 	Info                    *FieldInfo
+	// FeatureFlag includes the field only if the feature flag is enabled, see Context.FeatureFlags
+	FeatureFlag string
+	// RequirePresence adds an error if the field is absent in the data, even if the field is nullable
+	// An explicit null value is still allowed, e.g. to distinguish "unset" from "set to null" for patch semantics
+	RequirePresence bool
+	// CacheControl is the @cacheControl hint of the field, see Resolvable.CacheControl
+	CacheControl *CacheControl
+	// NullPlaceholder is printed instead of null if the value of the field is null, e.g. `""` for missing names
+	// It must be valid JSON and only be set on nullable fields, see ValidateNullPlaceholders
+	NullPlaceholder []byte
+	// RequiredVariables are the variables the arguments of the field depend on
+	// If any of them is absent, an error is added and the field is resolved to null instead of failing the whole operation
+	RequiredVariables []string
+}
+
+// schemaName returns the name of the field in the schema, which differs from Name if the field is aliased
+func (f *Field) schemaName() string {
+	if f.Info != nil && f.Info.Name != "" {
+		return f.Info.Name
+	}
+	return unsafebytes.BytesToString(f.Name)
+}
+
+// ValidateNullPlaceholders returns an error if a field of the response tree has a NullPlaceholder
+// which is not valid JSON or is set on a non-nullable field.
+// It is called by the planner when the response is planned.
+func ValidateNullPlaceholders(node Node) error {
+	switch n := node.(type) {
+	case *Object:
+		for i := range n.Fields {
+			if n.Fields[i].NullPlaceholder != nil {
+				if !n.Fields[i].Value.NodeNullable() {
+					return fmt.Errorf("null placeholder of field '%s' is set on a non-nullable field", n.Fields[i].Name)
+				}
+				if !json.Valid(n.Fields[i].NullPlaceholder) {
+					return fmt.Errorf("null placeholder of field '%s' is not valid JSON: %s", n.Fields[i].Name, n.Fields[i].NullPlaceholder)
+				}
+			}
+			if err := ValidateNullPlaceholders(n.Fields[i].Value); err != nil {
+				return err
+			}
+		}
+	case *Array:
+		return ValidateNullPlaceholders(n.Item)
+	}
+	return nil
+}
+
+const (
+	CacheControlScopePublic  = "PUBLIC"
+	CacheControlScopePrivate = "PRIVATE"
+)
+
+// CacheControl is a cache hint of a field
+type CacheControl struct {
+	// MaxAge is the maximum age of the field in seconds
+	MaxAge int
+	// Scope is either CacheControlScopePublic or CacheControlScopePrivate, empty means public
+	Scope string
 }
 
 func (f *Field) Equals(n *Field) bool {
@@ -91,6 +167,18 @@ func (f *Field) Equals(n *Field) bool {
 	if !f.Value.Equals(n.Value) {
 		return false
 	}
+	if !slices.Equal(f.RequiredVariables, n.RequiredVariables) {
+		return false
+	}
+	if f.FeatureFlag != n.FeatureFlag || f.RequirePresence != n.RequirePresence {
+		return false
+	}
+	if !bytes.Equal(f.NullPlaceholder, n.NullPlaceholder) {
+		return false
+	}
+	if (f.CacheControl == nil) != (n.CacheControl == nil) || (f.CacheControl != nil && *f.CacheControl != *n.CacheControl) {
+		return false
+	}
 	return true
 }
 
@@ -113,6 +201,16 @@ type FieldInfo struct {
 	FetchID   int
 	// HasAuthorizationRule needs to be set to true if the Authorizer should be called for this field
 	HasAuthorizationRule bool
+	// IsDeprecated is true if the field definition has the @deprecated directive, see Context.OnDeprecatedFieldUsed
+	IsDeprecated bool
+	// Complexity is the weight of the field, which is added to Resolvable.ComplexityScore for each resolved occurrence of the field
+	Complexity int
+	// CacheHit is set by the fetch layer if the data of the field was served from a cache instead of a live fetch, see Context.IncludeCacheStatus
+	CacheHit bool
+	// Line and Column are the location of the field in the source operation.
+	// If set, errors generated for the field render them as locations.
+	Line   uint32
+	Column uint32
 }
 
 func (i *FieldInfo) Merge(other *FieldInfo) {
@@ -1,12 +1,39 @@
 package resolve
 
-import "slices"
+import (
+	"bytes"
+	"slices"
+)
+
+// ScalarValidator validates the value of a JSON scalar, e.g. each item of a list of heterogeneous scalars
+type ScalarValidator interface {
+	ValidateScalar(ctx *Context, value []byte) error
+}
 
 type Scalar struct {
 	Path     []string
 	Nullable bool
 	Export   *FieldExport `json:"export,omitempty"`
-}
+	// DefaultValue is rendered instead of null if the value is nullable and absent in the data
+	// Explicit null values are rendered as null. The default value must be compact JSON, e.g. `""`, `0` or `[]`
+	DefaultValue []byte `json:"default_value,omitempty"`
+	// Validator is optional and invoked for each value of the scalar
+	// When used as an array item, the error path contains the index of the invalid item
+	Validator ScalarValidator `json:"-"`
+	// MaxBytes limits the length of string values of the scalar, see String.MaxBytes
+	MaxBytes     int          `json:"max_bytes,omitempty"`
+	MaxBytesMode MaxBytesMode `json:"max_bytes_mode,omitempty"`
+}
+
+// MaxBytesMode defines how string values exceeding MaxBytes are handled
+type MaxBytesMode int
+
+const (
+	// MaxBytesTruncate truncates the value and adds a warning
+	MaxBytesTruncate MaxBytesMode = iota
+	// MaxBytesError adds an error like for a value which doesn't match the type of the field
+	MaxBytesError
+)
 
 func (_ *Scalar) NodeKind() NodeKind {
 	return NodeKindScalar
@@ -30,6 +57,14 @@ func (s *Scalar) Equals(n Node) bool {
 		return false
 	}
 
+	if s.MaxBytes != other.MaxBytes || s.MaxBytesMode != other.MaxBytesMode {
+		return false
+	}
+
+	if !bytes.Equal(s.DefaultValue, other.DefaultValue) {
+		return false
+	}
+
 	if !slices.Equal(s.Path, other.Path) {
 		return false
 	}
@@ -43,6 +78,19 @@ type String struct {
 	Export               *FieldExport `json:"export,omitempty"`
 	UnescapeResponseJson bool         `json:"unescape_response_json,omitempty"`
 	IsTypeName           bool         `json:"is_type_name,omitempty"`
+	// UnescapeDepth is the number of nested levels unescaped with UnescapeResponseJson, e.g. 2 for doubly encoded JSON
+	// Unescaping stops early if a level is no JSON string. 0 and 1 unescape a single level
+	UnescapeDepth int `json:"unescape_depth,omitempty"`
+	// StrictUnescape adds an error if a level unescaped with UnescapeResponseJson isn't valid JSON
+	// By default, the last valid level is rendered, which is the string itself if the first level is invalid
+	StrictUnescape bool `json:"strict_unescape,omitempty"`
+	// DefaultValue is rendered if the nullable value is absent, see Scalar.DefaultValue
+	DefaultValue []byte `json:"default_value,omitempty"`
+	// MaxBytes limits the length of the JSON escaped value, 0 means no limit
+	// Truncation never splits a multibyte character or an escape sequence, so the value may be shorter than MaxBytes
+	// It is ignored if UnescapeResponseJson is set
+	MaxBytes     int          `json:"max_bytes,omitempty"`
+	MaxBytesMode MaxBytesMode `json:"max_bytes_mode,omitempty"`
 }
 
 func (s *String) Equals(n Node) bool {
@@ -59,10 +107,22 @@ func (s *String) Equals(n Node) bool {
 		return false
 	}
 
+	if s.UnescapeDepth != other.UnescapeDepth || s.StrictUnescape != other.StrictUnescape {
+		return false
+	}
+
 	if s.IsTypeName != other.IsTypeName {
 		return false
 	}
 
+	if s.MaxBytes != other.MaxBytes || s.MaxBytesMode != other.MaxBytesMode {
+		return false
+	}
+
+	if !bytes.Equal(s.DefaultValue, other.DefaultValue) {
+		return false
+	}
+
 	if !slices.Equal(s.Path, other.Path) {
 		return false
 	}
@@ -120,6 +180,8 @@ type Boolean struct {
 	Path     []string
 	Nullable bool
 	Export   *FieldExport `json:"export,omitempty"`
+	// DefaultValue is rendered if the nullable value is absent, see Scalar.DefaultValue
+	DefaultValue []byte `json:"default_value,omitempty"`
 }
 
 func (_ *Boolean) NodeKind() NodeKind {
@@ -144,6 +206,10 @@ func (b *Boolean) Equals(n Node) bool {
 		return false
 	}
 
+	if !bytes.Equal(b.DefaultValue, other.DefaultValue) {
+		return false
+	}
+
 	if !slices.Equal(b.Path, other.Path) {
 		return false
 	}
@@ -155,6 +221,8 @@ type Float struct {
 	Path     []string
 	Nullable bool
 	Export   *FieldExport `json:"export,omitempty"`
+	// DefaultValue is rendered if the nullable value is absent, see Scalar.DefaultValue
+	DefaultValue []byte `json:"default_value,omitempty"`
 }
 
 func (_ *Float) NodeKind() NodeKind {
@@ -179,6 +247,10 @@ func (f *Float) Equals(n Node) bool {
 		return false
 	}
 
+	if !bytes.Equal(f.DefaultValue, other.DefaultValue) {
+		return false
+	}
+
 	if !slices.Equal(f.Path, other.Path) {
 		return false
 	}
@@ -190,6 +262,8 @@ type Integer struct {
 	Path     []string
 	Nullable bool
 	Export   *FieldExport `json:"export,omitempty"`
+	// DefaultValue is rendered if the nullable value is absent, see Scalar.DefaultValue
+	DefaultValue []byte `json:"default_value,omitempty"`
 }
 
 func (_ *Integer) NodeKind() NodeKind {
@@ -214,6 +288,10 @@ func (i *Integer) Equals(n Node) bool {
 		return false
 	}
 
+	if !bytes.Equal(i.DefaultValue, other.DefaultValue) {
+		return false
+	}
+
 	if !slices.Equal(i.Path, other.Path) {
 		return false
 	}
@@ -226,6 +304,8 @@ type BigInt struct {
 	Path     []string
 	Nullable bool
 	Export   *FieldExport `json:"export,omitempty"`
+	// DefaultValue is rendered if the nullable value is absent, see Scalar.DefaultValue
+	DefaultValue []byte `json:"default_value,omitempty"`
 }
 
 func (_ *BigInt) NodeKind() NodeKind {
@@ -250,6 +330,10 @@ func (b *BigInt) Equals(n Node) bool {
 		return false
 	}
 
+	if !bytes.Equal(b.DefaultValue, other.DefaultValue) {
+		return false
+	}
+
 	if !slices.Equal(b.Path, other.Path) {
 		return false
 	}
@@ -0,0 +1,60 @@
+package resolve
+
+import (
+	"io"
+	"slices"
+)
+
+// ArraySource opens the JSON array of a StreamedArray
+type ArraySource interface {
+	// OpenArray returns a reader of the JSON array for the value of the StreamedArray in the data
+	// If the reader is an io.Closer, it's closed after the array is read
+	OpenArray(ctx *Context, value []byte) (io.Reader, error)
+}
+
+// StreamedArray is a list whose items are decoded one by one from a reader, e.g. a very large upstream list.
+// The value at Path in the data is passed to the Source, e.g. the reference of the list for the transport.
+// The items are walked one by one during the first walk, so the list is never parsed into the data as a whole.
+// As the errors of the response are printed before the data, each resolved item is printed into a buffer,
+// which is written to the response in place of the array.
+// Only the nodes of the storage are bounded to a single item, the printed items are held in the buffer until the response is written.
+// An item which can't be resolved adds its error and sets a nullable array to null, like an item of an Array.
+type StreamedArray struct {
+	Path     []string
+	Nullable bool
+	Item     Node
+	Source   ArraySource `json:"-"`
+}
+
+func (_ *StreamedArray) NodeKind() NodeKind {
+	return NodeKindStreamedArray
+}
+
+func (a *StreamedArray) NodePath() []string {
+	return a.Path
+}
+
+func (a *StreamedArray) NodeNullable() bool {
+	return a.Nullable
+}
+
+func (a *StreamedArray) Equals(n Node) bool {
+	other, ok := n.(*StreamedArray)
+	if !ok {
+		return false
+	}
+
+	if a.Nullable != other.Nullable {
+		return false
+	}
+
+	if !slices.Equal(a.Path, other.Path) {
+		return false
+	}
+
+	if a.Source != other.Source {
+		return false
+	}
+
+	return a.Item.Equals(other.Item)
+}
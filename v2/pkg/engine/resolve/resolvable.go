@@ -1,16 +1,22 @@
 package resolve
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	goerrors "errors"
 	"fmt"
 	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/cespare/xxhash/v2"
+	"github.com/google/uuid"
 	"github.com/tidwall/gjson"
 
 	"github.com/wundergraph/graphql-go-tools/v2/pkg/ast"
@@ -21,53 +27,129 @@ import (
 
 type Resolvable struct {
 	storage            *astjson.JSON
+	sharedStorage      bool
 	dataRoot           int
+	resolvedDataRoot   int
+	previousDataRoot   int
+	passThroughRoot    *Object
 	errorsRoot         int
+	warningsRoot       int
+	softErrorsRoot     int
 	variablesRoot      int
 	print              bool
 	out                io.Writer
+	outCounter         countingWriter
+	outBuffer          *bufio.Writer
+	asciiOut           asciiWriter
+	contentHash        uint64
+	contentDigest      *xxhash.Digest
+	fieldByteSizes     map[string]int
+	pageInfos          map[int]int
+	fileRefs           []FileRefPart
+	fileRefIndexes     map[fileRefKey]int
+	fieldSources       map[string][]string
+	fieldStates        map[string]string
+	cacheStatus        map[string]string
+	errorPaths         map[errorPathKey]struct{}
+	deniedFields       []string
+	retryableFields    []int
+	complexityScore    int
+	customResults      map[string][]byte
+	streamedArrays     map[int]*streamedArray
+	streamedArrayNodes map[int]*streamedArray
+	softErrors         int
+	cacheControl       CacheControl
+	hasCacheControl    bool
 	printErr           error
 	path               []astjson.PathElement
+	fieldInfo          *FieldInfo
 	depth              int
 	operationType      ast.OperationType
 	renameTypeNames    []RenameTypeName
+	reverseTypeNames   map[string][]byte
+	// transformedObjects maps the ref of a transformed object to the ref of its copy, see transformObject
+	// The entries are only valid as long as both nodes exist, so code which removes nodes and reuses their refs
+	// must drop the entries of the removed nodes, like truncateStorage and Reset do.
+	transformedObjects map[int]int
 	ctx                *Context
 	authorizationError error
+	failFast           bool
 	xxh                *xxhash.Digest
 	authorizationAllow map[uint64]struct{}
 	authorizationDeny  map[uint64]string
 
+	authorizationCacheStats AuthorizationCacheStats
+	authorizationDecisions  []AuthorizationDecision
+
 	authorizationBuf          *bytes.Buffer
+	errorBuf                  *bytes.Buffer
+	errorEncoder              *json.Encoder
 	authorizationBufObjectRef int
 
 	wroteErrors bool
 	wroteData   bool
+	dataPresent bool
 }
 
 func NewResolvable() *Resolvable {
 	return &Resolvable{
 		storage:            &astjson.JSON{},
 		xxh:                xxhash.New(),
+		contentDigest:      xxhash.New(),
 		authorizationAllow: make(map[uint64]struct{}),
 		authorizationDeny:  make(map[uint64]string),
+		fieldByteSizes:     make(map[string]int),
+		previousDataRoot:   astjson.InvalidRef,
 	}
 }
 
+// NewResolvableWithStorage creates a Resolvable that walks data parsed into an existing storage.
+// This allows one parsed document to back several resolves, e.g. to render different response shapes.
+// Use InitWithDataRoot to resolve against a data root appended to the storage.
+//
+// The storage is not safe for concurrent use: Resolvables sharing a storage must resolve sequentially,
+// because resolving appends nodes to the storage.
+// Resolving also marks invalid values as null in the storage,
+// so such values are rendered as null by subsequent resolves against the same data.
+// Reset does not reset a shared storage, this is the responsibility of the owner of the storage.
+func NewResolvableWithStorage(storage *astjson.JSON) *Resolvable {
+	r := NewResolvable()
+	r.storage = storage
+	r.sharedStorage = true
+	return r
+}
+
 func (r *Resolvable) Reset() {
-	r.storage.Reset()
+	if !r.sharedStorage {
+		r.storage.Reset()
+	}
 	r.wroteErrors = false
 	r.wroteData = false
+	r.dataPresent = false
 	r.dataRoot = -1
+	r.resolvedDataRoot = -1
+	r.previousDataRoot = -1
+	r.passThroughRoot = nil
 	r.errorsRoot = -1
+	r.warningsRoot = -1
+	r.softErrorsRoot = -1
 	r.variablesRoot = -1
 	r.depth = 0
 	r.print = false
 	r.out = nil
 	r.printErr = nil
 	r.path = r.path[:0]
+	r.fieldInfo = nil
 	r.operationType = ast.OperationTypeUnknown
 	r.renameTypeNames = r.renameTypeNames[:0]
+	for k := range r.reverseTypeNames {
+		delete(r.reverseTypeNames, k)
+	}
+	for k := range r.transformedObjects {
+		delete(r.transformedObjects, k)
+	}
 	r.authorizationError = nil
+	r.failFast = false
 	r.xxh.Reset()
 	r.authorizationBufObjectRef = -1
 	for k := range r.authorizationAllow {
@@ -76,6 +158,47 @@ func (r *Resolvable) Reset() {
 	for k := range r.authorizationDeny {
 		delete(r.authorizationDeny, k)
 	}
+	r.authorizationCacheStats = AuthorizationCacheStats{}
+	r.authorizationDecisions = r.authorizationDecisions[:0]
+	for k := range r.fieldByteSizes {
+		delete(r.fieldByteSizes, k)
+	}
+	for k := range r.pageInfos {
+		delete(r.pageInfos, k)
+	}
+	r.fileRefs = r.fileRefs[:0]
+	for k := range r.fileRefIndexes {
+		delete(r.fileRefIndexes, k)
+	}
+	for k := range r.fieldSources {
+		delete(r.fieldSources, k)
+	}
+	for k := range r.fieldStates {
+		delete(r.fieldStates, k)
+	}
+	for k := range r.cacheStatus {
+		delete(r.cacheStatus, k)
+	}
+	for k := range r.customResults {
+		delete(r.customResults, k)
+	}
+	for k := range r.streamedArrays {
+		delete(r.streamedArrays, k)
+	}
+	for k := range r.streamedArrayNodes {
+		delete(r.streamedArrayNodes, k)
+	}
+	for k := range r.errorPaths {
+		delete(r.errorPaths, k)
+	}
+	r.deniedFields = r.deniedFields[:0]
+	r.retryableFields = r.retryableFields[:0]
+	r.complexityScore = 0
+	r.softErrors = 0
+	r.cacheControl = CacheControl{}
+	r.hasCacheControl = false
+	r.outCounter = countingWriter{}
+	r.contentHash = 0
 }
 
 func (r *Resolvable) Init(ctx *Context, initialData []byte, operationType ast.OperationType) (err error) {
@@ -86,13 +209,77 @@ func (r *Resolvable) Init(ctx *Context, initialData []byte, operationType ast.Op
 	if err != nil {
 		return
 	}
+	return r.finishInit(ctx)
+}
+
+// InitWithDataRoot initializes the Resolvable to walk the data at dataRoot, which must be a ref into the storage of the Resolvable
+// In combination with NewResolvableWithStorage, this allows resolving against data parsed once into a shared storage
+func (r *Resolvable) InitWithDataRoot(ctx *Context, dataRoot int, operationType ast.OperationType) (err error) {
+	r.ctx = ctx
+	r.operationType = operationType
+	r.renameTypeNames = ctx.RenameTypeNames
+	r.dataRoot = dataRoot
+	r.errorsRoot, err = r.storage.AppendArray(emptyArray)
+	if err != nil {
+		return
+	}
+	return r.finishInit(ctx)
+}
+
+func (r *Resolvable) finishInit(ctx *Context) (err error) {
+	r.warningsRoot, err = r.storage.AppendArray(emptyArray)
+	if err != nil {
+		return
+	}
+	r.softErrorsRoot, err = r.storage.AppendArray(emptyArray)
+	if err != nil {
+		return
+	}
+	if ctx.IntrospectionData != nil {
+		err = r.mergeIntrospectionData(ctx.IntrospectionData)
+		if err != nil {
+			return
+		}
+	}
 	if len(ctx.Variables) != 0 {
 		r.variablesRoot, err = r.storage.AppendAnyJSONBytes(ctx.Variables)
 	}
 	return
 }
 
+// mergeIntrospectionData merges the introspection result into the data
+// Introspection fields (e.g. __schema, __type) are authoritative and replace existing values
+// For all other fields, the upstream data takes precedence and objects are merged deeply
+func (r *Resolvable) mergeIntrospectionData(introspection *IntrospectionData) error {
+	introspectionRef, err := r.storage.AppendObject(introspection.Data)
+	if err != nil {
+		return err
+	}
+	target := r.storage.Get(r.dataRoot, introspection.MergePath)
+	if !r.storage.NodeIsDefined(target) || r.storage.Nodes[target].Kind != astjson.NodeKindObject {
+		r.storage.MergeNodesWithPath(r.dataRoot, introspectionRef, introspection.MergePath)
+		return nil
+	}
+	for _, field := range r.storage.Nodes[introspectionRef].ObjectFields {
+		key := r.storage.ObjectFieldKey(field)
+		value := r.storage.ObjectFieldValue(field)
+		existing := r.storage.GetObjectFieldBytes(target, key)
+		if bytes.HasPrefix(key, literalIntrospectionPrefix) || !r.storage.NodeIsDefined(existing) {
+			r.storage.SetObjectFieldKeyBytes(target, value, key)
+			continue
+		}
+		r.storage.SetObjectFieldKeyBytes(target, r.storage.MergeNodes(value, existing), key)
+	}
+	return nil
+}
+
 func (r *Resolvable) InitSubscription(ctx *Context, initialData []byte, postProcessing PostProcessingConfiguration) (err error) {
+	return r.InitSubscriptionFromDataSource(ctx, "", initialData, postProcessing)
+}
+
+// InitSubscriptionFromDataSource initializes the Resolvable like InitSubscription for data received from the data source with dataSourceID
+// With Context.PrefixSubgraphErrors, the messages of the errors are prefixed with "[dataSourceID] " like the errors of fetches
+func (r *Resolvable) InitSubscriptionFromDataSource(ctx *Context, dataSourceID string, initialData []byte, postProcessing PostProcessingConfiguration) (err error) {
 	r.ctx = ctx
 	r.operationType = ast.OperationTypeSubscription
 	r.renameTypeNames = ctx.RenameTypeNames
@@ -106,6 +293,14 @@ func (r *Resolvable) InitSubscription(ctx *Context, initialData []byte, postProc
 	if err != nil {
 		return
 	}
+	r.warningsRoot, err = r.storage.AppendArray(emptyArray)
+	if err != nil {
+		return
+	}
+	r.softErrorsRoot, err = r.storage.AppendArray(emptyArray)
+	if err != nil {
+		return
+	}
 	raw, err := r.storage.AppendObject(initialData)
 	if err != nil {
 		return err
@@ -116,175 +311,754 @@ func (r *Resolvable) InitSubscription(ctx *Context, initialData []byte, postProc
 	}
 	errors := r.storage.Get(raw, postProcessing.SelectResponseErrorsPath)
 	if r.storage.NodeIsDefined(errors) {
+		if ctx.PrefixSubgraphErrors && r.storage.Nodes[errors].Kind == astjson.NodeKindArray {
+			prefixErrorMessages(r.storage, errors, dataSourceID)
+		}
 		r.storage.MergeArrays(r.errorsRoot, errors)
 	}
 	return
 }
 
+// InitSubscriptionDelta initializes the Resolvable like InitSubscription,
+// but Resolve only prints the fields of the data which changed compared to the previous response printed for the subscription.
+// Objects are compared field by field, all other values including lists are printed as a whole if they changed.
+// If previous is empty, e.g. for the first payload of a subscription, the complete data is printed.
+func (r *Resolvable) InitSubscriptionDelta(ctx *Context, newData, previous []byte, postProcessing PostProcessingConfiguration) (err error) {
+	err = r.InitSubscription(ctx, newData, postProcessing)
+	if err != nil || len(previous) == 0 {
+		return
+	}
+	previousRef, err := r.storage.AppendObject(previous)
+	if err != nil {
+		return err
+	}
+	r.previousDataRoot = r.storage.GetObjectFieldBytes(previousRef, literalData)
+	return
+}
+
+// printChunkSize is the size of the buffer batching the many small writes of the printer for unbuffered writers
+const printChunkSize = 4096
+
+// bufferedWriter is implemented by writers which buffer the writes in memory, e.g. *bytes.Buffer and *bufio.Writer
+type bufferedWriter interface {
+	AvailableBuffer() []byte
+}
+
 func (r *Resolvable) Resolve(ctx context.Context, rootData *Object, fetchTree *Object, out io.Writer) error {
-	r.out = out
+	if _, ok := out.(bufferedWriter); ok {
+		return r.resolve(ctx, rootData, fetchTree, out)
+	}
+	// batch the small writes of the printer, e.g. of commas and quotes, into chunks
+	if r.outBuffer == nil {
+		r.outBuffer = bufio.NewWriterSize(out, printChunkSize)
+	} else {
+		r.outBuffer.Reset(out)
+	}
+	err := r.resolve(ctx, rootData, fetchTree, r.outBuffer)
+	if flushErr := r.outBuffer.Flush(); err == nil {
+		err = flushErr
+	}
+	// don't retain the writer
+	r.outBuffer.Reset(nil)
+	return err
+}
+
+func (r *Resolvable) resolve(ctx context.Context, rootData *Object, fetchTree *Object, out io.Writer) error {
+	r.outCounter = countingWriter{out: out}
+	r.out = &r.outCounter
+	if r.ctx.ASCIIOnlyStrings {
+		r.asciiOut.out = &r.outCounter
+		r.out = &r.asciiOut
+	}
+	err, authorizationErr := r.walkData(rootData)
+	if authorizationErr != nil {
+		return authorizationErr
+	}
+	if r.ctx.NDJSONOutput {
+		if arr := ndjsonArray(rootData); arr != nil {
+			printErr := r.printNDJSON(ctx, rootData, arr, fetchTree, err)
+			r.afterDataWalk(err || printErr != nil)
+			return printErr
+		}
+	}
+	return r.encodeResponse(ctx, rootData, fetchTree, err, jsonResponseEncoder{r: r})
+}
+
+// walkData runs the first walk of the data and applies the error handling of the Context, independent of the response format
+// It returns true if the data can't be resolved, and the authorization error which aborts the response.
+func (r *Resolvable) walkData(rootData *Object) (bool, error) {
 	r.print = false
 	r.printErr = nil
 	r.authorizationError = nil
+	r.failFast = false
+	r.resolvedDataRoot = astjson.InvalidRef
+	r.passThroughRoot = nil
 
 	/* @TODO: In the event of an error or failed fetch, propagate only the highest level errors.
 	 * For example, if a fetch fails, only propagate that the fetch has failed; do not propagate nested non-null errors.
 	 */
 
+	if r.ctx.BeforeDataWalk != nil {
+		r.ctx.BeforeDataWalk()
+	}
 	_, err := r.walkObject(rootData, r.dataRoot)
 	if r.authorizationError != nil {
-		return r.authorizationError
+		r.afterDataWalk(true)
+		return false, r.authorizationError
+	}
+	err = err || r.failFast
+	if r.ctx.SuppressSoftErrorsTopLevel {
+		r.relocateSoftErrors()
+	}
+	if r.ctx.SynthesizeEmptyResponseError && !r.hasData() && !r.hasErrors() {
+		r.appendGraphQLError(r.errorsRoot, "No data resolved.", ErrorCategoryInternal)
+	}
+	if r.ctx.IncludeRetryableFields {
+		r.collectRetryableFields()
+	}
+	return err, nil
+}
+
+// responseEncoder writes the entries of the response, e.g. as JSON or MessagePack, see encodeResponse
+type responseEncoder interface {
+	begin()
+	// singleError writes the error entry of Context.SimplifySingleError with the message node of the error
+	singleError(message int)
+	errors()
+	// data writes the data entry, or null if the data can't be resolved
+	data(rootData *Object, nullData bool)
+	hasNext(hasNext bool)
+	extensions(ctx context.Context, fetchTree *Object) error
+	end() error
+}
+
+// encodeResponse writes the response after the first walk, err is true if the data can't be resolved
+func (r *Resolvable) encodeResponse(ctx context.Context, rootData *Object, fetchTree *Object, err bool, enc responseEncoder) error {
+	enc.begin()
+	if r.ctx.SimplifySingleError && (err || r.nullDataOnErrors()) && len(r.storage.Nodes[r.errorsRoot].ArrayValues) == 1 {
+		r.afterDataWalk(true)
+		enc.singleError(r.storage.GetObjectFieldBytes(r.storage.Nodes[r.errorsRoot].ArrayValues[0], literalMessage))
+		r.wroteErrors = true
+		return enc.end()
 	}
-	r.printBytes(lBrace)
 	if r.hasErrors() {
-		r.printErrors()
+		if r.ctx.SortErrorsByPath {
+			r.sortErrors()
+		}
+		enc.errors()
+		r.wroteErrors = true
+	}
+	enc.data(rootData, err || r.nullDataOnErrors())
+	r.afterDataWalk(err || r.printErr != nil)
+	if r.ctx.IncrementalEnvelope {
+		enc.hasNext(false)
+	}
+	if r.hasExtensions(fetchTree) {
+		if extensionsErr := enc.extensions(ctx, fetchTree); extensionsErr != nil {
+			return extensionsErr
+		}
 	}
+	return enc.end()
+}
 
-	if err {
-		r.printBytes(quote)
-		r.printBytes(literalData)
-		r.printBytes(quote)
-		r.printBytes(colon)
-		r.printBytes(null)
+// jsonResponseEncoder prints the response as JSON to the output of the Resolvable
+type jsonResponseEncoder struct {
+	r *Resolvable
+}
+
+func (e jsonResponseEncoder) begin() {
+	e.r.printBytes(lBrace)
+}
+
+func (e jsonResponseEncoder) singleError(message int) {
+	e.r.printBytes(quote)
+	e.r.printBytes(literalError)
+	e.r.printBytes(quote)
+	e.r.printBytes(colon)
+	if e.r.storage.NodeIsDefined(message) {
+		e.r.printNode(message)
+	} else {
+		e.r.printBytes(null)
+	}
+}
+
+func (e jsonResponseEncoder) errors() {
+	e.r.printErrors()
+}
+
+func (e jsonResponseEncoder) data(rootData *Object, nullData bool) {
+	if nullData {
+		e.r.printBytes(quote)
+		e.r.printBytes(literalData)
+		e.r.printBytes(quote)
+		e.r.printBytes(colon)
+		e.r.printBytes(null)
+		return
+	}
+	e.r.printData(rootData)
+}
+
+func (e jsonResponseEncoder) hasNext(hasNext bool) {
+	e.r.printHasNext(hasNext)
+}
+
+func (e jsonResponseEncoder) extensions(ctx context.Context, fetchTree *Object) error {
+	e.r.printBytes(comma)
+	return e.r.printExtensions(ctx, fetchTree)
+}
+
+func (e jsonResponseEncoder) end() error {
+	e.r.printBytes(rBrace)
+	return e.r.printErr
+}
+
+// relocateSoftErrors moves the errors to extensions.softErrors if all of them are soft errors, see Context.SuppressSoftErrorsTopLevel
+func (r *Resolvable) relocateSoftErrors() {
+	errorRefs := r.storage.Nodes[r.errorsRoot].ArrayValues
+	if r.softErrors == 0 || len(errorRefs) != r.softErrors {
+		return
+	}
+	r.storage.Nodes[r.softErrorsRoot].ArrayValues = append(r.storage.Nodes[r.softErrorsRoot].ArrayValues, errorRefs...)
+	r.storage.Nodes[r.errorsRoot].ArrayValues = errorRefs[:0]
+}
+
+// printHasNext prints the hasNext field of the incremental delivery envelope, see Context.IncrementalEnvelope
+func (r *Resolvable) printHasNext(hasNext bool) {
+	r.printBytes(comma)
+	r.printBytes(quote)
+	r.printBytes(literalHasNext)
+	r.printBytes(quote)
+	r.printBytes(colon)
+	if hasNext {
+		r.printBytes(literalTrue)
 	} else {
-		r.printData(rootData)
+		r.printBytes(literalFalse)
+	}
+}
+
+// afterDataWalk calls Context.AfterDataWalk once the data of the response is walked, including early returns on errors
+func (r *Resolvable) afterDataWalk(hadError bool) {
+	if r.ctx.AfterDataWalk != nil {
+		r.ctx.AfterDataWalk(hadError)
+	}
+}
+
+// MergeEntities merges the results of an _entities fetch into the placeholder objects of the data.
+// entitiesJSON is either the _entities list or a subgraph response containing data._entities.
+// The entity at index i is merged into the object at targetPaths[i], null entities leave the placeholder untouched.
+// It must be called after Init and before Resolve.
+func (r *Resolvable) MergeEntities(entitiesJSON []byte, targetPaths [][]astjson.PathElement) error {
+	entitiesRef, err := r.storage.AppendAnyJSONBytes(entitiesJSON)
+	if err != nil {
+		return err
+	}
+	if r.storage.Nodes[entitiesRef].Kind == astjson.NodeKindObject {
+		entitiesRef = r.storage.GetObjectFieldBytes(entitiesRef, literalData)
+		if r.storage.NodeIsDefined(entitiesRef) {
+			entitiesRef = r.storage.GetObjectFieldBytes(entitiesRef, literalUnderscoreEntities)
+		}
+	}
+	if !r.storage.NodeIsDefined(entitiesRef) || r.storage.Nodes[entitiesRef].Kind != astjson.NodeKindArray {
+		return errors.New("entities must be a JSON array")
+	}
+	entities := r.storage.Nodes[entitiesRef].ArrayValues
+	if len(entities) != len(targetPaths) {
+		return fmt.Errorf("got %d entities for %d target paths", len(entities), len(targetPaths))
+	}
+	for i := range entities {
+		target := r.getPathElements(r.dataRoot, targetPaths[i])
+		if !r.storage.NodeIsDefined(target) || r.storage.Nodes[target].Kind != astjson.NodeKindObject {
+			return fmt.Errorf("no placeholder object for entity %d", i)
+		}
+		if !r.storage.NodeIsDefined(entities[i]) {
+			continue
+		}
+		r.storage.MergeNodes(target, entities[i])
+	}
+	return nil
+}
+
+// getPathElements returns the node at the path relative to ref, or InvalidRef if the path doesn't exist
+func (r *Resolvable) getPathElements(ref int, path []astjson.PathElement) int {
+	for i := range path {
+		if !r.storage.NodeIsDefined(ref) {
+			return astjson.InvalidRef
+		}
+		if path[i].Name != "" {
+			ref = r.storage.GetObjectField(ref, path[i].Name)
+			continue
+		}
+		if r.storage.Nodes[ref].Kind != astjson.NodeKindArray || path[i].ArrayIndex < 0 || path[i].ArrayIndex >= len(r.storage.Nodes[ref].ArrayValues) {
+			return astjson.InvalidRef
+		}
+		ref = r.storage.Nodes[ref].ArrayValues[path[i].ArrayIndex]
+	}
+	return ref
+}
+
+// ResolveCached prints a cached response instead of walking the data, e.g. for a cache hit on the operation and its variables.
+// The cached response must be a JSON object without extensions, e.g. as printed by Resolve.
+// The extensions of the current request, e.g. rate limiting stats or authorizer data, are added to the cached response.
+// As no fetches are executed, the trace is omitted. It must be called after Init.
+func (r *Resolvable) ResolveCached(cached []byte, out io.Writer) error {
+	cached = bytes.TrimSpace(cached)
+	if len(cached) < 2 || cached[0] != '{' || cached[len(cached)-1] != '}' {
+		return errors.New("cached response must be a JSON object")
+	}
+	r.outCounter = countingWriter{out: out}
+	r.out = &r.outCounter
+	r.printErr = nil
+	if !r.hasExtensions(nil) {
+		r.printBytes(cached)
+		return r.printErr
 	}
-	if r.hasExtensions() {
+	// print the cached response without the closing brace to append the extensions
+	r.printBytes(cached[:len(cached)-1])
+	if len(bytes.TrimSpace(cached[1:len(cached)-1])) != 0 {
 		r.printBytes(comma)
-		r.printErr = r.printExtensions(ctx, fetchTree)
+	}
+	if err := r.printExtensions(r.ctx.Context(), nil); err != nil {
+		return err
 	}
 	r.printBytes(rBrace)
+	return r.printErr
+}
+
+// BatchItem is a single operation of a batched request, see Resolvable.ResolveBatch
+type BatchItem struct {
+	Ctx           *Context
+	Data          []byte
+	OperationType ast.OperationType
+	RootData      *Object
+	FetchTree     *Object
+}
+
+// ResolveBatch resolves the operations of a batched request one after another and prints the responses as a JSON array
+// The Resolvable is reset before each operation, so it must not be initialized before.
+// Errors of an operation are part of its response, ResolveBatch only returns an error if an operation can't be resolved at all,
+// in which case the output is incomplete.
+func (r *Resolvable) ResolveBatch(items []BatchItem, out io.Writer) error {
+	if _, err := out.Write(lBrack); err != nil {
+		return err
+	}
+	for i := range items {
+		if i != 0 {
+			if _, err := out.Write(comma); err != nil {
+				return err
+			}
+		}
+		r.Reset()
+		if err := r.Init(items[i].Ctx, items[i].Data, items[i].OperationType); err != nil {
+			return err
+		}
+		if err := r.Resolve(items[i].Ctx.Context(), items[i].RootData, items[i].FetchTree, out); err != nil {
+			return err
+		}
+	}
+	_, err := out.Write(rBrack)
+	return err
+}
+
+// ndjsonArray returns the list value of the root field if the root object has a single list field
+func ndjsonArray(rootData *Object) *Array {
+	if len(rootData.Fields) != 1 {
+		return nil
+	}
+	arr, _ := rootData.Fields[0].Value.(*Array)
+	return arr
+}
 
+// printNDJSON prints each item of the root list as a JSON line, followed by a line with errors and extensions, if any
+func (r *Resolvable) printNDJSON(ctx context.Context, rootData *Object, arr *Array, fetchTree *Object, dataErr bool) error {
+	if !dataErr {
+		r.print = true
+		arrayNodeRef, _ := r.walkArray(arr, r.storage.Get(r.dataRoot, rootData.Path))
+		r.print = false
+		if r.storage.NodeIsDefined(arrayNodeRef) && r.storage.Nodes[arrayNodeRef].Kind == astjson.NodeKindArray {
+			for _, item := range r.storage.Nodes[arrayNodeRef].ArrayValues {
+				r.printNode(item)
+				r.printBytes(newLine)
+			}
+			r.dataPresent = len(r.storage.Nodes[arrayNodeRef].ArrayValues) != 0
+		}
+		r.wroteData = true
+	}
+	hasErrors, hasExtensions := r.hasErrors(), r.hasExtensions(fetchTree)
+	if !hasErrors && !hasExtensions {
+		return r.printErr
+	}
+	r.printBytes(lBrace)
+	if hasErrors {
+		if r.ctx.SortErrorsByPath {
+			r.sortErrors()
+		}
+		r.printErrorsField()
+	}
+	if hasExtensions {
+		if hasErrors {
+			r.printBytes(comma)
+		}
+		r.printErr = r.printExtensions(ctx, fetchTree)
+	}
+	r.printBytes(rBrace)
+	r.printBytes(newLine)
 	return r.printErr
 }
 
+func (r *Resolvable) nullDataOnErrors() bool {
+	return r.ctx.NullDataOnErrors && !r.hasData() && r.hasErrors()
+}
+
 func (r *Resolvable) err() bool {
 	return true
 }
 
 func (r *Resolvable) printErrors() {
+	r.printErrorsField()
+	r.printBytes(comma)
+}
+
+func (r *Resolvable) printErrorsField() {
 	r.printBytes(quote)
 	r.printBytes(literalErrors)
 	r.printBytes(quote)
 	r.printBytes(colon)
 	r.printNode(r.errorsRoot)
-	r.printBytes(comma)
 	r.wroteErrors = true
 }
 
+// sortErrors sorts the errors by path, then by message
+// Errors without a path are sorted first
+func (r *Resolvable) sortErrors() {
+	slices.SortStableFunc(r.storage.Nodes[r.errorsRoot].ArrayValues, func(a, b int) int {
+		if c := r.compareErrorPaths(r.storage.GetObjectFieldBytes(a, literalPath), r.storage.GetObjectFieldBytes(b, literalPath)); c != 0 {
+			return c
+		}
+		return bytes.Compare(r.errorMessage(a), r.errorMessage(b))
+	})
+}
+
+func (r *Resolvable) errorMessage(ref int) []byte {
+	message := r.storage.GetObjectFieldBytes(ref, literalMessage)
+	if !r.storage.NodeIsDefined(message) {
+		return nil
+	}
+	return r.storage.Nodes[message].ValueBytes(r.storage)
+}
+
+func (r *Resolvable) compareErrorPaths(a, b int) int {
+	var left, right []int
+	if r.storage.NodeIsDefined(a) && r.storage.Nodes[a].Kind == astjson.NodeKindArray {
+		left = r.storage.Nodes[a].ArrayValues
+	}
+	if r.storage.NodeIsDefined(b) && r.storage.Nodes[b].Kind == astjson.NodeKindArray {
+		right = r.storage.Nodes[b].ArrayValues
+	}
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if c := r.compareErrorPathElements(left[i], right[i]); c != 0 {
+			return c
+		}
+	}
+	return len(left) - len(right)
+}
+
+// compareErrorPathElements sorts array indices numerically and before field names
+func (r *Resolvable) compareErrorPathElements(a, b int) int {
+	left, right := r.storage.Nodes[a], r.storage.Nodes[b]
+	leftIsIndex, rightIsIndex := left.Kind == astjson.NodeKindNumber, right.Kind == astjson.NodeKindNumber
+	switch {
+	case leftIsIndex && rightIsIndex:
+		leftIndex, _ := strconv.Atoi(unsafebytes.BytesToString(left.ValueBytes(r.storage)))
+		rightIndex, _ := strconv.Atoi(unsafebytes.BytesToString(right.ValueBytes(r.storage)))
+		return leftIndex - rightIndex
+	case leftIsIndex:
+		return -1
+	case rightIsIndex:
+		return 1
+	}
+	return bytes.Compare(left.ValueBytes(r.storage), right.ValueBytes(r.storage))
+}
+
 func (r *Resolvable) printData(root *Object) {
 	r.printBytes(quote)
 	r.printBytes(literalData)
 	r.printBytes(quote)
 	r.printBytes(colon)
-	r.print = true
-	resolvedDataNodeRef, _ := r.walkObject(root, r.dataRoot)
-	r.printNode(resolvedDataNodeRef)
-	r.print = false
+	r.dataPresent = r.rootDataPresent(root)
+	r.contentDigest.Reset()
+	r.outCounter.hash = r.contentDigest
+	defer r.finishContentHash()
+	if r.passThroughEligible(root) {
+		r.passThroughRoot = root
+		r.printPassThroughObject(root, r.dataRoot)
+		r.wroteData = true
+		return
+	}
+	if r.parallelRootFieldsEligible(root) {
+		r.printParallelRootFields(root)
+		r.wroteData = true
+		return
+	}
+	r.walkResolvedData(root)
+	r.printResolvedData(r.resolvedDataRoot)
 	r.wroteData = true
 }
 
-func (r *Resolvable) printExtensions(ctx context.Context, fetchTree *Object) error {
-	r.printBytes(quote)
-	r.printBytes(literalExtensions)
-	r.printBytes(quote)
-	r.printBytes(colon)
-	r.printBytes(lBrace)
-
-	var (
-		writeComma bool
-	)
+// walkResolvedData runs the print walk, which builds the resolved tree of the data in the storage
+func (r *Resolvable) walkResolvedData(root *Object) {
+	r.print = true
+	r.resolvedDataRoot, _ = r.walkObject(root, r.dataRoot)
+	r.print = false
+	if r.storage.NodeIsDefined(r.previousDataRoot) {
+		r.removeUnchangedFields(r.resolvedDataRoot, r.previousDataRoot)
+	}
+}
 
-	if r.ctx.authorizer != nil && r.ctx.authorizer.HasResponseExtensionData(r.ctx) {
-		writeComma = true
-		err := r.printAuthorizerExtension()
-		if err != nil {
-			return err
+// removeUnchangedFields removes all fields of the object at ref which are equal to the fields of the previous object
+// It returns true if no field is left
+func (r *Resolvable) removeUnchangedFields(ref, previous int) bool {
+	if r.storage.Nodes[ref].Kind != astjson.NodeKindObject || r.storage.Nodes[previous].Kind != astjson.NodeKindObject {
+		return false
+	}
+	fields := make([]int, 0, len(r.storage.Nodes[ref].ObjectFields))
+	for _, field := range r.storage.Nodes[ref].ObjectFields {
+		value := r.storage.ObjectFieldValue(field)
+		previousValue := r.storage.GetObjectFieldBytes(previous, r.storage.ObjectFieldKey(field))
+		if !r.storage.NodeIsDefined(previousValue) {
+			fields = append(fields, field)
+			continue
+		}
+		if r.storage.Nodes[value].Kind == astjson.NodeKindObject && r.storage.Nodes[previousValue].Kind == astjson.NodeKindObject {
+			if !r.removeUnchangedFields(value, previousValue) {
+				fields = append(fields, field)
+			}
+			continue
+		}
+		if !r.nodesEqual(value, previousValue) {
+			fields = append(fields, field)
 		}
 	}
+	r.storage.Nodes[ref].ObjectFields = fields
+	return len(fields) == 0
+}
 
-	if r.ctx.RateLimitOptions.Enable && r.ctx.RateLimitOptions.IncludeStatsInResponseExtension && r.ctx.rateLimiter != nil {
-		if writeComma {
-			r.printBytes(comma)
+func (r *Resolvable) nodesEqual(left, right int) bool {
+	if r.storage.Nodes[left].Kind != r.storage.Nodes[right].Kind {
+		return false
+	}
+	switch r.storage.Nodes[left].Kind {
+	case astjson.NodeKindObject:
+		if len(r.storage.Nodes[left].ObjectFields) != len(r.storage.Nodes[right].ObjectFields) {
+			return false
 		}
-		writeComma = true
-		err := r.printRateLimitingExtension()
-		if err != nil {
-			return err
+		for _, field := range r.storage.Nodes[left].ObjectFields {
+			value := r.storage.GetObjectFieldBytes(right, r.storage.ObjectFieldKey(field))
+			if !r.storage.NodeIsDefined(value) || !r.nodesEqual(r.storage.ObjectFieldValue(field), value) {
+				return false
+			}
+		}
+		return true
+	case astjson.NodeKindArray:
+		if len(r.storage.Nodes[left].ArrayValues) != len(r.storage.Nodes[right].ArrayValues) {
+			return false
 		}
+		for i := range r.storage.Nodes[left].ArrayValues {
+			if !r.nodesEqual(r.storage.Nodes[left].ArrayValues[i], r.storage.Nodes[right].ArrayValues[i]) {
+				return false
+			}
+		}
+		return true
+	case astjson.NodeKindNull:
+		return true
+	default:
+		return bytes.Equal(r.storage.Nodes[left].ValueBytes(r.storage), r.storage.Nodes[right].ValueBytes(r.storage))
 	}
+}
 
-	if r.ctx.TracingOptions.Enable && r.ctx.TracingOptions.IncludeTraceOutputInResponseExtensions {
-		if writeComma {
+// printResolvedData prints the resolved data root field by field to track the bytes written per root field
+func (r *Resolvable) printResolvedData(ref int) {
+	if r.storage.Nodes[ref].Kind != astjson.NodeKindObject {
+		r.printNode(ref)
+		return
+	}
+	r.printBytes(lBrace)
+	for i, field := range r.storage.Nodes[ref].ObjectFields {
+		if i != 0 {
 			r.printBytes(comma)
 		}
-		err := r.printTraceExtension(ctx, fetchTree)
-		if err != nil {
-			return err
-		}
+		start := r.outCounter.written
+		key := r.storage.ObjectFieldKey(field)
+		r.printBytes(quote)
+		r.printBytes(key)
+		r.printBytes(quote)
+		r.printBytes(colon)
+		r.printNode(r.storage.ObjectFieldValue(field))
+		r.fieldByteSizes[string(key)] += r.outCounter.written - start
 	}
-
 	r.printBytes(rBrace)
-	return nil
 }
 
-func (r *Resolvable) printAuthorizerExtension() error {
-	r.printBytes(quote)
-	r.printBytes(literalAuthorization)
-	r.printBytes(quote)
-	r.printBytes(colon)
-	return r.ctx.authorizer.RenderResponseExtension(r.ctx, r.out)
+// FieldByteSizes returns the number of bytes each root field contributed to the data of the response
+// The size of a field includes its key. The returned map is only valid until the next call to Reset.
+func (r *Resolvable) FieldByteSizes() map[string]int {
+	return r.fieldByteSizes
 }
 
-func (r *Resolvable) printRateLimitingExtension() error {
-	r.printBytes(quote)
-	r.printBytes(literalRateLimit)
-	r.printBytes(quote)
-	r.printBytes(colon)
-	return r.ctx.rateLimiter.RenderResponseExtension(r.ctx, r.out)
+// countingWriter counts the bytes written to the response
+// If hash is set, the written bytes are also written to the hash
+type countingWriter struct {
+	out     io.Writer
+	written int
+	hash    *xxhash.Digest
 }
 
-func (r *Resolvable) printTraceExtension(ctx context.Context, fetchTree *Object) error {
-	var trace *TraceNode
-	if r.ctx.TracingOptions.Debug {
-		trace = GetTrace(ctx, fetchTree, GetTraceDebug())
-	} else {
-		trace = GetTrace(ctx, fetchTree)
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = w.out.Write(p)
+	w.written += n
+	if w.hash != nil {
+		_, _ = w.hash.Write(p[:n])
+	}
+	return
+}
+
+func (r *Resolvable) finishContentHash() {
+	r.outCounter.hash = nil
+	r.contentHash = r.contentDigest.Sum64()
+}
+
+// ContentHash returns a hash of the data printed by Resolve, e.g. to derive a strong ETag
+// The hash is stable for identical data and empty if no data was printed
+func (r *Resolvable) ContentHash() string {
+	if !r.wroteData {
+		return ""
+	}
+	return strconv.FormatUint(r.contentHash, 16)
+}
+
+// ResolvedData returns the storage and the ref of the resolved data root produced by the print walk of Resolve.
+// Callers can use it to post-process the resolved tree and print it again.
+// The returned storage and ref are only valid until the next call to Reset.
+// If no data was printed, the ref is astjson.InvalidRef.
+func (r *Resolvable) ResolvedData() (*astjson.JSON, int) {
+	r.materializeResolvedData()
+	return r.storage, r.resolvedDataRoot
+}
+
+// RenderRepresentations renders the _entities representations that would be sent upstream for the entity at objectRef.
+// If objectRef is an array, a representation is rendered for each entity in the array.
+// keyFields are the paths of the key fields relative to the entity, e.g. {"id"} or {"owner", "id"}.
+// If keyFields is empty, all flat fields of the entity are rendered.
+// Each representation includes the __typename of the entity.
+func (r *Resolvable) RenderRepresentations(objectRef int, keyFields [][]string) ([][]byte, error) {
+	if !r.storage.NodeIsDefined(objectRef) {
+		return nil, nil
 	}
-	traceData, err := json.Marshal(trace)
+	entities := []int{objectRef}
+	if r.storage.Nodes[objectRef].Kind == astjson.NodeKindArray {
+		entities = r.storage.Nodes[objectRef].ArrayValues
+	}
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	representations := make([][]byte, 0, len(entities))
+	for i, entity := range entities {
+		buf.Reset()
+		err := r.renderRepresentation(entity, keyFields, buf)
+		if err != nil {
+			return nil, fmt.Errorf("entity at index %d: %w", i, err)
+		}
+		representations = append(representations, append([]byte(nil), buf.Bytes()...))
+	}
+	return representations, nil
+}
+
+func (r *Resolvable) renderRepresentation(entity int, keyFields [][]string, out io.Writer) error {
+	if r.storage.Nodes[entity].Kind != astjson.NodeKindObject {
+		return fmt.Errorf("entity is not an object")
+	}
+	typeName := r.storage.GetObjectField(entity, "__typename")
+	if !r.storage.NodeIsDefined(typeName) {
+		return fmt.Errorf("entity has no __typename")
+	}
+	if len(keyFields) == 0 {
+		return r.storage.PrintObjectFlat(entity, out)
+	}
+	representation, err := r.storage.AppendObject(emptyObject)
 	if err != nil {
 		return err
 	}
-	r.printBytes(quote)
-	r.printBytes(literalTrace)
-	r.printBytes(quote)
-	r.printBytes(colon)
-	r.printBytes(traceData)
-	return nil
+	r.storage.SetObjectField(representation, typeName, "__typename")
+	for _, keyField := range keyFields {
+		value := r.storage.Get(entity, keyField)
+		if !r.storage.NodeIsDefined(value) {
+			return fmt.Errorf("entity has no value for key field '%s'", strings.Join(keyField, "."))
+		}
+		representation = r.storage.MergeNodesWithPath(representation, value, keyField)
+	}
+	return r.storage.PrintNode(r.storage.Nodes[representation], out)
 }
 
-func (r *Resolvable) hasExtensions() bool {
-	if r.ctx.authorizer != nil && r.ctx.authorizer.HasResponseExtensionData(r.ctx) {
-		return true
+// CountDuplicateArrayItems returns the number of items in the array which are identical to a previous item.
+// Items are compared by the hash of their printed subtree, so objects are only identical if their fields have the same order.
+// It returns 0 if arrayRef is not an array.
+func (r *Resolvable) CountDuplicateArrayItems(arrayRef int) int {
+	if !r.storage.NodeIsDefined(arrayRef) || r.storage.Nodes[arrayRef].Kind != astjson.NodeKindArray {
+		return 0
 	}
-	if r.ctx.RateLimitOptions.Enable && r.ctx.RateLimitOptions.IncludeStatsInResponseExtension && r.ctx.rateLimiter != nil {
-		return true
+	items := r.storage.Nodes[arrayRef].ArrayValues
+	if len(items) < 2 {
+		return 0
 	}
-	if r.ctx.TracingOptions.Enable && r.ctx.TracingOptions.IncludeTraceOutputInResponseExtensions {
-		return true
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	seen := make(map[uint64]struct{}, len(items))
+	duplicates := 0
+	for _, item := range items {
+		buf.Reset()
+		if err := r.storage.PrintNode(r.storage.Nodes[item], buf); err != nil {
+			continue
+		}
+		hash := xxhash.Sum64(buf.Bytes())
+		if _, ok := seen[hash]; ok {
+			duplicates++
+			continue
+		}
+		seen[hash] = struct{}{}
 	}
-	return false
+	return duplicates
 }
 
 func (r *Resolvable) WroteErrorsWithoutData() bool {
 	return r.wroteErrors && !r.wroteData
 }
 
+// ErrorsJSON returns the JSON of the current errors array, e.g. the errors of a subgraph response merged by InitSubscription,
+// so callers can inspect the errors without resolving the data. It returns nil if there are no errors.
+func (r *Resolvable) ErrorsJSON() []byte {
+	if !r.hasErrors() {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := r.storage.PrintNode(r.storage.Nodes[r.errorsRoot], buf); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
 func (r *Resolvable) hasErrors() bool {
 	return r.storage.NodeIsDefined(r.errorsRoot) &&
 		len(r.storage.Nodes[r.errorsRoot].ArrayValues) > 0
 }
 
+func (r *Resolvable) hasWarnings() bool {
+	return r.storage.NodeIsDefined(r.warningsRoot) &&
+		len(r.storage.Nodes[r.warningsRoot].ArrayValues) > 0
+}
+
+func (r *Resolvable) hasSoftErrors() bool {
+	return r.storage.NodeIsDefined(r.softErrorsRoot) &&
+		len(r.storage.Nodes[r.softErrorsRoot].ArrayValues) > 0
+}
+
 func (r *Resolvable) hasData() bool {
 	if !r.storage.NodeIsDefined(r.dataRoot) {
 		return false
@@ -306,6 +1080,14 @@ func (r *Resolvable) printNode(ref int) {
 	if r.printErr != nil {
 		return
 	}
+	if len(r.streamedArrayNodes) != 0 {
+		r.printStreamedArrayNode(ref)
+		return
+	}
+	if r.ctx.NodeEncoder != nil {
+		r.printErr = r.ctx.NodeEncoder.EncodeNode(r.storage, ref, r.out)
+		return
+	}
 	r.printErr = r.storage.PrintNode(r.storage.Nodes[ref], r.out)
 }
 
@@ -334,7 +1116,7 @@ func (r *Resolvable) popNodePathElement(path []string) {
 }
 
 func (r *Resolvable) walkNode(node Node, ref int) (nodeRef int, hasError bool) {
-	if r.authorizationError != nil {
+	if r.authorizationError != nil || r.failFast {
 		return astjson.InvalidRef, true
 	}
 	if r.print {
@@ -365,6 +1147,14 @@ func (r *Resolvable) walkNode(node Node, ref int) (nodeRef int, hasError bool) {
 		return r.walkEmptyArray(n)
 	case *CustomNode:
 		return r.walkCustom(n, ref)
+	case *ConnectionInfo:
+		return r.walkConnectionInfo(n, ref)
+	case *FileRef:
+		return r.walkFileRef(n, ref)
+	case *Enum:
+		return r.walkEnum(n, ref)
+	case *StreamedArray:
+		return r.walkStreamedArray(n, ref)
 	default:
 		return astjson.InvalidRef, false
 	}
@@ -372,6 +1162,10 @@ func (r *Resolvable) walkNode(node Node, ref int) (nodeRef int, hasError bool) {
 
 func (r *Resolvable) walkObject(obj *Object, ref int) (nodeRef int, hasError bool) {
 	ref = r.storage.Get(ref, obj.Path)
+	transformedRef, transformed := r.transformedObjects[ref]
+	if transformed {
+		ref = transformedRef
+	}
 	if !r.storage.NodeIsDefined(ref) {
 		if obj.Nullable {
 			return r.walkNull()
@@ -383,22 +1177,64 @@ func (r *Resolvable) walkObject(obj *Object, ref int) (nodeRef int, hasError boo
 	isRoot := r.depth < 2
 	defer r.popNodePathElement(obj.Path)
 
-	if r.storage.Nodes[ref].Kind == astjson.NodeKindNull {
-		return r.walkNull()
+	if r.storage.Nodes[ref].Kind == astjson.NodeKindNull {
+		return r.walkNull()
+	}
+	if r.storage.Nodes[ref].Kind != astjson.NodeKindObject {
+		r.addCoercionError("Object cannot represent non-object value.", obj.Path, obj.Nullable)
+		return astjson.InvalidRef, r.err()
+	}
+	if !r.print && obj.TypeDiscriminator != nil {
+		r.discriminateTypeName(obj, ref)
+	}
+	if !r.print && r.ctx.StrictTypeName {
+		if typeName := r.storage.GetObjectField(ref, "__typename"); r.invalidTypeName(typeName) {
+			r.addCategorizedError(fmt.Sprintf("invalid __typename value: %s", r.storage.Nodes[typeName].ValueBytes(r.storage)), []string{"__typename"}, ErrorCategoryValidation)
+			if obj.Nullable {
+				r.storage.Nodes[ref].Kind = astjson.NodeKindNull
+				return astjson.InvalidRef, false
+			}
+			return astjson.InvalidRef, r.err()
+		}
+	}
+	if !r.print && !transformed && len(r.ctx.TypeTransformers) != 0 {
+		transformedRef, err := r.transformObject(ref)
+		if err != nil {
+			r.addError(err.Error(), nil)
+			if obj.Nullable {
+				r.storage.Nodes[ref].Kind = astjson.NodeKindNull
+				return astjson.InvalidRef, false
+			}
+			return astjson.InvalidRef, r.err()
+		}
+		ref = transformedRef
+	}
+
+	if !r.print && isRoot && r.ctx.RootTypeName != "" {
+		r.setRootTypeName(obj, ref)
 	}
-	if r.storage.Nodes[ref].Kind != astjson.NodeKindObject {
-		r.addError("Object cannot represent non-object value.", obj.Path)
-		return astjson.InvalidRef, r.err()
+	if !r.print && r.ctx.StrictExtraFields {
+		r.addExtraFieldWarnings(obj, ref)
 	}
 
 	objectNodeRef := astjson.InvalidRef
 	if r.print {
 		if !isRoot {
 			r.ctx.Stats.ResolvedObjects++
+			if r.ctx.OnResolveObject != nil {
+				r.ctx.OnResolveObject(r.resolvedObjectTypeName(ref, obj), r.renderPath())
+			}
 		}
 		objectNodeRef, _ = r.storage.AppendObject(emptyObject)
 	}
+	parentFieldInfo := r.fieldInfo
+	defer func() {
+		r.fieldInfo = parentFieldInfo
+	}()
+	walkedFields := 0
 	for i := range obj.Fields {
+		// errors generated for the field use its location, see appendGraphQLError
+		r.fieldInfo = obj.Fields[i].Info
 		if obj.Fields[i].SkipDirectiveDefined {
 			if r.skipField(obj.Fields[i].SkipVariableName) {
 				continue
@@ -409,11 +1245,34 @@ func (r *Resolvable) walkObject(obj *Object, ref int) (nodeRef int, hasError boo
 				continue
 			}
 		}
+		if obj.Fields[i].FeatureFlag != "" {
+			if r.excludeFeatureFlag(obj.Fields[i].FeatureFlag) {
+				continue
+			}
+		}
 		if obj.Fields[i].OnTypeNames != nil {
 			if r.skipFieldOnTypeNames(ref, obj.Fields[i]) {
 				continue
 			}
 		}
+		if r.ctx.MaxObjectFields > 0 && walkedFields == r.ctx.MaxObjectFields {
+			// the warning belongs to the truncated object, not to the omitted field
+			r.fieldInfo = parentFieldInfo
+			message := fmt.Sprintf("Object truncated to %d fields.", r.ctx.MaxObjectFields)
+			if len(r.path) == 0 {
+				r.addPathlessWarning(message)
+			} else {
+				r.addWarning(message, nil)
+			}
+			break
+		}
+		walkedFields++
+		if !r.print && obj.Fields[i].CacheControl != nil {
+			r.addCacheControl(obj.Fields[i].CacheControl)
+		}
+		if !r.print && r.ctx.DetectDuplicateKeys && r.isDuplicateResponseKey(ref, obj, i) {
+			r.addError(fmt.Sprintf("Duplicate response key '%s'.", obj.Fields[i].Name), nil)
+		}
 		if !r.print {
 			skip := r.authorizeField(ref, obj.Fields[i])
 			if skip {
@@ -437,9 +1296,72 @@ func (r *Resolvable) walkObject(obj *Object, ref int) (nodeRef int, hasError boo
 			}
 		}
 
+		if !r.print && len(obj.Fields[i].RequiredVariables) != 0 && r.missingRequiredVariable(obj.Fields[i]) {
+			if obj.Fields[i].Value.NodeNullable() {
+				field := r.storage.Get(ref, obj.Fields[i].Value.NodePath())
+				if r.storage.NodeIsDefined(field) {
+					r.storage.Nodes[field].Kind = astjson.NodeKindNull
+				}
+			} else if obj.Nullable {
+				// like for unauthorized fields, the remaining fields are still walked
+				r.storage.Nodes[ref].Kind = astjson.NodeKindNull
+			} else {
+				return astjson.InvalidRef, true
+			}
+			continue
+		}
+
+		if !r.print && obj.Fields[i].RequirePresence && r.storage.Get(ref, obj.Fields[i].Value.NodePath()) == astjson.InvalidRef {
+			r.addAbsentFieldError(obj.Fields[i].Value.NodePath())
+			if obj.Fields[i].Value.NodeNullable() {
+				// the absent field is printed as null, the remaining fields are still walked
+				continue
+			}
+			if obj.Nullable {
+				r.storage.Nodes[ref].Kind = astjson.NodeKindNull
+			} else {
+				return astjson.InvalidRef, true
+			}
+			continue
+		}
+
+		if !r.print && r.ctx.FieldPresenceCollector != nil && obj.Fields[i].Info != nil {
+			r.collectFieldPresence(ref, obj.Fields[i])
+		}
+
+		if !r.print && r.ctx.TraceFieldSources && obj.Fields[i].Info != nil && len(obj.Fields[i].Info.Source.IDs) != 0 {
+			recordFieldValue(r, &r.fieldSources, obj.Fields[i], obj.Fields[i].Info.Source.IDs)
+		}
+
+		if !r.print && r.ctx.IncludeFieldStatesExtension {
+			r.recordFieldState(ref, obj.Fields[i])
+		}
+
+		if !r.print && obj.Fields[i].Info != nil {
+			r.complexityScore += obj.Fields[i].Info.Complexity
+		}
+
+		if !r.print && r.ctx.IncludeCacheStatus && obj.Fields[i].Info != nil {
+			r.recordCacheStatus(obj.Fields[i])
+		}
+
+		if !r.print && r.ctx.OnDeprecatedFieldUsed != nil && obj.Fields[i].Info != nil && obj.Fields[i].Info.IsDeprecated {
+			if r.storage.Get(ref, obj.Fields[i].Value.NodePath()) != astjson.InvalidRef {
+				r.ctx.OnDeprecatedFieldUsed(GraphCoordinate{
+					TypeName:  r.objectFieldTypeName(ref, obj.Fields[i]),
+					FieldName: obj.Fields[i].Info.Name,
+				})
+			}
+		}
+
+		if !r.print && r.ctx.NonNullFallback != nil && !obj.Fields[i].Value.NodeNullable() {
+			r.applyNonNullFallback(ref, obj.Fields[i])
+		}
+
 		fieldNodeRef, err := r.walkNode(obj.Fields[i].Value, ref)
 		if err {
 			if obj.Nullable {
+				r.nullBubble()
 				// set ref to null so we have early return on next round of walk
 				r.storage.Nodes[ref].Kind = astjson.NodeKindNull
 				if r.print {
@@ -451,6 +1373,25 @@ func (r *Resolvable) walkObject(obj *Object, ref int) (nodeRef int, hasError boo
 		}
 
 		if r.print {
+			if obj.Fields[i].NullPlaceholder != nil && !r.storage.NodeIsDefined(fieldNodeRef) {
+				placeholderRef, placeholderErr := r.storage.AppendAnyJSONBytes(obj.Fields[i].NullPlaceholder)
+				if placeholderErr != nil {
+					// the placeholder is validated when planning, see ValidateNullPlaceholders
+					r.printErr = placeholderErr
+					return r.storage.AppendNull(), false
+				}
+				fieldNodeRef = placeholderRef
+			}
+			if r.ctx.NullMode != NullModeJSONNull && !r.storage.NodeIsDefined(fieldNodeRef) {
+				if r.ctx.NullMode == NullModeOmitKey {
+					continue
+				}
+				fieldNodeRef = r.storage.AppendString("")
+			}
+			if flatten, ok := obj.Fields[i].Value.(*Object); ok && flatten.FlattenInto {
+				r.flattenObjectInto(objectNodeRef, fieldNodeRef)
+				continue
+			}
 			fieldTmpObjectRef, _ := r.storage.AppendObject(emptyObject)
 			r.storage.SetObjectFieldKeyBytes(fieldTmpObjectRef, fieldNodeRef, obj.Fields[i].Name)
 			objectNodeRef = r.storage.MergeNodes(objectNodeRef, fieldTmpObjectRef)
@@ -460,12 +1401,210 @@ func (r *Resolvable) walkObject(obj *Object, ref int) (nodeRef int, hasError boo
 	return objectNodeRef, false
 }
 
+// applyNonNullFallback sets the value supplied by Context.NonNullFallback in the data if the value of the non-nullable field is null or absent
+func (r *Resolvable) applyNonNullFallback(ref int, field *Field) {
+	path := field.Value.NodePath()
+	if len(path) == 0 || r.storage.NodeIsDefined(r.storage.Get(ref, path)) {
+		return
+	}
+	parent := r.storage.Get(ref, path[:len(path)-1])
+	if !r.storage.NodeIsDefined(parent) || r.storage.Nodes[parent].Kind != astjson.NodeKindObject {
+		return
+	}
+	fieldPath := string(field.Name)
+	if len(r.path) != 0 {
+		fieldPath = r.renderPath() + "." + fieldPath
+	}
+	fallback, ok := r.ctx.NonNullFallback(fieldPath, field.Value)
+	if !ok {
+		return
+	}
+	value, err := r.storage.AppendAnyJSONBytes(fallback)
+	if err != nil {
+		return
+	}
+	r.storage.SetObjectField(parent, value, path[len(path)-1])
+}
+
+// discriminateTypeName sets the __typename of the object data to the type computed by Object.TypeDiscriminator
+func (r *Resolvable) discriminateTypeName(obj *Object, ref int) {
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	if err := r.storage.PrintNode(r.storage.Nodes[ref], buf); err != nil {
+		r.printErr = err
+		return
+	}
+	if typeName := obj.TypeDiscriminator(buf.Bytes()); typeName != "" {
+		r.storage.SetObjectField(ref, r.storage.AppendString(typeName), "__typename")
+	}
+}
+
+// setRootTypeName sets Context.RootTypeName as the value of the __typename fields of the root object which are absent from the data
+func (r *Resolvable) setRootTypeName(obj *Object, ref int) {
+	for i := range obj.Fields {
+		typeName, ok := obj.Fields[i].Value.(*String)
+		if !ok || !typeName.IsTypeName || len(typeName.Path) != 1 {
+			continue
+		}
+		if r.storage.NodeIsDefined(r.storage.Get(ref, typeName.Path)) {
+			continue
+		}
+		r.storage.SetObjectField(ref, r.storage.AppendString(r.ctx.RootTypeName), typeName.Path[0])
+	}
+}
+
+// addExtraFieldWarnings adds a warning for each key of the data object which isn't selected by any field of the object
+// Meta fields like __typename are never reported
+func (r *Resolvable) addExtraFieldWarnings(obj *Object, ref int) {
+	for _, fieldRef := range r.storage.Nodes[ref].ObjectFields {
+		key := r.storage.ObjectFieldKey(fieldRef)
+		if bytes.HasPrefix(key, literalIntrospectionPrefix) || objectSelectsKey(obj, key) {
+			continue
+		}
+		r.addWarning(fmt.Sprintf("Unexpected field '%s' in the response of the subgraph.", key), []string{string(key)})
+	}
+}
+
+// objectSelectsKey returns true if any field of the object reads the key from the data object
+// Object values without a path read their fields from the same data object
+func objectSelectsKey(obj *Object, key []byte) bool {
+	for i := range obj.Fields {
+		path := obj.Fields[i].Value.NodePath()
+		if len(path) != 0 {
+			if path[0] == string(key) {
+				return true
+			}
+			continue
+		}
+		if nested, ok := obj.Fields[i].Value.(*Object); ok && objectSelectsKey(nested, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// addCacheControl aggregates the minimum max age and the most restrictive scope of all selected fields
+func (r *Resolvable) addCacheControl(cacheControl *CacheControl) {
+	if !r.hasCacheControl || cacheControl.MaxAge < r.cacheControl.MaxAge {
+		r.cacheControl.MaxAge = cacheControl.MaxAge
+	}
+	if cacheControl.Scope == CacheControlScopePrivate || r.cacheControl.Scope == "" {
+		r.cacheControl.Scope = cacheControl.Scope
+	}
+	r.hasCacheControl = true
+}
+
+// CacheControl returns the effective cache hint of the response after Resolve,
+// which is the minimum max age and the most restrictive scope of all selected fields with a CacheControl hint.
+// It returns false if no selected field has a hint.
+func (r *Resolvable) CacheControl() (CacheControl, bool) {
+	if !r.hasCacheControl {
+		return CacheControl{}, false
+	}
+	cacheControl := r.cacheControl
+	if cacheControl.Scope == "" {
+		cacheControl.Scope = CacheControlScopePublic
+	}
+	return cacheControl, true
+}
+
+// isDuplicateResponseKey returns true if a previously selected field of the object has the same response key as the field at index i
+func (r *Resolvable) isDuplicateResponseKey(ref int, obj *Object, i int) bool {
+	for j := 0; j < i; j++ {
+		if !bytes.Equal(obj.Fields[j].Name, obj.Fields[i].Name) {
+			continue
+		}
+		if obj.Fields[j].SkipDirectiveDefined && r.skipField(obj.Fields[j].SkipVariableName) {
+			continue
+		}
+		if obj.Fields[j].IncludeDirectiveDefined && r.excludeField(obj.Fields[j].IncludeVariableName) {
+			continue
+		}
+		if obj.Fields[j].FeatureFlag != "" && r.excludeFeatureFlag(obj.Fields[j].FeatureFlag) {
+			continue
+		}
+		if obj.Fields[j].OnTypeNames != nil && r.skipFieldOnTypeNames(ref, obj.Fields[j]) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// transformObject applies the transformer of Context.TypeTransformers for the __typename of the object at ref
+// The result is merged into a copy of the object, so the data itself is not modified, e.g. in a shared storage.
+// The copy is kept per object, so the transformer runs once even if the object is walked multiple times, e.g. using aliases.
+// The copy is keyed by ref, see the invariant of transformedObjects.
+func (r *Resolvable) transformObject(ref int) (int, error) {
+	typeName := r.storage.GetObjectField(ref, "__typename")
+	if !r.storage.NodeIsDefined(typeName) || r.storage.Nodes[typeName].Kind != astjson.NodeKindString {
+		return ref, nil
+	}
+	transform, ok := r.ctx.TypeTransformers[unsafebytes.BytesToString(r.storage.Nodes[typeName].ValueBytes(r.storage))]
+	if !ok {
+		return ref, nil
+	}
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	err := r.storage.PrintNode(r.storage.Nodes[ref], buf)
+	if err != nil {
+		return ref, err
+	}
+	transformed, err := transform(buf.Bytes())
+	if err != nil {
+		return ref, err
+	}
+	transformedRef, err := r.storage.AppendObject(transformed)
+	if err != nil {
+		return ref, err
+	}
+	copyRef := r.storage.MergeNodes(r.storage.CopyNode(ref), transformedRef)
+	if r.transformedObjects == nil {
+		r.transformedObjects = make(map[int]int)
+	}
+	r.transformedObjects[ref] = copyRef
+	return copyRef, nil
+}
+
+// ReverseRenameType returns the original type name for a type name renamed using Context.RenameTypeNames,
+// e.g. to map type names echoed back by clients to the type names of the subgraphs.
+// If the type name was not renamed, it is returned as is.
+// The reverse lookup is built on the first call and kept until the next call to Reset.
+func (r *Resolvable) ReverseRenameType(name []byte) []byte {
+	if len(r.renameTypeNames) == 0 {
+		return name
+	}
+	if len(r.reverseTypeNames) == 0 {
+		if r.reverseTypeNames == nil {
+			r.reverseTypeNames = make(map[string][]byte, len(r.renameTypeNames))
+		}
+		for i := range r.renameTypeNames {
+			r.reverseTypeNames[string(r.renameTypeNames[i].To)] = r.renameTypeNames[i].From
+		}
+	}
+	if from, ok := r.reverseTypeNames[string(name)]; ok {
+		return from
+	}
+	return name
+}
+
+// flattenObjectInto lifts the fields of the resolved object into the parent object
+// If the resolved object is null, no fields are lifted
+func (r *Resolvable) flattenObjectInto(parentRef, objectRef int) {
+	if r.storage.Nodes[objectRef].Kind != astjson.NodeKindObject {
+		return
+	}
+	for _, field := range r.storage.Nodes[objectRef].ObjectFields {
+		r.storage.SetObjectFieldKeyBytes(parentRef, r.storage.ObjectFieldValue(field), r.storage.ObjectFieldKey(field))
+	}
+}
+
 func (r *Resolvable) authorizeField(ref int, field *Field) (skipField bool) {
 	if field.Info == nil {
 		return false
 	}
 	if !field.Info.HasAuthorizationRule {
-		return false
+		return r.ctx.DefaultDeny && r.denyFieldByDefault(field)
 	}
 	if r.ctx.authorizer == nil {
 		return false
@@ -474,11 +1613,12 @@ func (r *Resolvable) authorizeField(ref int, field *Field) (skipField bool) {
 		return false
 	}
 	dataSourceID := field.Info.Source.IDs[0]
-	typeName := r.objectFieldTypeName(ref, field)
-	fieldName := unsafebytes.BytesToString(field.Name)
+	if _, trusted := r.ctx.TrustedDataSourceIDs[dataSourceID]; trusted {
+		return false
+	}
 	gc := GraphCoordinate{
-		TypeName:  typeName,
-		FieldName: fieldName,
+		TypeName:  r.objectFieldTypeName(ref, field),
+		FieldName: field.schemaName(),
 	}
 	result, authErr := r.authorize(ref, dataSourceID, gc)
 	if authErr != nil {
@@ -489,9 +1629,78 @@ func (r *Resolvable) authorizeField(ref int, field *Field) (skipField bool) {
 		r.addRejectFieldError(result.Reason, dataSourceID, field)
 		return true
 	}
+	if transformer, ok := r.ctx.authorizer.(AuthorizationTransformer); ok {
+		err := r.transformAuthorizedField(transformer, ref, dataSourceID, gc, field)
+		if err != nil {
+			r.authorizationError = err
+			return true
+		}
+	}
+	if redactor, ok := r.ctx.authorizer.(AuthorizationRedactor); ok {
+		err := r.redactAuthorizedField(redactor, ref, dataSourceID, gc, field)
+		if err != nil {
+			r.authorizationError = err
+			return true
+		}
+	}
 	return false
 }
 
+func (r *Resolvable) redactAuthorizedField(redactor AuthorizationRedactor, ref int, dataSourceID string, coordinate GraphCoordinate, field *Field) error {
+	value := r.storage.Get(ref, field.Value.NodePath())
+	if !r.storage.NodeIsDefined(value) {
+		return nil
+	}
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	err := r.storage.PrintNode(r.storage.Nodes[value], buf)
+	if err != nil {
+		return err
+	}
+	redactPaths, err := redactor.RedactObjectField(r.ctx, dataSourceID, buf.Bytes(), coordinate)
+	if err != nil {
+		return err
+	}
+	for _, path := range redactPaths {
+		redacted := r.storage.Get(value, path)
+		if r.storage.NodeIsDefined(redacted) {
+			r.storage.Nodes[redacted].Kind = astjson.NodeKindNull
+		}
+	}
+	return nil
+}
+
+func (r *Resolvable) transformAuthorizedField(transformer AuthorizationTransformer, ref int, dataSourceID string, coordinate GraphCoordinate, field *Field) error {
+	value := r.storage.Get(ref, field.Value.NodePath())
+	if !r.storage.NodeIsDefined(value) {
+		return nil
+	}
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	err := r.storage.PrintNode(r.storage.Nodes[value], buf)
+	if err != nil {
+		return err
+	}
+	replacement, err := transformer.TransformObjectField(r.ctx, dataSourceID, buf.Bytes(), coordinate)
+	if err != nil {
+		return err
+	}
+	if replacement == nil {
+		return nil
+	}
+	replacementRef, err := r.storage.AppendAnyJSONBytes(replacement)
+	if err != nil {
+		return err
+	}
+	// the replacement node takes the place of the original value, so all references to the value see the replacement
+	r.storage.Nodes[value] = r.storage.Nodes[replacementRef]
+	if r.authorizationBufObjectRef == ref {
+		// the flat render of the object is outdated
+		r.authorizationBufObjectRef = -1
+	}
+	return nil
+}
+
 func (r *Resolvable) authorize(objectRef int, dataSourceID string, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
 	r.xxh.Reset()
 	_, _ = r.xxh.WriteString(dataSourceID)
@@ -499,11 +1708,14 @@ func (r *Resolvable) authorize(objectRef int, dataSourceID string, coordinate Gr
 	_, _ = r.xxh.WriteString(coordinate.FieldName)
 	decisionID := r.xxh.Sum64()
 	if _, ok := r.authorizationAllow[decisionID]; ok {
+		r.authorizationCacheStats.AllowHits++
 		return nil, nil
 	}
 	if reason, ok := r.authorizationDeny[decisionID]; ok {
+		r.authorizationCacheStats.DenyHits++
 		return &AuthorizationDeny{Reason: reason}, nil
 	}
+	r.authorizationCacheStats.Misses++
 	if r.authorizationBufObjectRef != objectRef {
 		if r.authorizationBuf == nil {
 			r.authorizationBuf = bytes.NewBuffer(nil)
@@ -519,14 +1731,81 @@ func (r *Resolvable) authorize(objectRef int, dataSourceID string, coordinate Gr
 	if err != nil {
 		return nil, err
 	}
+	decision := AuthorizationDecision{
+		Coordinate:   coordinate,
+		DataSourceID: dataSourceID,
+	}
 	if result == nil {
 		r.authorizationAllow[decisionID] = struct{}{}
+		decision.Decision = AuthorizationDecisionAllow
 	} else {
 		r.authorizationDeny[decisionID] = result.Reason
+		decision.Decision = AuthorizationDecisionDeny
+		decision.Reason = result.Reason
 	}
+	r.authorizationDecisions = append(r.authorizationDecisions, decision)
 	return result, nil
 }
 
+type AuthorizationDecisionType int
+
+const (
+	AuthorizationDecisionAllow AuthorizationDecisionType = iota
+	AuthorizationDecisionDeny
+)
+
+// AuthorizationDecision is a decision of the Authorizer for a field, see Resolvable.AuthorizationDecisions
+type AuthorizationDecision struct {
+	Coordinate   GraphCoordinate
+	DataSourceID string
+	Decision     AuthorizationDecisionType
+	// Reason is the reason of the Authorizer for denying the field
+	Reason string
+}
+
+// AuthorizationDecisions returns the decisions of the Authorizer in the order the fields were walked, e.g. for audit logs.
+// Decisions served from the decision cache are not repeated, so each coordinate and datasource occurs only once.
+// The returned slice is only valid until the next call to Reset.
+func (r *Resolvable) AuthorizationDecisions() []AuthorizationDecision {
+	return r.authorizationDecisions
+}
+
+// ComplexityScore returns the sum of the FieldInfo.Complexity of all fields resolved since the last call to Reset.
+// Fields of list items are counted for each item, so the score reflects the actual size of the result.
+// Fields of null objects are not counted.
+func (r *Resolvable) ComplexityScore() int {
+	return r.complexityScore
+}
+
+// AuthorizationCacheStats are the hits and misses of the authorization decision cache
+// accumulated since the last call to Reset
+type AuthorizationCacheStats struct {
+	AllowHits int
+	DenyHits  int
+	Misses    int
+}
+
+// AuthorizationCacheStats returns the authorization decision cache stats accumulated during the resolve
+func (r *Resolvable) AuthorizationCacheStats() AuthorizationCacheStats {
+	return r.authorizationCacheStats
+}
+
+// denyFieldByDefault denies a field without authorization rule with Context.DefaultDeny, unless it's exempt
+func (r *Resolvable) denyFieldByDefault(field *Field) (denied bool) {
+	if r.ctx.DefaultDenyExemptMetaFields && strings.HasPrefix(field.schemaName(), "__") {
+		return false
+	}
+	var dataSourceID string
+	if len(field.Info.Source.IDs) != 0 {
+		dataSourceID = field.Info.Source.IDs[0]
+		if _, trusted := r.ctx.TrustedDataSourceIDs[dataSourceID]; trusted {
+			return false
+		}
+	}
+	r.addRejectFieldError("field has no authorization rule", dataSourceID, field)
+	return true
+}
+
 func (r *Resolvable) addRejectFieldError(reason, dataSourceID string, field *Field) {
 	nodePath := field.Value.NodePath()
 	r.pushNodePathElement(nodePath)
@@ -540,8 +1819,18 @@ func (r *Resolvable) addRejectFieldError(reason, dataSourceID string, field *Fie
 	}
 	r.ctx.appendSubgraphError(goerrors.Join(errors.New(errorMessage), NewSubgraphError(dataSourceID, fieldPath, reason, 0)))
 
-	ref := r.storage.AppendErrorWithMessage(errorMessage, r.path)
-	r.storage.Nodes[r.errorsRoot].ArrayValues = append(r.storage.Nodes[r.errorsRoot].ArrayValues, ref)
+	if r.ctx.ConsolidateAuthDenials {
+		r.deniedFields = append(r.deniedFields, r.renderPath())
+		if field.Value.NodeNullable() {
+			// the denial is not rendered as an error, but still reported to the ErrorSink
+			if r.ctx.ErrorSink != nil {
+				r.ctx.ErrorSink(r.newGraphQLError(errorMessage, ErrorCategoryAuthorization))
+			}
+			r.popNodePathElement(nodePath)
+			return
+		}
+	}
+	r.appendGraphQLError(r.errorsRoot, errorMessage, ErrorCategoryAuthorization)
 	r.popNodePathElement(nodePath)
 }
 
@@ -554,6 +1843,54 @@ func (r *Resolvable) objectFieldTypeName(ref int, field *Field) string {
 	return field.Info.ExactParentTypeName
 }
 
+// collectFieldPresence records the presence of the value of the field in Context.FieldPresenceCollector
+func (r *Resolvable) collectFieldPresence(ref int, field *Field) {
+	presence := r.ctx.FieldPresenceCollector.presence(GraphCoordinate{
+		TypeName:  r.objectFieldTypeName(ref, field),
+		FieldName: field.schemaName(),
+	})
+	value := r.storage.Get(ref, field.Value.NodePath())
+	switch {
+	case value == astjson.InvalidRef:
+		presence.Absent++
+	case !r.storage.NodeIsDefined(value):
+		presence.Null++
+	default:
+		presence.Present++
+	}
+}
+
+// resolvedObjectTypeName returns the __typename of the object data or the parent type name of the selected fields
+func (r *Resolvable) resolvedObjectTypeName(ref int, obj *Object) string {
+	typeName := r.storage.GetObjectField(ref, "__typename")
+	if r.storage.NodeIsDefined(typeName) && r.storage.Nodes[typeName].Kind == astjson.NodeKindString {
+		return string(r.storage.Nodes[typeName].ValueBytes(r.storage))
+	}
+	for i := range obj.Fields {
+		if obj.Fields[i].Info != nil && obj.Fields[i].Info.ExactParentTypeName != "" {
+			return obj.Fields[i].Info.ExactParentTypeName
+		}
+	}
+	return ""
+}
+
+// missingRequiredVariable adds an error for the first absent variable of Field.RequiredVariables and returns true if any is absent
+func (r *Resolvable) missingRequiredVariable(field *Field) bool {
+	for _, name := range field.RequiredVariables {
+		if r.variablesRoot != astjson.InvalidRef && r.storage.GetObjectField(r.variablesRoot, name) != astjson.InvalidRef {
+			continue
+		}
+		r.addCategorizedError(fmt.Sprintf("Variable \"$%s\" required by field '%s' was not provided.", name, field.Name), field.Value.NodePath(), ErrorCategoryValidation)
+		return true
+	}
+	return false
+}
+
+// invalidTypeName returns true if the __typename field is present in the data, but its value isn't a string
+func (r *Resolvable) invalidTypeName(typeName int) bool {
+	return typeName != astjson.InvalidRef && r.storage.Nodes[typeName].Kind != astjson.NodeKindString
+}
+
 func (r *Resolvable) skipFieldOnTypeNames(ref int, field *Field) bool {
 	typeName := r.storage.GetObjectField(ref, "__typename")
 	if !r.storage.NodeIsDefined(typeName) {
@@ -595,6 +1932,15 @@ func (r *Resolvable) excludeField(includeVariableName string) bool {
 	return bytes.Equal(value, literalFalse)
 }
 
+// excludeFeatureFlag returns true if the feature flag of a field is disabled, see Context.FeatureFlags
+func (r *Resolvable) excludeFeatureFlag(featureFlag string) bool {
+	enabled, ok := r.ctx.FeatureFlags[featureFlag]
+	if !ok {
+		return !r.ctx.IncludeUnknownFeatureFlags
+	}
+	return !enabled
+}
+
 func (r *Resolvable) walkArray(arr *Array, ref int) (nodeRef int, hasError bool) {
 	ref = r.storage.Get(ref, arr.Path)
 	if !r.storage.NodeIsDefined(ref) {
@@ -607,20 +1953,34 @@ func (r *Resolvable) walkArray(arr *Array, ref int) (nodeRef int, hasError bool)
 	r.pushNodePathElement(arr.Path)
 	defer r.popNodePathElement(arr.Path)
 	if r.storage.Nodes[ref].Kind != astjson.NodeKindArray {
-		r.addError("Array cannot represent non-array value.", arr.Path)
+		r.addCoercionError("Array cannot represent non-array value.", arr.Path, arr.Nullable)
 		return astjson.InvalidRef, r.err()
 	}
+	if _, nested := arr.Item.(*Array); nested && !r.print {
+		expected := listDepth(arr)
+		if depth, ok := r.nestedListDepth(ref, expected); !ok {
+			// the path of the array is already pushed
+			r.addCoercionError(fmt.Sprintf("Expected nested list of depth %d, got depth %d.", expected, depth), nil, arr.Nullable)
+			return astjson.InvalidRef, r.err()
+		}
+	}
+	items := r.arrayItems(ref)
+	if !r.print && len(items) < len(r.storage.Nodes[ref].ArrayValues) {
+		omitted := len(r.storage.Nodes[ref].ArrayValues) - len(items)
+		r.addWarning(fmt.Sprintf("List truncated to %d items, %d items omitted.", r.ctx.MaxArrayItems, omitted), nil)
+	}
 
 	arrayNodeRef := astjson.InvalidRef
 	if r.print {
 		arrayNodeRef, _ = r.storage.AppendArray(emptyArray)
 	}
-	for i, value := range r.storage.Nodes[ref].ArrayValues {
+	for i, value := range items {
 		r.pushArrayPathElement(i)
 		itemNodeRef, err := r.walkNode(arr.Item, value)
 		r.popArrayPathElement()
 		if err {
 			if arr.Nullable {
+				r.nullBubble()
 				// set ref to null so we have early return on next round of walk
 				r.storage.Nodes[ref].Kind = astjson.NodeKindNull
 				if r.print {
@@ -638,10 +1998,94 @@ func (r *Resolvable) walkArray(arr *Array, ref int) (nodeRef int, hasError bool)
 	return arrayNodeRef, false
 }
 
+// nullBubble calls Context.OnNullBubble with the path of the object or array which is set to null due to an error of a child
+// The reason is the message of the last error, which is the error of the child
+func (r *Resolvable) nullBubble() {
+	if r.print || r.ctx.OnNullBubble == nil {
+		return
+	}
+	var reason string
+	if r.hasErrors() {
+		errorRefs := r.storage.Nodes[r.errorsRoot].ArrayValues
+		reason = string(r.errorMessage(errorRefs[len(errorRefs)-1]))
+	}
+	r.ctx.OnNullBubble(r.renderPath(), reason)
+}
+
+// listDepth returns the nesting depth of the list, e.g. 2 for [[Int]]
+func listDepth(arr *Array) int {
+	depth := 1
+	for item, ok := arr.Item.(*Array); ok; item, ok = item.Item.(*Array) {
+		depth++
+	}
+	return depth
+}
+
+// nestedListDepth validates that all non-null values nested in the data at ref have the expected list depth
+// If not, it returns the depth of the first mismatching value, e.g. 1 for [1] if the expected depth is 2
+func (r *Resolvable) nestedListDepth(ref int, expected int) (depth int, ok bool) {
+	if r.storage.Nodes[ref].Kind != astjson.NodeKindArray {
+		return 0, expected == 0
+	}
+	if expected == 0 {
+		return r.listDataDepth(ref), false
+	}
+	for _, item := range r.storage.Nodes[ref].ArrayValues {
+		if !r.storage.NodeIsDefined(item) {
+			continue
+		}
+		if depth, ok := r.nestedListDepth(item, expected-1); !ok {
+			return depth + 1, false
+		}
+	}
+	return expected, true
+}
+
+// listDataDepth returns the list depth of the data at ref, following the first non-null item of each list
+func (r *Resolvable) listDataDepth(ref int) int {
+	if r.storage.Nodes[ref].Kind != astjson.NodeKindArray {
+		return 0
+	}
+	for _, item := range r.storage.Nodes[ref].ArrayValues {
+		if r.storage.NodeIsDefined(item) {
+			return 1 + r.listDataDepth(item)
+		}
+	}
+	return 1
+}
+
+// arrayItems returns the items of the array at ref up to Context.MaxArrayItems
+// Items beyond the limit are neither validated nor printed. The data itself is not truncated,
+// so a shared storage still contains all items for subsequent resolves
+func (r *Resolvable) arrayItems(ref int) []int {
+	items := r.storage.Nodes[ref].ArrayValues
+	if r.ctx.MaxArrayItems > 0 && len(items) > r.ctx.MaxArrayItems {
+		return items[:r.ctx.MaxArrayItems]
+	}
+	return items
+}
+
 func (r *Resolvable) walkNull() (nodeRef int, hasError bool) {
 	if r.print {
-		r.ctx.Stats.ResolvedLeafs++
-		return r.storage.AppendNull(), false
+		r.ctx.Stats.ResolvedLeafs++
+		return r.storage.AppendNull(), false
+	}
+	return astjson.InvalidRef, false
+}
+
+// walkNullOrDefault renders the default value of a nullable leaf if the value is absent in the data
+// Explicit null values are rendered as null
+func (r *Resolvable) walkNullOrDefault(ref int, defaultValue []byte) (nodeRef int, hasError bool) {
+	if ref != astjson.InvalidRef || defaultValue == nil {
+		return r.walkNull()
+	}
+	if r.print {
+		nodeRef, err := r.storage.AppendAnyJSONBytes(defaultValue)
+		if err != nil {
+			r.printErr = err
+			return astjson.InvalidRef, r.err()
+		}
+		return nodeRef, false
 	}
 	return astjson.InvalidRef, false
 }
@@ -653,19 +2097,30 @@ func (r *Resolvable) walkString(s *String, ref int) (nodeRef int, hasError bool)
 	ref = r.storage.Get(ref, s.Path)
 	if !r.storage.NodeIsDefined(ref) {
 		if s.Nullable {
-			return r.walkNull()
+			return r.walkNullOrDefault(ref, s.DefaultValue)
 		}
 		r.addNonNullableFieldError(ref, s.Path)
 		return astjson.InvalidRef, r.err()
 	}
 	if r.storage.Nodes[ref].Kind != astjson.NodeKindString {
 		value := string(r.storage.Nodes[ref].ValueBytes(r.storage))
-		r.addError(fmt.Sprintf("String cannot represent non-string value: \\\"%s\\\"", value), s.Path)
+		r.addCoercionError(fmt.Sprintf("String cannot represent non-string value: \"%s\"", value), s.Path, s.Nullable)
 		return astjson.InvalidRef, r.err()
 	}
+	if s.MaxBytes > 0 && !s.UnescapeResponseJson && len(r.storage.Nodes[ref].ValueBytes(r.storage)) > s.MaxBytes {
+		return r.walkOverlongString(ref, s.Path, s.Nullable, s.MaxBytes, s.MaxBytesMode)
+	}
+	if s.UnescapeResponseJson && (s.UnescapeDepth > 1 || s.StrictUnescape) {
+		return r.walkNestedJSONString(s, ref)
+	}
 	if r.print {
 		if s.IsTypeName {
 			value := r.storage.Nodes[ref].ValueBytes(r.storage)
+			if r.ctx.TypeNameRewriter != nil {
+				if rewritten := r.ctx.TypeNameRewriter(value, r.typeNameCoordinate()); rewritten != nil {
+					return r.storage.AppendStringBytes(rewritten), false
+				}
+			}
 			for i := range r.renameTypeNames {
 				if bytes.Equal(value, r.renameTypeNames[i].From) {
 					return r.storage.AppendStringBytes(r.renameTypeNames[i].To), false
@@ -695,6 +2150,57 @@ func (r *Resolvable) walkString(s *String, ref int) (nodeRef int, hasError bool)
 	return astjson.InvalidRef, false
 }
 
+// typeNameCoordinate returns the coordinate of the __typename field which is walked, see Context.TypeNameRewriter
+func (r *Resolvable) typeNameCoordinate() GraphCoordinate {
+	coordinate := GraphCoordinate{FieldName: "__typename"}
+	if r.fieldInfo != nil {
+		coordinate.TypeName = r.fieldInfo.ExactParentTypeName
+	}
+	return coordinate
+}
+
+func (r *Resolvable) walkEnum(e *Enum, ref int) (nodeRef int, hasError bool) {
+	if r.print {
+		r.ctx.Stats.ResolvedLeafs++
+	}
+	ref = r.storage.Get(ref, e.Path)
+	if !r.storage.NodeIsDefined(ref) {
+		if e.Nullable {
+			return r.walkNull()
+		}
+		r.addNonNullableFieldError(ref, e.Path)
+		return astjson.InvalidRef, r.err()
+	}
+	value := r.storage.Nodes[ref].ValueBytes(r.storage)
+	if r.storage.Nodes[ref].Kind != astjson.NodeKindString {
+		r.addCoercionError(fmt.Sprintf("Enum \"%s\" cannot represent non-string value: %s", e.TypeName, value), e.Path, e.Nullable)
+		return astjson.InvalidRef, r.err()
+	}
+	if len(e.AllowedValues) != 0 && !slices.Contains(e.AllowedValues, unsafebytes.BytesToString(value)) {
+		switch e.UnknownValueStrategy {
+		case EnumUnknownValueNull:
+			if e.Nullable {
+				return r.walkNull()
+			}
+			r.addNonNullableFieldError(ref, e.Path)
+			return astjson.InvalidRef, r.err()
+		case EnumUnknownValueDefault:
+			if r.print {
+				return r.storage.AppendString(e.DefaultValue), false
+			}
+			return astjson.InvalidRef, false
+		default:
+			r.addCoercionError(fmt.Sprintf("Enum \"%s\" cannot represent value: \"%s\"", e.TypeName, value), e.Path, e.Nullable)
+			return astjson.InvalidRef, r.err()
+		}
+	}
+	if r.print {
+		nodeRef, _ = r.storage.ImportPrimitiveNode(r.storage, ref)
+		return nodeRef, false
+	}
+	return astjson.InvalidRef, false
+}
+
 func (r *Resolvable) walkBoolean(b *Boolean, ref int) (nodeRef int, hasError bool) {
 	if r.print {
 		r.ctx.Stats.ResolvedLeafs++
@@ -702,17 +2208,23 @@ func (r *Resolvable) walkBoolean(b *Boolean, ref int) (nodeRef int, hasError boo
 	ref = r.storage.Get(ref, b.Path)
 	if !r.storage.NodeIsDefined(ref) {
 		if b.Nullable {
-			return r.walkNull()
+			return r.walkNullOrDefault(ref, b.DefaultValue)
 		}
 		r.addNonNullableFieldError(ref, b.Path)
 		return astjson.InvalidRef, r.err()
 	}
 	if r.storage.Nodes[ref].Kind != astjson.NodeKindBoolean {
 		value := string(r.storage.Nodes[ref].ValueBytes(r.storage))
-		r.addError(fmt.Sprintf("Bool cannot represent non-boolean value: \\\"%s\\\"", value), b.Path)
+		r.addCoercionError(fmt.Sprintf("Bool cannot represent non-boolean value: \"%s\"", value), b.Path, b.Nullable)
 		return astjson.InvalidRef, r.err()
 	}
 	if r.print {
+		if r.ctx.BooleanAsInt {
+			if bytes.Equal(r.storage.Nodes[ref].ValueBytes(r.storage), literalTrue) {
+				return r.storage.AppendInt(1), false
+			}
+			return r.storage.AppendInt(0), false
+		}
 		nodeRef, _ = r.storage.ImportPrimitiveNode(r.storage, ref)
 		return nodeRef, false
 	}
@@ -726,17 +2238,20 @@ func (r *Resolvable) walkInteger(i *Integer, ref int) (nodeRef int, hasError boo
 	ref = r.storage.Get(ref, i.Path)
 	if !r.storage.NodeIsDefined(ref) {
 		if i.Nullable {
-			return r.walkNull()
+			return r.walkNullOrDefault(ref, i.DefaultValue)
 		}
 		r.addNonNullableFieldError(ref, i.Path)
 		return astjson.InvalidRef, r.err()
 	}
 	if r.storage.Nodes[ref].Kind != astjson.NodeKindNumber {
 		value := string(r.storage.Nodes[ref].ValueBytes(r.storage))
-		r.addError(fmt.Sprintf("Int cannot represent non-integer value: \\\"%s\\\"", value), i.Path)
+		r.addCoercionError(fmt.Sprintf("Int cannot represent non-integer value: \"%s\"", value), i.Path, i.Nullable)
 		return astjson.InvalidRef, r.err()
 	}
 	if r.print {
+		if r.ctx.LargeIntAsString && r.isLargeInt(ref) {
+			return r.storage.AppendStringBytes(r.storage.Nodes[ref].ValueBytes(r.storage)), false
+		}
 		nodeRef, _ = r.storage.ImportPrimitiveNode(r.storage, ref)
 		return nodeRef, false
 	}
@@ -750,16 +2265,32 @@ func (r *Resolvable) walkFloat(f *Float, ref int) (nodeRef int, hasError bool) {
 	ref = r.storage.Get(ref, f.Path)
 	if !r.storage.NodeIsDefined(ref) {
 		if f.Nullable {
-			return r.walkNull()
+			return r.walkNullOrDefault(ref, f.DefaultValue)
 		}
 		r.addNonNullableFieldError(ref, f.Path)
 		return astjson.InvalidRef, r.err()
 	}
+	if r.ctx.NonFiniteFloatMode != NonFiniteFloatModeDefault && !r.print && r.isNonFiniteFloat(ref) {
+		if r.ctx.NonFiniteFloatMode == NonFiniteFloatModeNull {
+			if f.Nullable {
+				r.storage.Nodes[ref].Kind = astjson.NodeKindNull
+				return astjson.InvalidRef, false
+			}
+			r.addNonNullableFieldError(ref, f.Path)
+			return astjson.InvalidRef, r.err()
+		}
+		value := string(r.storage.Nodes[ref].ValueBytes(r.storage))
+		r.addCoercionError(fmt.Sprintf("Float cannot represent non-finite value: \"%s\"", value), f.Path, f.Nullable)
+		return astjson.InvalidRef, r.err()
+	}
 	if r.storage.Nodes[ref].Kind != astjson.NodeKindNumber {
 		value := string(r.storage.Nodes[ref].ValueBytes(r.storage))
-		r.addError(fmt.Sprintf("Float cannot represent non-float value: \\\"%s\\\"", value), f.Path)
+		r.addCoercionError(fmt.Sprintf("Float cannot represent non-float value: \"%s\"", value), f.Path, f.Nullable)
 		return astjson.InvalidRef, r.err()
 	}
+	if r.print && r.ctx.FloatFormatter != nil {
+		return r.walkFormattedFloat(ref)
+	}
 	if r.print {
 		nodeRef, _ = r.storage.ImportPrimitiveNode(r.storage, ref)
 		return nodeRef, false
@@ -767,6 +2298,36 @@ func (r *Resolvable) walkFloat(f *Float, ref int) (nodeRef int, hasError bool) {
 	return astjson.InvalidRef, false
 }
 
+// walkFormattedFloat appends the number returned by Context.FloatFormatter for the float at ref
+func (r *Resolvable) walkFormattedFloat(ref int) (nodeRef int, hasError bool) {
+	formatted := r.ctx.FloatFormatter(r.storage.Nodes[ref].ValueBytes(r.storage))
+	nodeRef, err := r.storage.AppendAnyJSONBytes(formatted)
+	if err == nil && r.storage.Nodes[nodeRef].Kind != astjson.NodeKindNumber {
+		err = fmt.Errorf("float formatter returned an invalid number: %s", formatted)
+	}
+	if err != nil {
+		r.printErr = err
+		return r.storage.AppendNull(), false
+	}
+	return nodeRef, false
+}
+
+// isNonFiniteFloat returns true for NaN and Infinity tokens encoded as strings and for numbers overflowing float64
+func (r *Resolvable) isNonFiniteFloat(ref int) bool {
+	value := r.storage.Nodes[ref].ValueBytes(r.storage)
+	switch r.storage.Nodes[ref].Kind {
+	case astjson.NodeKindString:
+		switch string(value) {
+		case "NaN", "Infinity", "+Infinity", "-Infinity", "Inf", "+Inf", "-Inf":
+			return true
+		}
+	case astjson.NodeKindNumber:
+		f, _ := strconv.ParseFloat(unsafebytes.BytesToString(value), 64)
+		return math.IsInf(f, 0) || math.IsNaN(f)
+	}
+	return false
+}
+
 func (r *Resolvable) walkBigInt(b *BigInt, ref int) (nodeRef int, hasError bool) {
 	if r.print {
 		r.ctx.Stats.ResolvedLeafs++
@@ -774,18 +2335,41 @@ func (r *Resolvable) walkBigInt(b *BigInt, ref int) (nodeRef int, hasError bool)
 	ref = r.storage.Get(ref, b.Path)
 	if !r.storage.NodeIsDefined(ref) {
 		if b.Nullable {
-			return r.walkNull()
+			return r.walkNullOrDefault(ref, b.DefaultValue)
 		}
 		r.addNonNullableFieldError(ref, b.Path)
 		return astjson.InvalidRef, r.err()
 	}
 	if r.print {
+		if r.ctx.LargeIntAsString && r.isLargeInt(ref) {
+			return r.storage.AppendStringBytes(r.storage.Nodes[ref].ValueBytes(r.storage)), false
+		}
 		nodeRef, _ = r.storage.ImportPrimitiveNode(r.storage, ref)
 		return nodeRef, false
 	}
 	return astjson.InvalidRef, false
 }
 
+// maxSafeInteger is the largest integer which can be represented exactly by a JavaScript number
+const maxSafeInteger = 1<<53 - 1
+
+// isLargeInt returns true if the node is an integer number with an absolute value above Context.LargeIntThreshold
+func (r *Resolvable) isLargeInt(ref int) bool {
+	if r.storage.Nodes[ref].Kind != astjson.NodeKindNumber {
+		return false
+	}
+	threshold := r.ctx.LargeIntThreshold
+	if threshold <= 0 {
+		threshold = maxSafeInteger
+	}
+	value, err := strconv.ParseInt(unsafebytes.BytesToString(r.storage.Nodes[ref].ValueBytes(r.storage)), 10, 64)
+	if err != nil {
+		// integers which don't fit into int64 are always large, other numbers like 1.5 are no integers
+		return goerrors.Is(err, strconv.ErrRange)
+	}
+	return value > threshold || value < -threshold
+}
+
 func (r *Resolvable) walkScalar(s *Scalar, ref int) (nodeRef int, hasError bool) {
 	if r.print {
 		r.ctx.Stats.ResolvedLeafs++
@@ -793,36 +2377,46 @@ func (r *Resolvable) walkScalar(s *Scalar, ref int) (nodeRef int, hasError bool)
 	ref = r.storage.Get(ref, s.Path)
 	if !r.storage.NodeIsDefined(ref) {
 		if s.Nullable {
-			return r.walkNull()
+			return r.walkNullOrDefault(ref, s.DefaultValue)
 		}
 		r.addNonNullableFieldError(ref, s.Path)
 		return astjson.InvalidRef, r.err()
 	}
+	if !r.print && s.Validator != nil {
+		if err := r.validateScalar(s, ref); err != nil {
+			r.addCategorizedError(err.Error(), s.Path, ErrorCategoryValidation)
+			if s.Nullable {
+				// invalid nullable values are set to null, so that e.g. the other items of a list are still resolved
+				r.storage.Nodes[ref].Kind = astjson.NodeKindNull
+				return astjson.InvalidRef, false
+			}
+			return astjson.InvalidRef, r.err()
+		}
+	}
+	if s.MaxBytes > 0 && r.storage.Nodes[ref].Kind == astjson.NodeKindString && len(r.storage.Nodes[ref].ValueBytes(r.storage)) > s.MaxBytes {
+		return r.walkOverlongString(ref, s.Path, s.Nullable, s.MaxBytes, s.MaxBytesMode)
+	}
 	if r.print {
 		if r.storage.NodeIsPrimitive(ref) {
 			nodeRef, _ = r.storage.ImportPrimitiveNode(r.storage, ref)
 			return nodeRef, false
 		}
-
-		buf := pool.BytesBuffer.Get()
-		defer pool.BytesBuffer.Put(buf)
-
-		err := r.storage.PrintNode(r.storage.Nodes[ref], buf)
-		if err != nil {
-			r.printErr = err
-			return astjson.InvalidRef, r.err()
-		}
-		nodeRef, err := r.storage.AppendAnyJSONBytes(buf.Bytes())
-		if err != nil {
-			r.printErr = err
-			return astjson.InvalidRef, r.err()
-		}
-
-		return nodeRef, false
+		// objects and lists are already parsed, so they are copied without printing and parsing them again
+		return r.storage.CopyNode(ref), false
 	}
 	return astjson.InvalidRef, false
 }
 
+func (r *Resolvable) validateScalar(s *Scalar, ref int) error {
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	err := r.storage.PrintNode(r.storage.Nodes[ref], buf)
+	if err != nil {
+		return err
+	}
+	return s.Validator.ValidateScalar(r.ctx, buf.Bytes())
+}
+
 func (r *Resolvable) walkEmptyObject(_ *EmptyObject) (nodeRef int, hasError bool) {
 	if r.print {
 		nodeRef, _ = r.storage.AppendObject(emptyObject)
@@ -839,68 +2433,320 @@ func (r *Resolvable) walkEmptyArray(_ *EmptyArray) (nodeRef int, hasError bool)
 	return astjson.InvalidRef, false
 }
 
-func (r *Resolvable) walkCustom(c *CustomNode, ref int) (nodeRef int, hasError bool) {
-	if r.print {
-		r.ctx.Stats.ResolvedLeafs++
-	}
-	ref = r.storage.Get(ref, c.Path)
-	if !r.storage.NodeIsDefined(ref) {
-		if c.Nullable {
-			return r.walkNull()
-		}
-		r.addNonNullableFieldError(ref, c.Path)
-		return astjson.InvalidRef, r.err()
-	}
-	value := r.storage.Nodes[ref].ValueBytes(r.storage)
-	resolved, err := c.Resolve(r.ctx, value)
-	if err != nil {
-		r.addError(err.Error(), c.Path)
-		return astjson.InvalidRef, r.err()
-	}
-	if r.print {
-		nodeRef, err = r.storage.AppendAnyJSONBytes(resolved)
-		if err != nil {
-			r.addError(err.Error(), c.Path)
-			return astjson.InvalidRef, r.err()
-		}
-		return nodeRef, false
-	}
-	return astjson.InvalidRef, false
-}
-
 func (r *Resolvable) addNonNullableFieldError(fieldRef int, fieldPath []string) {
 	if fieldRef != -1 && r.storage.Nodes[fieldRef].Kind == astjson.NodeKindNullSkipError {
 		return
 	}
 	r.pushNodePathElement(fieldPath)
-	ref := r.storage.AppendNonNullableFieldIsNullErr(r.renderFieldPath(), r.path)
-	r.storage.Nodes[r.errorsRoot].ArrayValues = append(r.storage.Nodes[r.errorsRoot].ArrayValues, ref)
+	r.appendGraphQLError(r.errorsRoot, fmt.Sprintf("Cannot return null for non-nullable field '%s'.", r.renderFieldPath()), ErrorCategoryInternal)
+	r.popNodePathElement(fieldPath)
+}
+
+func (r *Resolvable) addAbsentFieldError(fieldPath []string) {
+	r.pushNodePathElement(fieldPath)
+	message := fmt.Sprintf("Field '%s' must be present in the data.", r.renderFieldPath())
 	r.popNodePathElement(fieldPath)
+	r.addCategorizedError(message, fieldPath, ErrorCategoryValidation)
+}
+
+// renderPath renders the current path including array indices, e.g. user.friends.0
+func (r *Resolvable) renderPath() string {
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	for i := range r.path {
+		if i != 0 {
+			_, _ = buf.WriteString(".")
+		}
+		if r.path[i].Name != "" {
+			_, _ = buf.WriteString(r.path[i].Name)
+		} else {
+			_, _ = buf.WriteString(strconv.Itoa(r.path[i].ArrayIndex))
+		}
+	}
+	return buf.String()
 }
 
 func (r *Resolvable) renderFieldPath() string {
 	buf := pool.BytesBuffer.Get()
 	defer pool.BytesBuffer.Put(buf)
-	switch r.operationType {
-	case ast.OperationTypeQuery:
+	switch {
+	case r.ctx.IncludeOperationNameInErrorPath && r.ctx.Request.OperationName != "":
+		_, _ = buf.WriteString(r.ctx.Request.OperationName)
+	case r.operationType == ast.OperationTypeQuery:
 		_, _ = buf.WriteString("Query")
-	case ast.OperationTypeMutation:
+	case r.operationType == ast.OperationTypeMutation:
 		_, _ = buf.WriteString("Mutation")
-	case ast.OperationTypeSubscription:
+	case r.operationType == ast.OperationTypeSubscription:
 		_, _ = buf.WriteString("Subscription")
 	}
+	separator := "."
+	if r.ctx.ErrorPathSeparator != "" {
+		separator = r.ctx.ErrorPathSeparator
+	}
 	for i := range r.path {
 		if r.path[i].Name != "" {
-			_, _ = buf.WriteString(".")
+			_, _ = buf.WriteString(separator)
 			_, _ = buf.WriteString(r.path[i].Name)
 		}
 	}
 	return buf.String()
 }
 
+// AppendInputError appends an error for the given path to the errors of the response.
+// The error has the same shape as the errors generated while walking the response,
+// so callers validating inputs (e.g. @oneOf input objects) can reuse the error formatting.
+// It must be called after Init and before Resolve.
+func (r *Resolvable) AppendInputError(path []string, message string) {
+	r.addCategorizedError(message, path, ErrorCategoryValidation)
+}
+
+// AppendWarning appends a warning for the given path to the response.
+// Warnings have the same shape as errors, but are rendered under extensions.warnings.
+// Unlike errors, warnings never null out any data.
+// It must be called after Init.
+func (r *Resolvable) AppendWarning(path []string, message string) {
+	r.addWarning(message, path)
+}
+
+// addWarning appends a warning while walking the response
+// The print walk visits the same nodes again, so warnings are only added during the first walk
+func (r *Resolvable) addWarning(message string, fieldPath []string) {
+	if r.print {
+		return
+	}
+	r.pushNodePathElement(fieldPath)
+	r.appendGraphQLError(r.warningsRoot, message, "")
+	r.popNodePathElement(fieldPath)
+}
+
+// addPathlessWarning adds a warning which doesn't belong to a field, e.g. for the root object
+func (r *Resolvable) addPathlessWarning(message string) {
+	if r.print {
+		return
+	}
+	encoded, err := r.encodeGraphQLError(&pathlessGraphQLError{Message: message})
+	if err != nil {
+		r.printErr = err
+		return
+	}
+	ref, err := r.storage.AppendObject(encoded)
+	if err != nil {
+		r.printErr = err
+		return
+	}
+	r.storage.Nodes[r.warningsRoot].ArrayValues = append(r.storage.Nodes[r.warningsRoot].ArrayValues, ref)
+}
+
+// addCoercionError adds an error for a value that doesn't match the type of the field
+// With Context.SoftErrorExtension, errors on nullable fields are soft errors and rendered under extensions.softErrors
+func (r *Resolvable) addCoercionError(message string, fieldPath []string, nullable bool) {
+	if !r.ctx.SoftErrorExtension || !nullable {
+		errorCount := len(r.storage.Nodes[r.errorsRoot].ArrayValues)
+		r.addCategorizedError(message, fieldPath, ErrorCategoryValidation)
+		if nullable && r.ctx.SuppressSoftErrorsTopLevel {
+			r.softErrors += len(r.storage.Nodes[r.errorsRoot].ArrayValues) - errorCount
+		}
+		return
+	}
+	r.pushNodePathElement(fieldPath)
+	r.appendGraphQLError(r.softErrorsRoot, message, ErrorCategoryValidation)
+	r.popNodePathElement(fieldPath)
+}
+
+// appendGraphQLError appends an error with the message and the current path to the array at arrayRef
+// All errors generated while walking are serialized using the same encoder, so they have the same shape as GraphQLError
+// With Context.IncludeErrorCategory, a non-empty category is rendered as extensions.category
+func (r *Resolvable) appendGraphQLError(arrayRef int, message, category string) {
+	if r.ctx.UniqueErrorPaths && arrayRef != r.warningsRoot && r.duplicateErrorPath(arrayRef) {
+		return
+	}
+	if arrayRef == r.errorsRoot && r.ctx.FailFast {
+		// abort the walk, see walkNode
+		r.failFast = true
+	}
+	graphQLError := r.newGraphQLError(message, category)
+	fullError := graphQLError
+	if r.ctx.MaskInternalErrors && (category == ErrorCategoryValidation || category == ErrorCategoryInternal) {
+		if graphQLError.Extensions == nil {
+			graphQLError.Extensions = map[string]any{}
+		}
+		graphQLError.Extensions["referenceId"] = uuid.NewString()
+		fullError = graphQLError
+		graphQLError.Message = maskedErrorMessage
+	}
+	encoded, err := r.encodeGraphQLError(&graphQLError)
+	if err != nil {
+		r.printErr = err
+		return
+	}
+	ref, err := r.storage.AppendObject(encoded)
+	if err != nil {
+		r.printErr = err
+		return
+	}
+	r.storage.Nodes[arrayRef].ArrayValues = append(r.storage.Nodes[arrayRef].ArrayValues, ref)
+	if r.ctx.ErrorSink != nil && arrayRef != r.warningsRoot {
+		r.ctx.ErrorSink(fullError)
+	}
+}
+
+// newGraphQLError creates an error with the message and the current path and location
+func (r *Resolvable) newGraphQLError(message, category string) GraphQLError {
+	graphQLError := GraphQLError{
+		Message: message,
+		Path:    make([]any, 0, len(r.path)),
+	}
+	for i := range r.path {
+		if r.path[i].Name != "" {
+			graphQLError.Path = append(graphQLError.Path, r.path[i].Name)
+		} else if r.ctx.OneBasedArrayIndices {
+			graphQLError.Path = append(graphQLError.Path, r.path[i].ArrayIndex+1)
+		} else {
+			graphQLError.Path = append(graphQLError.Path, r.path[i].ArrayIndex)
+		}
+	}
+	if r.fieldInfo != nil && r.fieldInfo.Line != 0 {
+		graphQLError.Locations = []Location{{Line: r.fieldInfo.Line, Column: r.fieldInfo.Column}}
+	}
+	if r.ctx.IncludeErrorCategory && category != "" {
+		graphQLError.Extensions = map[string]any{"category": category}
+	}
+	return graphQLError
+}
+
+// errorPathKey is the path of an error within the errors or soft errors of the response, see Context.UniqueErrorPaths
+type errorPathKey struct {
+	arrayRef int
+	path     string
+}
+
+// duplicateErrorPath returns true if an error was already added to the array at arrayRef for the current path, see Context.UniqueErrorPaths
+func (r *Resolvable) duplicateErrorPath(arrayRef int) bool {
+	if r.errorPaths == nil {
+		r.errorPaths = make(map[errorPathKey]struct{})
+	}
+	key := errorPathKey{arrayRef: arrayRef, path: r.renderPath()}
+	if _, ok := r.errorPaths[key]; ok {
+		return true
+	}
+	r.errorPaths[key] = struct{}{}
+	return false
+}
+
+// pathlessGraphQLError is a GraphQLError without a path, e.g. for request errors which don't belong to a field
+// The path of GraphQLError is always rendered, as an empty path is valid for errors generated while resolving.
+type pathlessGraphQLError struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// encodeGraphQLError returns the JSON encoding of the error, which is valid until the next call
+func (r *Resolvable) encodeGraphQLError(graphQLError any) ([]byte, error) {
+	if r.errorEncoder == nil {
+		r.errorBuf = &bytes.Buffer{}
+		r.errorEncoder = json.NewEncoder(r.errorBuf)
+		r.errorEncoder.SetEscapeHTML(false)
+	}
+	r.errorBuf.Reset()
+	if err := r.errorEncoder.Encode(graphQLError); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(r.errorBuf.Bytes(), newLine), nil
+}
+
+// ResolveErrors prints a response with the errors and without walking any data, e.g. for errors before the execution of the operation.
+// The errors are serialized like the errors generated while resolving, errors without a path are printed without the path.
+// With Context.NullDataOnErrors, "data":null is printed.
+// It must be called after Init, e.g. with nil data, so that the extensions of the request are printed.
+func (r *Resolvable) ResolveErrors(graphQLErrors []GraphQLError, out io.Writer) error {
+	r.outCounter = countingWriter{out: out}
+	r.out = &r.outCounter
+	r.printErr = nil
+	r.printBytes(lBrace)
+	r.printBytes(quote)
+	r.printBytes(literalErrors)
+	r.printBytes(quote)
+	r.printBytes(colon)
+	r.printBytes(lBrack)
+	for i := range graphQLErrors {
+		if i != 0 {
+			r.printBytes(comma)
+		}
+		var (
+			encoded []byte
+			err     error
+		)
+		if graphQLErrors[i].Path == nil {
+			encoded, err = r.encodeGraphQLError(&pathlessGraphQLError{
+				Message:    graphQLErrors[i].Message,
+				Locations:  graphQLErrors[i].Locations,
+				Extensions: graphQLErrors[i].Extensions,
+			})
+		} else {
+			encoded, err = r.encodeGraphQLError(&graphQLErrors[i])
+		}
+		if err != nil {
+			return err
+		}
+		r.printBytes(encoded)
+	}
+	r.printBytes(rBrack)
+	r.wroteErrors = true
+	if r.ctx.NullDataOnErrors {
+		r.printBytes(comma)
+		r.printBytes(quote)
+		r.printBytes(literalData)
+		r.printBytes(quote)
+		r.printBytes(colon)
+		r.printBytes(null)
+	}
+	// no fetches are executed, so the trace is omitted
+	if r.hasExtensions(nil) {
+		r.printBytes(comma)
+		if err := r.printExtensions(r.ctx.Context(), nil); err != nil {
+			return err
+		}
+	}
+	r.printBytes(rBrace)
+	return r.printErr
+}
+
+// Errors returns the errors of the response, including errors returned by subgraphs.
+// It can be called after Resolve to inspect the errors programmatically.
+// Array indices of paths are returned as int. Errors not matching the shape of GraphQLError are omitted.
+func (r *Resolvable) Errors() []GraphQLError {
+	if !r.hasErrors() {
+		return nil
+	}
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	graphQLErrors := make([]GraphQLError, 0, len(r.storage.Nodes[r.errorsRoot].ArrayValues))
+	for _, ref := range r.storage.Nodes[r.errorsRoot].ArrayValues {
+		buf.Reset()
+		if err := r.storage.PrintNode(r.storage.Nodes[ref], buf); err != nil {
+			continue
+		}
+		var graphQLError GraphQLError
+		if err := json.Unmarshal(buf.Bytes(), &graphQLError); err != nil {
+			continue
+		}
+		for i := range graphQLError.Path {
+			if index, ok := graphQLError.Path[i].(float64); ok {
+				graphQLError.Path[i] = int(index)
+			}
+		}
+		graphQLErrors = append(graphQLErrors, graphQLError)
+	}
+	return graphQLErrors
+}
+
 func (r *Resolvable) addError(message string, fieldPath []string) {
+	r.addCategorizedError(message, fieldPath, ErrorCategoryInternal)
+}
+
+func (r *Resolvable) addCategorizedError(message string, fieldPath []string, category string) {
 	r.pushNodePathElement(fieldPath)
-	ref := r.storage.AppendErrorWithMessage(message, r.path)
-	r.storage.Nodes[r.errorsRoot].ArrayValues = append(r.storage.Nodes[r.errorsRoot].ArrayValues, ref)
+	r.appendGraphQLError(r.errorsRoot, message, category)
 	r.popNodePathElement(fieldPath)
 }
@@ -0,0 +1,62 @@
+package resolve
+
+import (
+	"encoding/json"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/pool"
+)
+
+// walkConnectionInfo computes the pageInfo from the edges of the connection object at ref
+// The pageInfo is computed once during the first walk and reused by the print walk
+func (r *Resolvable) walkConnectionInfo(c *ConnectionInfo, ref int) (nodeRef int, hasError bool) {
+	if r.print {
+		pageInfoRef, ok := r.pageInfos[ref]
+		if !ok {
+			// the pageInfo can't be computed for a nullable ConnectionInfo
+			return r.walkNull()
+		}
+		r.ctx.Stats.ResolvedLeafs++
+		return pageInfoRef, false
+	}
+	var edges [][]byte
+	edgesRef := r.storage.Get(ref, c.EdgesPath)
+	if r.storage.NodeIsDefined(edgesRef) && r.storage.Nodes[edgesRef].Kind == astjson.NodeKindArray {
+		buf := pool.BytesBuffer.Get()
+		defer pool.BytesBuffer.Put(buf)
+		// compute the pageInfo from the printed edges only, e.g. with Context.MaxArrayItems
+		items := r.arrayItems(edgesRef)
+		edges = make([][]byte, 0, len(items))
+		for _, edge := range items {
+			buf.Reset()
+			if err := r.storage.PrintNode(r.storage.Nodes[edge], buf); err != nil {
+				r.addError(err.Error(), nil)
+				return astjson.InvalidRef, r.err()
+			}
+			edges = append(edges, append([]byte(nil), buf.Bytes()...))
+		}
+	}
+	pageInfo, err := c.ComputePageInfo(r.ctx, edges)
+	if err != nil {
+		r.addCategorizedError(err.Error(), nil, ErrorCategoryResolver)
+		if c.Nullable {
+			return r.walkNull()
+		}
+		return astjson.InvalidRef, r.err()
+	}
+	data, err := json.Marshal(pageInfo)
+	if err != nil {
+		r.addError(err.Error(), nil)
+		return astjson.InvalidRef, r.err()
+	}
+	pageInfoRef, err := r.storage.AppendObject(data)
+	if err != nil {
+		r.addError(err.Error(), nil)
+		return astjson.InvalidRef, r.err()
+	}
+	if r.pageInfos == nil {
+		r.pageInfos = make(map[int]int)
+	}
+	r.pageInfos[ref] = pageInfoRef
+	return astjson.InvalidRef, false
+}
@@ -0,0 +1,64 @@
+package resolve
+
+import (
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/internal/unsafebytes"
+)
+
+func (r *Resolvable) walkCustom(c *CustomNode, ref int) (nodeRef int, hasError bool) {
+	if r.print {
+		r.ctx.Stats.ResolvedLeafs++
+	}
+	ref = r.storage.Get(ref, c.Path)
+	if !r.storage.NodeIsDefined(ref) {
+		if c.Nullable {
+			return r.walkNull()
+		}
+		r.addNonNullableFieldError(ref, c.Path)
+		return astjson.InvalidRef, r.err()
+	}
+	if !r.print && r.ctx.LazyCustomNodes {
+		return astjson.InvalidRef, false
+	}
+	value := r.storage.Nodes[ref].ValueBytes(r.storage)
+	resolved, err := r.resolveCustom(c, value)
+	if err != nil {
+		if r.ctx.LazyCustomNodes {
+			// the errors are already printed, so the error aborts printing the response
+			// and the partially written response must be discarded, see Context.LazyCustomNodes
+			r.printErr = err
+			return r.storage.AppendNull(), false
+		}
+		r.addError(err.Error(), c.Path)
+		return astjson.InvalidRef, r.err()
+	}
+	if r.print {
+		nodeRef, err = r.storage.AppendAnyJSONBytes(resolved)
+		if err != nil {
+			r.addError(err.Error(), c.Path)
+			return astjson.InvalidRef, r.err()
+		}
+		return nodeRef, false
+	}
+	return astjson.InvalidRef, false
+}
+
+// resolveCustom resolves the value of the CustomNode, memoizing the results of CustomNodes with an ID until Reset
+func (r *Resolvable) resolveCustom(c *CustomNode, value []byte) ([]byte, error) {
+	if c.ID == "" {
+		return c.Resolve(r.ctx, value)
+	}
+	key := c.ID + "\x00" + unsafebytes.BytesToString(value)
+	if resolved, ok := r.customResults[key]; ok {
+		return resolved, nil
+	}
+	resolved, err := c.Resolve(r.ctx, value)
+	if err != nil {
+		return nil, err
+	}
+	if r.customResults == nil {
+		r.customResults = make(map[string][]byte)
+	}
+	r.customResults[key] = resolved
+	return resolved, nil
+}
@@ -0,0 +1,327 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+)
+
+// responseExtensionWriter is an entry of the extensions object of the response
+type responseExtensionWriter struct {
+	key []byte
+	// enabled returns true if the entry is printed, fetchTree is nil for responses which are not resolved from fetches
+	enabled func(r *Resolvable, fetchTree *Object) bool
+	// print writes the value of the entry
+	print func(r *Resolvable, ctx context.Context, fetchTree *Object) error
+}
+
+// responseExtensionWriters are printed in order, followed by Context.ResponseExtensions
+var responseExtensionWriters = []responseExtensionWriter{
+	{
+		key: literalAuthorization,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return r.ctx.authorizer != nil && r.ctx.authorizer.HasResponseExtensionData(r.ctx)
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			return r.ctx.authorizer.RenderResponseExtension(r.ctx, r.out)
+		},
+	},
+	{
+		key: literalRateLimit,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return r.ctx.RateLimitOptions.Enable && r.ctx.RateLimitOptions.IncludeStatsInResponseExtension && r.ctx.rateLimiter != nil
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			return r.ctx.rateLimiter.RenderResponseExtension(r.ctx, r.out)
+		},
+	},
+	{
+		key:     literalTrace,
+		enabled: (*Resolvable).includeTraceExtension,
+		print:   (*Resolvable).printTrace,
+	},
+	{
+		key: literalWarnings,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return r.hasWarnings()
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			r.printNode(r.warningsRoot)
+			return nil
+		},
+	},
+	{
+		key: literalSoftErrors,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return r.hasSoftErrors()
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			r.printNode(r.softErrorsRoot)
+			return nil
+		},
+	},
+	{
+		key: literalDataPresent,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return r.ctx.IncludeDataPresentExtension
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			if r.dataPresent {
+				r.printBytes(literalTrue)
+			} else {
+				r.printBytes(literalFalse)
+			}
+			return nil
+		},
+	},
+	{
+		key: literalFieldSources,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return r.ctx.TraceFieldSources
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			return printJSONObject(r, r.fieldSources)
+		},
+	},
+	{
+		key: literalFieldStates,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return r.ctx.IncludeFieldStatesExtension
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			return printJSONObject(r, r.fieldStates)
+		},
+	},
+	{
+		key: literalCacheStatus,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return r.ctx.IncludeCacheStatus
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			return printJSONObject(r, r.cacheStatus)
+		},
+	},
+	{
+		key: literalDeniedFields,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return len(r.deniedFields) != 0
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			return r.printJSON(r.deniedFields)
+		},
+	},
+	{
+		key: literalRetryableFields,
+		enabled: func(r *Resolvable, _ *Object) bool {
+			return len(r.retryableFields) != 0
+		},
+		print: func(r *Resolvable, _ context.Context, _ *Object) error {
+			r.printBytes(lBrack)
+			for i, path := range r.retryableFields {
+				if i != 0 {
+					r.printBytes(comma)
+				}
+				r.printNode(path)
+			}
+			r.printBytes(rBrack)
+			return nil
+		},
+	},
+}
+
+// RenderExtensions writes only the extensions object of the response, e.g. for middleware logging rate limit stats or traces.
+// It doesn't require a call to Resolve, but extensions computed while walking the data, e.g. warnings, are only
+// present after Resolve. If no extension is enabled, an empty object is written.
+func (r *Resolvable) RenderExtensions(ctx context.Context, fetchTree *Object, out io.Writer) error {
+	r.outCounter = countingWriter{out: out}
+	r.out = &r.outCounter
+	r.printErr = nil
+	if err := r.printExtensionsObject(ctx, fetchTree); err != nil {
+		return err
+	}
+	return r.printErr
+}
+
+func (r *Resolvable) hasExtensions(fetchTree *Object) bool {
+	for i := range responseExtensionWriters {
+		if responseExtensionWriters[i].enabled(r, fetchTree) {
+			return true
+		}
+	}
+	for i := range r.ctx.ResponseExtensions {
+		if r.ctx.ResponseExtensions[i].enabled(r.ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Resolvable) printExtensions(ctx context.Context, fetchTree *Object) error {
+	r.printBytes(quote)
+	r.printBytes(literalExtensions)
+	r.printBytes(quote)
+	r.printBytes(colon)
+	return r.printExtensionsObject(ctx, fetchTree)
+}
+
+func (r *Resolvable) printExtensionsObject(ctx context.Context, fetchTree *Object) error {
+	r.printBytes(lBrace)
+	writeComma := false
+	for i := range responseExtensionWriters {
+		if !responseExtensionWriters[i].enabled(r, fetchTree) {
+			continue
+		}
+		if writeComma {
+			r.printBytes(comma)
+		}
+		writeComma = true
+		r.printExtensionKey(responseExtensionWriters[i].key)
+		if err := responseExtensionWriters[i].print(r, ctx, fetchTree); err != nil {
+			return err
+		}
+	}
+	for i := range r.ctx.ResponseExtensions {
+		if !r.ctx.ResponseExtensions[i].enabled(r.ctx) {
+			continue
+		}
+		if writeComma {
+			r.printBytes(comma)
+		}
+		writeComma = true
+		r.printExtensionKey([]byte(r.ctx.ResponseExtensions[i].Key))
+		if r.printErr != nil {
+			return r.printErr
+		}
+		if err := r.ctx.ResponseExtensions[i].Render(r.ctx, r.out); err != nil {
+			return err
+		}
+	}
+	r.printBytes(rBrace)
+	return nil
+}
+
+func (r *Resolvable) printExtensionKey(key []byte) {
+	r.printBytes(quote)
+	r.printBytes(key)
+	r.printBytes(quote)
+	r.printBytes(colon)
+}
+
+// printJSON prints the JSON encoding of the value of an extension
+func (r *Resolvable) printJSON(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	r.printBytes(data)
+	return nil
+}
+
+// printJSONObject prints an empty object for an empty map
+// Map keys are sorted by the encoder, so the extension is deterministic
+func printJSONObject[V any](r *Resolvable, object map[string]V) error {
+	if len(object) == 0 {
+		r.printBytes(emptyObject)
+		return nil
+	}
+	return r.printJSON(object)
+}
+
+// includeTraceExtension returns true if the trace of the fetches is printed into the extensions
+// The trace is omitted without a fetch tree, e.g. for responses which are not resolved from fetches
+func (r *Resolvable) includeTraceExtension(fetchTree *Object) bool {
+	return fetchTree != nil && r.ctx.TracingOptions.Enable && r.ctx.TracingOptions.IncludeTraceOutputInResponseExtensions
+}
+
+func (r *Resolvable) printTrace(ctx context.Context, fetchTree *Object) error {
+	var trace *TraceNode
+	if r.ctx.TracingOptions.Debug {
+		trace = GetTrace(ctx, fetchTree, GetTraceDebug())
+	} else {
+		trace = GetTrace(ctx, fetchTree)
+	}
+	if r.ctx.TracingOptions.Format == TraceFormatCatapultJSON {
+		return r.printJSON(GetCatapultTrace(trace))
+	}
+	return r.printJSON(trace)
+}
+
+// rootDataPresent returns true if any selected root field has a non-null value
+func (r *Resolvable) rootDataPresent(root *Object) bool {
+	ref := r.storage.Get(r.dataRoot, root.Path)
+	if !r.storage.NodeIsDefined(ref) {
+		return false
+	}
+	for i := range root.Fields {
+		if root.Fields[i].SkipDirectiveDefined && r.skipField(root.Fields[i].SkipVariableName) {
+			continue
+		}
+		if root.Fields[i].IncludeDirectiveDefined && r.excludeField(root.Fields[i].IncludeVariableName) {
+			continue
+		}
+		if root.Fields[i].FeatureFlag != "" && r.excludeFeatureFlag(root.Fields[i].FeatureFlag) {
+			continue
+		}
+		if r.storage.NodeIsDefined(r.storage.Get(ref, root.Fields[i].Value.NodePath())) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordFieldValue sets the value of the field in an extension keyed by the path of the field, e.g. "user.name"
+func recordFieldValue[V any](r *Resolvable, values *map[string]V, field *Field, value V) {
+	if *values == nil {
+		*values = make(map[string]V)
+	}
+	path := string(field.Name)
+	if len(r.path) != 0 {
+		path = r.renderPath() + "." + path
+	}
+	(*values)[path] = value
+}
+
+// recordCacheStatus records whether the data of the field was served from a cache for extensions.cacheStatus, see Context.IncludeCacheStatus
+func (r *Resolvable) recordCacheStatus(field *Field) {
+	if field.Info.CacheHit {
+		recordFieldValue(r, &r.cacheStatus, field, "hit")
+	} else {
+		recordFieldValue(r, &r.cacheStatus, field, "miss")
+	}
+}
+
+// recordFieldState records whether the value of the field is present, null or undefined for extensions.fieldStates,
+// see Context.IncludeFieldStatesExtension
+func (r *Resolvable) recordFieldState(ref int, field *Field) {
+	value := r.storage.Get(ref, field.Value.NodePath())
+	switch {
+	case value == astjson.InvalidRef:
+		recordFieldValue(r, &r.fieldStates, field, "undefined")
+	case !r.storage.NodeIsDefined(value):
+		recordFieldValue(r, &r.fieldStates, field, "null")
+	default:
+		recordFieldValue(r, &r.fieldStates, field, "present")
+	}
+}
+
+// collectRetryableFields collects the paths of errors marked as transient by the subgraph, see Context.IncludeRetryableFields
+func (r *Resolvable) collectRetryableFields() {
+	for _, ref := range r.storage.Nodes[r.errorsRoot].ArrayValues {
+		if r.storage.Nodes[ref].Kind != astjson.NodeKindObject {
+			continue
+		}
+		transient := r.storage.Get(ref, []string{"extensions", "transient"})
+		if !r.storage.NodeIsDefined(transient) || r.storage.Nodes[transient].Kind != astjson.NodeKindBoolean ||
+			!bytes.Equal(r.storage.Nodes[transient].ValueBytes(r.storage), literalTrue) {
+			continue
+		}
+		path := r.storage.GetObjectFieldBytes(ref, literalPath)
+		if !r.storage.NodeIsDefined(path) || r.storage.Nodes[path].Kind != astjson.NodeKindArray {
+			continue
+		}
+		r.retryableFields = append(r.retryableFields, path)
+	}
+}
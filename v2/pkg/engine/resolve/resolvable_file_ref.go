@@ -0,0 +1,65 @@
+package resolve
+
+import (
+	"fmt"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+)
+
+// fileRefKey identifies the occurrence of a FileRef in the response
+type fileRefKey struct {
+	node *FileRef
+	path string
+}
+
+// walkFileRef records the file reference during the first walk, the print walk prints the index of the file as placeholder
+// The value of the field is valid until the next Reset
+func (r *Resolvable) walkFileRef(f *FileRef, ref int) (nodeRef int, hasError bool) {
+	if r.print {
+		r.ctx.Stats.ResolvedLeafs++
+	}
+	ref = r.storage.Get(ref, f.Path)
+	if !r.storage.NodeIsDefined(ref) {
+		if f.Nullable {
+			return r.walkNull()
+		}
+		r.addNonNullableFieldError(ref, f.Path)
+		return astjson.InvalidRef, r.err()
+	}
+	if r.storage.Nodes[ref].Kind != astjson.NodeKindString {
+		value := string(r.storage.Nodes[ref].ValueBytes(r.storage))
+		r.addCoercionError(fmt.Sprintf("FileRef cannot represent non-string value: \"%s\"", value), f.Path, f.Nullable)
+		return astjson.InvalidRef, r.err()
+	}
+	r.pushNodePathElement(f.Path)
+	defer r.popNodePathElement(f.Path)
+	// the index is kept per occurrence of the FileRef in the response, e.g. for aliases of the same data
+	key := fileRefKey{node: f, path: r.renderPath()}
+	if r.print {
+		return r.storage.AppendInt(r.fileRefIndexes[key]), false
+	}
+	if r.fileRefIndexes == nil {
+		r.fileRefIndexes = make(map[fileRefKey]int)
+	}
+	r.fileRefIndexes[key] = len(r.fileRefs)
+	path := make([]any, 0, len(r.path))
+	for i := range r.path {
+		if r.path[i].Name != "" {
+			path = append(path, r.path[i].Name)
+		} else {
+			path = append(path, r.path[i].ArrayIndex)
+		}
+	}
+	r.fileRefs = append(r.fileRefs, FileRefPart{
+		Index: len(r.fileRefs),
+		Path:  path,
+		Value: string(r.storage.Nodes[ref].ValueBytes(r.storage)),
+	})
+	return astjson.InvalidRef, false
+}
+
+// FileRefs returns the files referenced by the FileRef nodes of the response after Resolve, in the order of their placeholders
+// Files of values which are nulled by an error of a parent field are included as well.
+func (r *Resolvable) FileRefs() []FileRefPart {
+	return r.fileRefs
+}
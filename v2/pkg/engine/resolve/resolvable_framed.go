@@ -0,0 +1,32 @@
+package resolve
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/pool"
+)
+
+// ResolveFramed resolves the data like Resolve, but writes a 4-byte big-endian length prefix before the response,
+// e.g. for length-prefixed binary protocols. The response is buffered to compute its length,
+// so nothing is written to out if resolving fails.
+func (r *Resolvable) ResolveFramed(ctx context.Context, rootData *Object, fetchTree *Object, out io.Writer) error {
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	if err := r.Resolve(ctx, rootData, fetchTree, buf); err != nil {
+		return err
+	}
+	if uint64(buf.Len()) > math.MaxUint32 {
+		return fmt.Errorf("response of %d bytes exceeds the maximum frame length", buf.Len())
+	}
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], uint32(buf.Len()))
+	if _, err := out.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
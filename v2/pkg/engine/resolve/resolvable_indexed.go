@@ -0,0 +1,22 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/internal/unsafebytes"
+)
+
+// ResolveIndexed resolves the data like Resolve and returns the response together with a gjson.Result over it,
+// e.g. for callers reading the response repeatedly with gjson. The result references the returned bytes without copying them,
+// so the bytes must not be modified while the result is used.
+func (r *Resolvable) ResolveIndexed(ctx context.Context, rootData *Object, fetchTree *Object) ([]byte, gjson.Result, error) {
+	buf := &bytes.Buffer{}
+	if err := r.Resolve(ctx, rootData, fetchTree, buf); err != nil {
+		return nil, gjson.Result{}, err
+	}
+	response := buf.Bytes()
+	return response, gjson.Parse(unsafebytes.BytesToString(response)), nil
+}
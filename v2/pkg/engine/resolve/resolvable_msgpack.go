@@ -0,0 +1,286 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/internal/unsafebytes"
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/pool"
+)
+
+// ResolveMsgpack resolves the data like Resolve, but serializes the response as MessagePack instead of JSON,
+// e.g. for service-to-service traffic. The response is a map with the same "errors", "data" and "extensions" entries
+// as the JSON response. Strings are decoded from their JSON escaping, integers are encoded as integers
+// and all other numbers as float64, or as string if they are out of the range of float64.
+func (r *Resolvable) ResolveMsgpack(ctx context.Context, rootData *Object, fetchTree *Object, out io.Writer) error {
+	err, authorizationErr := r.walkData(rootData)
+	if authorizationErr != nil {
+		return authorizationErr
+	}
+	return r.encodeResponse(ctx, rootData, fetchTree, err, &msgpackResponseEncoder{
+		r:   r,
+		enc: msgpackEncoder{storage: r.storage},
+		out: out,
+	})
+}
+
+// msgpackResponseEncoder encodes the entries of the response into a buffer,
+// which is written to out with the header of the map once the number of entries is known
+type msgpackResponseEncoder struct {
+	r       *Resolvable
+	enc     msgpackEncoder
+	out     io.Writer
+	entries int
+}
+
+func (e *msgpackResponseEncoder) begin() {}
+
+func (e *msgpackResponseEncoder) singleError(message int) {
+	e.entries++
+	e.enc.appendString(literalError)
+	e.enc.appendNode(message)
+}
+
+func (e *msgpackResponseEncoder) errors() {
+	e.entries++
+	e.enc.appendString(literalErrors)
+	e.enc.appendNode(e.r.errorsRoot)
+}
+
+func (e *msgpackResponseEncoder) data(rootData *Object, nullData bool) {
+	e.entries++
+	e.enc.appendString(literalData)
+	if nullData {
+		e.enc.appendNil()
+		return
+	}
+	e.r.dataPresent = e.r.rootDataPresent(rootData)
+	e.r.walkResolvedData(rootData)
+	if err := e.r.resolveStreamedArrayNodes(); err != nil {
+		e.enc.err = err
+		return
+	}
+	e.enc.appendNode(e.r.resolvedDataRoot)
+	e.r.wroteData = true
+}
+
+func (e *msgpackResponseEncoder) hasNext(hasNext bool) {
+	e.entries++
+	e.enc.appendString(literalHasNext)
+	e.enc.appendBool(hasNext)
+}
+
+func (e *msgpackResponseEncoder) extensions(ctx context.Context, fetchTree *Object) error {
+	extensions, err := e.r.resolveExtensionsNode(ctx, fetchTree)
+	if err != nil {
+		return err
+	}
+	e.entries++
+	e.enc.appendString(literalExtensions)
+	e.enc.appendNode(extensions)
+	return nil
+}
+
+func (e *msgpackResponseEncoder) end() error {
+	if e.enc.err != nil {
+		return e.enc.err
+	}
+	if e.r.printErr != nil {
+		return e.r.printErr
+	}
+	header := msgpackEncoder{}
+	header.appendMapHeader(e.entries)
+	if _, err := e.out.Write(header.buf); err != nil {
+		return err
+	}
+	_, err := e.out.Write(e.enc.buf)
+	return err
+}
+
+// resolveStreamedArrayNodes parses the printed items of streamed arrays into their placeholders in the resolved tree
+func (r *Resolvable) resolveStreamedArrayNodes() error {
+	for placeholder, streamed := range r.streamedArrayNodes {
+		items, err := r.storage.AppendAnyJSONBytes(streamed.items.Bytes())
+		if err != nil {
+			return err
+		}
+		r.storage.Nodes[placeholder] = r.storage.Nodes[items]
+	}
+	return nil
+}
+
+// resolveExtensionsNode renders the extensions like the JSON printer and parses them into the storage
+func (r *Resolvable) resolveExtensionsNode(ctx context.Context, fetchTree *Object) (int, error) {
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	previousOut := r.out
+	r.out = buf
+	defer func() {
+		r.out = previousOut
+	}()
+	r.printBytes(lBrace)
+	if err := r.printExtensions(ctx, fetchTree); err != nil {
+		return astjson.InvalidRef, err
+	}
+	r.printBytes(rBrace)
+	if r.printErr != nil {
+		return astjson.InvalidRef, r.printErr
+	}
+	response, err := r.storage.AppendAnyJSONBytes(buf.Bytes())
+	if err != nil {
+		return astjson.InvalidRef, err
+	}
+	return r.storage.GetObjectFieldBytes(response, literalExtensions), nil
+}
+
+// msgpackEncoder serializes nodes of the storage into the MessagePack format
+type msgpackEncoder struct {
+	storage *astjson.JSON
+	buf     []byte
+	err     error
+}
+
+func (e *msgpackEncoder) appendNode(ref int) {
+	if e.err != nil {
+		return
+	}
+	if !e.storage.NodeIsDefined(ref) {
+		e.appendNil()
+		return
+	}
+	node := e.storage.Nodes[ref]
+	switch node.Kind {
+	case astjson.NodeKindObject:
+		e.appendMapHeader(len(node.ObjectFields))
+		for _, field := range node.ObjectFields {
+			e.appendString(e.storage.ObjectFieldKey(field))
+			e.appendNode(e.storage.ObjectFieldValue(field))
+		}
+	case astjson.NodeKindArray:
+		e.appendArrayHeader(len(node.ArrayValues))
+		for _, value := range node.ArrayValues {
+			e.appendNode(value)
+		}
+	case astjson.NodeKindString:
+		e.appendString(node.ValueBytes(e.storage))
+	case astjson.NodeKindNumber:
+		e.appendNumber(node.ValueBytes(e.storage))
+	case astjson.NodeKindBoolean:
+		e.appendBool(bytes.Equal(node.ValueBytes(e.storage), literalTrue))
+	default:
+		e.appendNil()
+	}
+}
+
+func (e *msgpackEncoder) appendNil() {
+	e.buf = append(e.buf, 0xc0)
+}
+
+func (e *msgpackEncoder) appendBool(value bool) {
+	if value {
+		e.buf = append(e.buf, 0xc3)
+	} else {
+		e.buf = append(e.buf, 0xc2)
+	}
+}
+
+func (e *msgpackEncoder) appendMapHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xde), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xdf), uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) appendArrayHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xdc), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xdd), uint32(n))
+	}
+}
+
+// appendString appends the JSON escaped string value as msgpack string
+func (e *msgpackEncoder) appendString(value []byte) {
+	if bytes.IndexByte(value, '\\') != -1 {
+		quoted := make([]byte, 0, len(value)+2)
+		quoted = append(append(append(quoted, '"'), value...), '"')
+		var unescaped string
+		if err := json.Unmarshal(quoted, &unescaped); err != nil {
+			e.err = err
+			return
+		}
+		value = unsafebytes.StringToBytes(unescaped)
+	}
+	n := len(value)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xda), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xdb), uint32(n))
+	}
+	e.buf = append(e.buf, value...)
+}
+
+// appendNumber appends integers as the smallest msgpack integer and all other numbers as float64
+// Numbers out of the range of float64 are appended as string.
+func (e *msgpackEncoder) appendNumber(value []byte) {
+	number := unsafebytes.BytesToString(value)
+	integer, err := strconv.ParseInt(number, 10, 64)
+	if err == nil {
+		e.appendInt(integer)
+		return
+	}
+	if goerrors.Is(err, strconv.ErrRange) && number[0] != '-' {
+		if unsigned, err := strconv.ParseUint(number, 10, 64); err == nil {
+			e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcf), unsigned)
+			return
+		}
+	}
+	float, err := strconv.ParseFloat(number, 64)
+	if goerrors.Is(err, strconv.ErrRange) {
+		// the number is out of the range of float64, encode it as string so it isn't turned into infinity
+		e.appendString(value)
+		return
+	}
+	if err != nil {
+		e.err = fmt.Errorf("invalid number: %s", number)
+		return
+	}
+	e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcb), math.Float64bits(float))
+}
+
+func (e *msgpackEncoder) appendInt(value int64) {
+	switch {
+	case value >= 0 && value <= math.MaxInt8:
+		e.buf = append(e.buf, byte(value))
+	case value < 0 && value >= -32:
+		e.buf = append(e.buf, byte(value))
+	case value >= math.MinInt8 && value <= math.MaxInt8:
+		e.buf = append(e.buf, 0xd0, byte(value))
+	case value >= math.MinInt16 && value <= math.MaxInt16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xd1), uint16(value))
+	case value >= math.MinInt32 && value <= math.MaxInt32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xd2), uint32(value))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xd3), uint64(value))
+	}
+}
@@ -0,0 +1,166 @@
+package resolve
+
+import (
+	"bytes"
+	"maps"
+	"runtime"
+	"sync"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+)
+
+// parallelRootField is the result of printing a single root field
+type parallelRootField struct {
+	name  []byte
+	buf   bytes.Buffer
+	stats Stats
+	err   error
+}
+
+func (r *Resolvable) parallelRootFieldsEligible(root *Object) bool {
+	if !r.ctx.ParallelRootFields || len(root.Fields) < 2 {
+		return false
+	}
+	if r.ctx.observesPrintWalk() || r.previousDataRoot != astjson.InvalidRef {
+		return false
+	}
+	if hasOverlappingFieldNames(root) {
+		return false
+	}
+	for i := range root.Fields {
+		if obj, ok := root.Fields[i].Value.(*Object); ok && obj.FlattenInto {
+			return false
+		}
+	}
+	return true
+}
+
+// printParallelRootFields walks and prints the root fields concurrently, each into its own buffer
+// The buffers are written to the output in the order of the root fields
+func (r *Resolvable) printParallelRootFields(root *Object) {
+	results := make([]parallelRootField, len(root.Fields))
+	indexes := make(chan int, len(root.Fields))
+	for i := range root.Fields {
+		indexes <- i
+	}
+	close(indexes)
+	workers := min(runtime.GOMAXPROCS(0), len(root.Fields))
+	children := make([]*Resolvable, workers)
+	for w, storage := range r.storage.Forks(workers) {
+		children[w] = r.forkRootFieldWalker(storage)
+	}
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+	for _, child := range children {
+		go func(child *Resolvable) {
+			defer wg.Done()
+			for i := range indexes {
+				child.printRootField(root, i, &results[i])
+			}
+		}(child)
+	}
+	wg.Wait()
+	errors, warnings, softErrors := r.messageCounts()
+	for _, child := range children {
+		r.mergeRootFieldWalker(child, errors, warnings, softErrors)
+	}
+
+	r.printBytes(lBrace)
+	printed := false
+	for i := range results {
+		r.ctx.Stats.ResolvedNodes += results[i].stats.ResolvedNodes
+		r.ctx.Stats.ResolvedObjects += results[i].stats.ResolvedObjects
+		r.ctx.Stats.ResolvedLeafs += results[i].stats.ResolvedLeafs
+		if results[i].err != nil && r.printErr == nil {
+			r.printErr = results[i].err
+		}
+		if results[i].buf.Len() == 0 {
+			continue
+		}
+		if printed {
+			r.printBytes(comma)
+		}
+		printed = true
+		start := r.outCounter.written
+		r.printBytes(results[i].buf.Bytes())
+		r.fieldByteSizes[string(results[i].name)] += r.outCounter.written - start
+	}
+	r.printBytes(rBrace)
+}
+
+// forkRootFieldWalker returns a Resolvable printing root fields into a fork of the storage with a copy of the Context,
+// so that no state is shared with root fields walked concurrently.
+// Only the state used by the print walk is copied, mergeRootFieldWalker merges the state written by the print walk back after the walk.
+func (r *Resolvable) forkRootFieldWalker(storage *astjson.JSON) *Resolvable {
+	ctx := *r.ctx
+	return &Resolvable{
+		storage:          storage,
+		dataRoot:         r.dataRoot,
+		previousDataRoot: r.previousDataRoot,
+		errorsRoot:       r.errorsRoot,
+		warningsRoot:     r.warningsRoot,
+		softErrorsRoot:   r.softErrorsRoot,
+		variablesRoot:    r.variablesRoot,
+		print:            true,
+		depth:            r.depth,
+		operationType:    r.operationType,
+		renameTypeNames:  r.renameTypeNames,
+		failFast:         r.failFast,
+		ctx:              &ctx,
+		// the maps are cloned, so no map is shared with other forks, even if the print walk writes to it
+		pageInfos:          maps.Clone(r.pageInfos),
+		fileRefIndexes:     maps.Clone(r.fileRefIndexes),
+		streamedArrays:     maps.Clone(r.streamedArrays),
+		transformedObjects: maps.Clone(r.transformedObjects),
+		reverseTypeNames:   maps.Clone(r.reverseTypeNames),
+		customResults:      maps.Clone(r.customResults),
+		errorPaths:         maps.Clone(r.errorPaths),
+	}
+}
+
+// mergeRootFieldWalker merges the state written by the print walk of the fork into r
+// Errors added while printing are copied into the storage of r, so they are reported like errors of a sequential walk.
+// errors, warnings and softErrors are the message counts of r when it was forked.
+func (r *Resolvable) mergeRootFieldWalker(child *Resolvable, errors, warnings, softErrors int) {
+	for key, resolved := range child.customResults {
+		if r.customResults == nil {
+			r.customResults = make(map[string][]byte)
+		}
+		r.customResults[key] = resolved
+	}
+	for path := range child.errorPaths {
+		if r.errorPaths == nil {
+			r.errorPaths = make(map[errorPathKey]struct{})
+		}
+		r.errorPaths[path] = struct{}{}
+	}
+	forked := []int{errors, warnings, softErrors}
+	for i, ref := range []int{r.errorsRoot, r.warningsRoot, r.softErrorsRoot} {
+		for _, value := range child.storage.Nodes[ref].ArrayValues[forked[i]:] {
+			r.storage.Nodes[ref].ArrayValues = append(r.storage.Nodes[ref].ArrayValues, r.storage.ImportNode(child.storage, value))
+		}
+	}
+}
+
+// printRootField walks the root field at index i and prints it into the buffer of the result
+func (r *Resolvable) printRootField(root *Object, i int, result *parallelRootField) {
+	r.ctx.Stats = Stats{}
+	r.out = &result.buf
+	r.printErr = nil
+
+	ref, _ := r.walkObject(&Object{Nullable: root.Nullable, Path: root.Path, Fields: root.Fields[i : i+1]}, r.dataRoot)
+	if r.storage.NodeIsDefined(ref) && r.storage.Nodes[ref].Kind == astjson.NodeKindObject {
+		for _, field := range r.storage.Nodes[ref].ObjectFields {
+			result.name = r.storage.ObjectFieldKey(field)
+			r.printBytes(quote)
+			r.printBytes(result.name)
+			r.printBytes(quote)
+			r.printBytes(colon)
+			r.printNode(r.storage.ObjectFieldValue(field))
+		}
+	}
+	result.stats.ResolvedNodes = r.ctx.Stats.ResolvedNodes
+	result.stats.ResolvedObjects = r.ctx.Stats.ResolvedObjects
+	result.stats.ResolvedLeafs = r.ctx.Stats.ResolvedLeafs
+	result.err = r.printErr
+}
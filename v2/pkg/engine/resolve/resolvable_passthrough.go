@@ -0,0 +1,211 @@
+package resolve
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+)
+
+// passThroughEligible returns true if the data of the response can be printed without transformations.
+// In this case, the print walk writes the selected data directly to the output
+// instead of building the resolved tree in the storage first.
+// Authorization is not relevant here, because it's applied to the data during the first walk.
+// Options which change the printed data opt out of the fast path with Context.changesPrintedData.
+func (r *Resolvable) passThroughEligible(node Node) bool {
+	if r.ctx.observesPrintWalk() || r.ctx.changesPrintedData() || r.previousDataRoot != astjson.InvalidRef {
+		return false
+	}
+	return r.passThroughEligibleNode(node)
+}
+
+func (r *Resolvable) passThroughEligibleNode(node Node) bool {
+	switch n := node.(type) {
+	case *Object:
+		if n.FlattenInto || hasOverlappingFieldNames(n) {
+			return false
+		}
+		for i := range n.Fields {
+			if n.Fields[i].NullPlaceholder != nil {
+				return false
+			}
+			if !r.passThroughEligibleNode(n.Fields[i].Value) {
+				return false
+			}
+		}
+		return true
+	case *Array:
+		return r.passThroughEligibleNode(n.Item)
+	case *String:
+		if n.UnescapeResponseJson {
+			return false
+		}
+		if n.IsTypeName && (len(r.renameTypeNames) != 0 || r.ctx.TypeNameRewriter != nil) {
+			return false
+		}
+		return n.MaxBytes == 0
+	case *Scalar:
+		return n.MaxBytes == 0
+	case *Null, *EmptyObject, *EmptyArray, *Boolean, *Integer, *Float, *BigInt:
+		return true
+	default:
+		return false
+	}
+}
+
+// hasOverlappingFieldNames returns true if multiple fields with the same response key can be selected at the same time
+// The walk merges such fields, so they can't be printed one after another
+func hasOverlappingFieldNames(obj *Object) bool {
+	for i := range obj.Fields {
+		for j := 0; j < i; j++ {
+			if !bytes.Equal(obj.Fields[i].Name, obj.Fields[j].Name) {
+				continue
+			}
+			if obj.Fields[i].OnTypeNames == nil || obj.Fields[j].OnTypeNames == nil {
+				return true
+			}
+			for _, typeName := range obj.Fields[i].OnTypeNames {
+				if slices.ContainsFunc(obj.Fields[j].OnTypeNames, func(other []byte) bool {
+					return bytes.Equal(typeName, other)
+				}) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (r *Resolvable) printPassThroughNode(node Node, ref int) {
+	r.ctx.Stats.ResolvedNodes++
+	switch n := node.(type) {
+	case *Object:
+		r.printPassThroughObject(n, ref)
+	case *Array:
+		r.printPassThroughArray(n, ref)
+	case *EmptyObject:
+		r.printBytes(emptyObject)
+	case *EmptyArray:
+		r.printBytes(emptyArray)
+	case *Null:
+		r.ctx.Stats.ResolvedLeafs++
+		r.printBytes(null)
+	default:
+		r.ctx.Stats.ResolvedLeafs++
+		r.printPassThroughLeaf(node, r.storage.Get(ref, node.NodePath()))
+	}
+}
+
+func leafDefaultValue(node Node) []byte {
+	switch n := node.(type) {
+	case *String:
+		return n.DefaultValue
+	case *Boolean:
+		return n.DefaultValue
+	case *Integer:
+		return n.DefaultValue
+	case *Float:
+		return n.DefaultValue
+	case *BigInt:
+		return n.DefaultValue
+	case *Scalar:
+		return n.DefaultValue
+	default:
+		return nil
+	}
+}
+
+func (r *Resolvable) printPassThroughObject(obj *Object, ref int) {
+	ref = r.storage.Get(ref, obj.Path)
+	if transformedRef, ok := r.transformedObjects[ref]; ok {
+		ref = transformedRef
+	}
+	if !r.storage.NodeIsDefined(ref) || r.storage.Nodes[ref].Kind != astjson.NodeKindObject {
+		r.printBytes(null)
+		return
+	}
+	if r.depth != 0 {
+		r.ctx.Stats.ResolvedObjects++
+	}
+	r.depth++
+	defer func() {
+		r.depth--
+	}()
+	r.printBytes(lBrace)
+	writeComma := false
+	for i := range obj.Fields {
+		if obj.Fields[i].SkipDirectiveDefined && r.skipField(obj.Fields[i].SkipVariableName) {
+			continue
+		}
+		if obj.Fields[i].IncludeDirectiveDefined && r.excludeField(obj.Fields[i].IncludeVariableName) {
+			continue
+		}
+		if obj.Fields[i].FeatureFlag != "" && r.excludeFeatureFlag(obj.Fields[i].FeatureFlag) {
+			continue
+		}
+		if obj.Fields[i].OnTypeNames != nil && r.skipFieldOnTypeNames(ref, obj.Fields[i]) {
+			continue
+		}
+		if writeComma {
+			r.printBytes(comma)
+		}
+		writeComma = true
+		start := r.outCounter.written
+		r.printBytes(quote)
+		r.printBytes(obj.Fields[i].Name)
+		r.printBytes(quote)
+		r.printBytes(colon)
+		r.printPassThroughNode(obj.Fields[i].Value, ref)
+		if r.depth == 1 {
+			r.fieldByteSizes[string(obj.Fields[i].Name)] += r.outCounter.written - start
+		}
+	}
+	r.printBytes(rBrace)
+}
+
+func (r *Resolvable) printPassThroughArray(arr *Array, ref int) {
+	ref = r.storage.Get(ref, arr.Path)
+	if !r.storage.NodeIsDefined(ref) || r.storage.Nodes[ref].Kind != astjson.NodeKindArray {
+		r.printBytes(null)
+		return
+	}
+	r.printBytes(lBrack)
+	for i, value := range r.arrayItems(ref) {
+		if i != 0 {
+			r.printBytes(comma)
+		}
+		r.printPassThroughNode(arr.Item, value)
+	}
+	r.printBytes(rBrack)
+}
+
+func (r *Resolvable) printPassThroughLeaf(node Node, ref int) {
+	if ref == astjson.InvalidRef && node.NodeNullable() {
+		if defaultValue := leafDefaultValue(node); defaultValue != nil {
+			r.printBytes(defaultValue)
+			return
+		}
+	}
+	if !r.storage.NodeIsDefined(ref) {
+		r.printBytes(null)
+		return
+	}
+	r.printNode(ref)
+}
+
+// materializeResolvedData builds the resolved data tree if the data was printed using the pass-through fast path
+func (r *Resolvable) materializeResolvedData() {
+	if r.resolvedDataRoot != astjson.InvalidRef || r.passThroughRoot == nil {
+		return
+	}
+	// the print walk already accounted for the resolved nodes
+	stats := r.ctx.Stats.ResolvedNodes
+	objects := r.ctx.Stats.ResolvedObjects
+	leafs := r.ctx.Stats.ResolvedLeafs
+	r.print = true
+	r.resolvedDataRoot, _ = r.walkObject(r.passThroughRoot, r.dataRoot)
+	r.print = false
+	r.ctx.Stats.ResolvedNodes = stats
+	r.ctx.Stats.ResolvedObjects = objects
+	r.ctx.Stats.ResolvedLeafs = leafs
+}
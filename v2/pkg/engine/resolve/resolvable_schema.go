@@ -0,0 +1,64 @@
+package resolve
+
+import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaViolation is a location in the resolved data which doesn't conform to the expected JSON Schema
+type SchemaViolation struct {
+	// Path is the JSON Pointer of the violating value within "data", e.g. /user/id
+	Path string
+	// Message describes the violation
+	Message string
+}
+
+// ResolveAndValidateSchema resolves the data like Resolve and validates the resolved "data" against the JSON Schema,
+// e.g. for contract tests. The response is resolved into memory and not written anywhere, so no trace is printed.
+// An error is returned if the response can't be resolved or the schema can't be compiled,
+// a response which doesn't conform to the schema is reported through the returned violations.
+func (r *Resolvable) ResolveAndValidateSchema(rootData *Object, schema []byte) ([]SchemaViolation, error) {
+	compiled, err := jsonschema.CompileString("schema.json", string(schema))
+	if err != nil {
+		return nil, err
+	}
+	out := &bytes.Buffer{}
+	if err = r.Resolve(r.ctx.Context(), rootData, nil, out); err != nil {
+		return nil, err
+	}
+	var response struct {
+		Data any `json:"data"`
+	}
+	decoder := json.NewDecoder(out)
+	// keep numbers exact, e.g. to validate large integers against "integer"
+	decoder.UseNumber()
+	if err = decoder.Decode(&response); err != nil {
+		return nil, err
+	}
+	err = compiled.Validate(response.Data)
+	if err == nil {
+		return nil, nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !goerrors.As(err, &validationErr) {
+		return nil, err
+	}
+	return appendSchemaViolations(nil, validationErr), nil
+}
+
+// appendSchemaViolations flattens the tree of validation errors into its leaves, which are the actual violations
+func appendSchemaViolations(violations []SchemaViolation, validationErr *jsonschema.ValidationError) []SchemaViolation {
+	if len(validationErr.Causes) == 0 {
+		return append(violations, SchemaViolation{
+			Path:    validationErr.InstanceLocation,
+			Message: validationErr.Message,
+		})
+	}
+	for _, cause := range validationErr.Causes {
+		violations = appendSchemaViolations(violations, cause)
+	}
+	return violations
+}
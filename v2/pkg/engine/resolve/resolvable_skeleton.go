@@ -0,0 +1,85 @@
+package resolve
+
+import (
+	"bytes"
+	"io"
+)
+
+// ResolveSkeleton prints a response matching the shape of the selection set without using any data.
+// Objects are printed with all selected fields, lists are printed as empty lists and all other values are printed as null.
+// Skip and include directives and feature flags are evaluated using the variables and the Context, so ResolveSkeleton must be called after Init.
+// Fields selected on multiple type conditions are printed once.
+func (r *Resolvable) ResolveSkeleton(rootData *Object, out io.Writer) error {
+	r.out = out
+	r.printErr = nil
+	r.printBytes(lBrace)
+	r.printBytes(quote)
+	r.printBytes(literalData)
+	r.printBytes(quote)
+	r.printBytes(colon)
+	r.printSkeletonObject(rootData)
+	r.printBytes(rBrace)
+	return r.printErr
+}
+
+func (r *Resolvable) printSkeletonNode(node Node) {
+	switch n := node.(type) {
+	case *Object:
+		r.printSkeletonObject(n)
+	case *Array, *StreamedArray, *EmptyArray:
+		r.printBytes(emptyArray)
+	case *EmptyObject:
+		r.printBytes(emptyObject)
+	default:
+		r.printBytes(null)
+	}
+}
+
+func (r *Resolvable) printSkeletonObject(obj *Object) {
+	r.printBytes(lBrace)
+	printed := false
+	for i := range obj.Fields {
+		if obj.Fields[i].SkipDirectiveDefined && r.skipField(obj.Fields[i].SkipVariableName) {
+			continue
+		}
+		if obj.Fields[i].IncludeDirectiveDefined && r.excludeField(obj.Fields[i].IncludeVariableName) {
+			continue
+		}
+		if obj.Fields[i].FeatureFlag != "" && r.excludeFeatureFlag(obj.Fields[i].FeatureFlag) {
+			continue
+		}
+		if r.skeletonFieldPrinted(obj, i) {
+			continue
+		}
+		if printed {
+			r.printBytes(comma)
+		}
+		printed = true
+		r.printBytes(quote)
+		r.printBytes(obj.Fields[i].Name)
+		r.printBytes(quote)
+		r.printBytes(colon)
+		r.printSkeletonNode(obj.Fields[i].Value)
+	}
+	r.printBytes(rBrace)
+}
+
+// skeletonFieldPrinted returns true if a field with the same name was printed before the field at index i
+func (r *Resolvable) skeletonFieldPrinted(obj *Object, i int) bool {
+	for j := 0; j < i; j++ {
+		if !bytes.Equal(obj.Fields[j].Name, obj.Fields[i].Name) {
+			continue
+		}
+		if obj.Fields[j].SkipDirectiveDefined && r.skipField(obj.Fields[j].SkipVariableName) {
+			continue
+		}
+		if obj.Fields[j].IncludeDirectiveDefined && r.excludeField(obj.Fields[j].IncludeVariableName) {
+			continue
+		}
+		if obj.Fields[j].FeatureFlag != "" && r.excludeFeatureFlag(obj.Fields[j].FeatureFlag) {
+			continue
+		}
+		return true
+	}
+	return false
+}
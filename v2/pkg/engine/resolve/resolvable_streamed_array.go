@@ -0,0 +1,230 @@
+package resolve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+)
+
+// streamedArray is a StreamedArray printed during the first walk
+type streamedArray struct {
+	items bytes.Buffer
+	// err aborts printing the response, as the items can't be resolved
+	err error
+}
+
+func (r *Resolvable) walkStreamedArray(arr *StreamedArray, ref int) (nodeRef int, hasError bool) {
+	ref = r.storage.Get(ref, arr.Path)
+	if !r.storage.NodeIsDefined(ref) {
+		if arr.Nullable {
+			return r.walkNull()
+		}
+		r.addNonNullableFieldError(ref, arr.Path)
+		return astjson.InvalidRef, r.err()
+	}
+	r.pushNodePathElement(arr.Path)
+	defer r.popNodePathElement(arr.Path)
+	if r.print {
+		streamed, ok := r.streamedArrays[ref]
+		if !ok {
+			r.printErr = fmt.Errorf("streamed array at path '%s' was not walked before printing", r.renderPath())
+			return r.storage.AppendNull(), false
+		}
+		if streamed.err != nil {
+			// the errors are already printed, so the error aborts printing the response
+			r.printErr = streamed.err
+			return r.storage.AppendNull(), false
+		}
+		// the placeholder is printed as the printed items, see printStreamedArrayNode
+		nodeRef = r.storage.AppendNull()
+		if r.streamedArrayNodes == nil {
+			r.streamedArrayNodes = make(map[int]*streamedArray)
+		}
+		r.streamedArrayNodes[nodeRef] = streamed
+		return nodeRef, false
+	}
+	streamed := &streamedArray{}
+	if r.streamedArrays == nil {
+		r.streamedArrays = make(map[int]*streamedArray)
+	}
+	r.streamedArrays[ref] = streamed
+	failed, err := r.printStreamedArray(arr, ref, &streamed.items)
+	if err != nil {
+		streamed.err = err
+		return astjson.InvalidRef, false
+	}
+	if failed {
+		if arr.Nullable {
+			r.nullBubble()
+			// set ref to null so the print walk renders null
+			r.storage.Nodes[ref].Kind = astjson.NodeKindNull
+			return astjson.InvalidRef, false
+		}
+		return astjson.InvalidRef, true
+	}
+	return astjson.InvalidRef, false
+}
+
+// printStreamedArray decodes the items of the streamed array one by one, walks each item and prints it to out
+// It returns true if an item can't be resolved, the error of the item is added to the errors of the response.
+// The nodes of each printed item are removed from the storage, so only a single item is held in memory.
+func (r *Resolvable) printStreamedArray(arr *StreamedArray, ref int, out *bytes.Buffer) (failed bool, err error) {
+	reader, err := arr.Source.OpenArray(r.ctx, r.storage.Nodes[ref].ValueBytes(r.storage))
+	if err != nil {
+		return false, err
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	decoder := json.NewDecoder(reader)
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+	if token == nil && arr.Nullable {
+		out.Write(null)
+		return false, nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return false, fmt.Errorf("streamed array at path '%s' cannot represent non-array value", r.renderPath())
+	}
+	previousOut := r.out
+	defer func() {
+		r.out = previousOut
+	}()
+	r.out = out
+	r.printBytes(lBrack)
+	var item json.RawMessage
+	for i := 0; decoder.More(); i++ {
+		if err = decoder.Decode(&item); err != nil {
+			return false, err
+		}
+		nodes, storage := r.storage.Len()
+		errorCount, warningCount, softErrorCount := r.messageCounts()
+		itemRef, err := r.storage.AppendAnyJSONBytes(item)
+		if err != nil {
+			return false, err
+		}
+		r.pushArrayPathElement(i)
+		itemNodeRef, failed := r.walkStreamedItem(arr.Item, itemRef)
+		r.popArrayPathElement()
+		if failed {
+			return true, nil
+		}
+		if i != 0 {
+			r.printBytes(comma)
+		}
+		r.printNode(itemNodeRef)
+		if r.printErr != nil {
+			err, r.printErr = r.printErr, nil
+			return false, err
+		}
+		if newErrors, newWarnings, newSoftErrors := r.messageCounts(); newErrors == errorCount && newWarnings == warningCount && newSoftErrors == softErrorCount {
+			r.truncateStorage(nodes, storage)
+		}
+	}
+	r.printBytes(rBrack)
+	// consume the closing bracket, so a truncated array is an error
+	if _, err = decoder.Token(); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// walkStreamedItem runs both walks on a single item of a streamed array
+func (r *Resolvable) walkStreamedItem(item Node, ref int) (nodeRef int, hasError bool) {
+	_, hasError = r.walkNode(item, ref)
+	if hasError {
+		return astjson.InvalidRef, true
+	}
+	r.print = true
+	defer func() {
+		r.print = false
+	}()
+	return r.walkNode(item, ref)
+}
+
+// messageCounts returns the number of errors, warnings and soft errors of the response
+func (r *Resolvable) messageCounts() (errors, warnings, softErrors int) {
+	return len(r.storage.Nodes[r.errorsRoot].ArrayValues),
+		len(r.storage.Nodes[r.warningsRoot].ArrayValues),
+		len(r.storage.Nodes[r.softErrorsRoot].ArrayValues)
+}
+
+// truncateStorage removes the nodes appended after nodes, e.g. the nodes of a printed item of a streamed array
+// State referencing the removed nodes is dropped, so the refs can be reused.
+func (r *Resolvable) truncateStorage(nodes, storage int) {
+	r.storage.Truncate(nodes, storage)
+	if r.authorizationBufObjectRef >= nodes {
+		r.authorizationBufObjectRef = -1
+	}
+	for ref := range r.pageInfos {
+		if ref >= nodes {
+			delete(r.pageInfos, ref)
+		}
+	}
+	for ref, copyRef := range r.transformedObjects {
+		if ref >= nodes || copyRef >= nodes {
+			delete(r.transformedObjects, ref)
+		}
+	}
+	for ref := range r.streamedArrays {
+		if ref >= nodes {
+			delete(r.streamedArrays, ref)
+		}
+	}
+	for ref := range r.streamedArrayNodes {
+		if ref >= nodes {
+			delete(r.streamedArrayNodes, ref)
+		}
+	}
+}
+
+// printStreamedArrayNode prints the node at ref like printNode, but prints the items of streamed arrays for their placeholders
+func (r *Resolvable) printStreamedArrayNode(ref int) {
+	if streamed, ok := r.streamedArrayNodes[ref]; ok {
+		r.printBytes(streamed.items.Bytes())
+		return
+	}
+	switch r.storage.Nodes[ref].Kind {
+	case astjson.NodeKindObject:
+		r.printBytes(lBrace)
+		for i, field := range r.storage.Nodes[ref].ObjectFields {
+			if i != 0 {
+				r.printBytes(comma)
+			}
+			r.printBytes(quote)
+			r.printBytes(r.storage.ObjectFieldKey(field))
+			r.printBytes(quote)
+			r.printBytes(colon)
+			value := r.storage.ObjectFieldValue(field)
+			if _, ok := r.streamedArrayNodes[value]; !ok && !r.storage.NodeIsDefined(value) {
+				r.printBytes(null)
+				continue
+			}
+			r.printStreamedArrayNode(value)
+		}
+		r.printBytes(rBrace)
+	case astjson.NodeKindArray:
+		r.printBytes(lBrack)
+		for i, value := range r.storage.Nodes[ref].ArrayValues {
+			if i != 0 {
+				r.printBytes(comma)
+			}
+			r.printStreamedArrayNode(value)
+		}
+		r.printBytes(rBrack)
+	default:
+		if r.printErr != nil {
+			return
+		}
+		if r.ctx.NodeEncoder != nil {
+			r.printErr = r.ctx.NodeEncoder.EncodeNode(r.storage, ref, r.out)
+			return
+		}
+		r.printErr = r.storage.PrintNode(r.storage.Nodes[ref], r.out)
+	}
+}
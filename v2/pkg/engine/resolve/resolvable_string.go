@@ -0,0 +1,96 @@
+package resolve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
+)
+
+// walkNestedJSONString unescapes the JSON embedded in the string value up to String.UnescapeDepth levels
+func (r *Resolvable) walkNestedJSONString(s *String, ref int) (nodeRef int, hasError bool) {
+	value := r.storage.Nodes[ref].ValueBytes(r.storage)
+	unescaped, ok := unescapeNestedJSON(value, max(s.UnescapeDepth, 1))
+	if !ok && s.StrictUnescape {
+		r.addCoercionError(fmt.Sprintf("String cannot represent invalid nested JSON value: \"%s\"", value), s.Path, s.Nullable)
+		return astjson.InvalidRef, r.err()
+	}
+	if !r.print {
+		return astjson.InvalidRef, false
+	}
+	nodeRef, err := r.storage.AppendAnyJSONBytes(unescaped)
+	if err != nil {
+		r.printErr = err
+		return astjson.InvalidRef, r.err()
+	}
+	return nodeRef, false
+}
+
+// unescapeNestedJSON unescapes the JSON escaped string value up to depth times, as long as the unescaped value is a JSON string itself.
+// It returns the JSON of the last valid level and false if a level isn't valid JSON
+func unescapeNestedJSON(value []byte, depth int) (result []byte, ok bool) {
+	result = make([]byte, 0, len(value)+2)
+	result = append(append(append(result, '"'), value...), '"')
+	for level := 0; level < depth; level++ {
+		var unescaped string
+		if err := json.Unmarshal(result, &unescaped); err != nil {
+			return result, false
+		}
+		next := bytes.TrimSpace([]byte(unescaped))
+		if !json.Valid(next) {
+			return result, false
+		}
+		result = next
+		if result[0] != '"' {
+			break
+		}
+	}
+	return result, true
+}
+
+// walkOverlongString handles a string value exceeding maxBytes according to the mode
+func (r *Resolvable) walkOverlongString(ref int, path []string, nullable bool, maxBytes int, mode MaxBytesMode) (nodeRef int, hasError bool) {
+	value := r.storage.Nodes[ref].ValueBytes(r.storage)
+	if mode == MaxBytesError {
+		r.addCoercionError(fmt.Sprintf("String value of %d bytes exceeds the limit of %d bytes.", len(value), maxBytes), path, nullable)
+		return astjson.InvalidRef, r.err()
+	}
+	if !r.print {
+		r.addWarning(fmt.Sprintf("String value truncated from %d to %d bytes.", len(value), maxBytes), path)
+		return astjson.InvalidRef, false
+	}
+	return r.storage.AppendStringBytes(truncateJSONString(value, maxBytes)), false
+}
+
+// truncateJSONString returns the longest prefix of the JSON escaped string value with at most maxBytes bytes
+// which neither splits a multibyte character nor an escape sequence
+func truncateJSONString(value []byte, maxBytes int) []byte {
+	end := 0
+	for end < len(value) {
+		size := jsonStringCharSize(value[end:])
+		if end+size > maxBytes {
+			break
+		}
+		end += size
+	}
+	return value[:end]
+}
+
+// jsonStringCharSize returns the number of bytes of the first character of the JSON escaped string value
+func jsonStringCharSize(value []byte) int {
+	if value[0] != '\\' {
+		_, size := utf8.DecodeRune(value)
+		return size
+	}
+	if len(value) < 6 || value[1] != 'u' {
+		return min(2, len(value))
+	}
+	// a surrogate pair escapes a single character, e.g. \ud83d\ude00
+	if len(value) >= 12 && (value[2] == 'd' || value[2] == 'D') && bytes.IndexByte([]byte("89abAB"), value[3]) != -1 &&
+		value[6] == '\\' && value[7] == 'u' {
+		return 12
+	}
+	return 6
+}
@@ -3,11 +3,23 @@ package resolve
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
 
 	"github.com/wundergraph/graphql-go-tools/v2/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/v2/pkg/astjson"
 )
 
 func TestResolvable_Resolve(t *testing.T) {
@@ -573,3 +585,6076 @@ func TestResolvable_WithTracing(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, `{"data":{"topProducts":[{"name":"Table","stock":8,"reviews":[{"body":"Love Table!","author":{"name":"user-1"}},{"body":"Prefer other Table.","author":{"name":"user-2"}}]},{"name":"Couch","stock":2,"reviews":[{"body":"Couch Too expensive.","author":{"name":"user-1"}}]},{"name":"Chair","stock":5,"reviews":[{"body":"Chair Could be better.","author":{"name":"user-2"}}]}]},"extensions":{"trace":{"info":{"trace_start_time":"","trace_start_unix":0,"parse_stats":{"duration_nanoseconds":5,"duration_pretty":"5ns","duration_since_start_nanoseconds":5,"duration_since_start_pretty":"5ns"},"normalize_stats":{"duration_nanoseconds":5,"duration_pretty":"5ns","duration_since_start_nanoseconds":10,"duration_since_start_pretty":"10ns"},"validate_stats":{"duration_nanoseconds":5,"duration_pretty":"5ns","duration_since_start_nanoseconds":15,"duration_since_start_pretty":"15ns"},"planner_stats":{"duration_nanoseconds":5,"duration_pretty":"5ns","duration_since_start_nanoseconds":20,"duration_since_start_pretty":"20ns"}},"node_type":"object","fields":[{"name":"topProducts","value":{"node_type":"array","path":["topProducts"],"items":[{"node_type":"object","fields":[{"name":"name","value":{"node_type":"string","path":["name"]}},{"name":"stock","value":{"node_type":"integer","path":["stock"]}},{"name":"reviews","value":{"node_type":"array","path":["reviews"],"items":[{"node_type":"object","fields":[{"name":"body","value":{"node_type":"string","path":["body"]}},{"name":"author","value":{"node_type":"object","path":["author"],"fields":[{"name":"name","value":{"node_type":"string","path":["name"]}}]}}]}]}}]}]}}]}}}`, out.String())
 }
+
+func TestResolvable_AppendInputError(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"user":{"name":true}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+
+	res.AppendInputError([]string{"input", "oneOf"}, "Exactly one key must be specified for input object 'UserInput'.")
+
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path:     []string{"user"},
+					Nullable: true,
+					Fields: []*Field{
+						{
+							Name: []byte("name"),
+							Value: &String{
+								Path: []string{"name"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"errors":[{"message":"Exactly one key must be specified for input object 'UserInput'.","path":["input","oneOf"]},{"message":"String cannot represent non-string value: \"true\"","path":["user","name"]}],"data":{"user":null}}`, out.String())
+}
+
+func TestResolvable_ResolvedData(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"user":{"id":"1","name":"Jens"}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{
+							Name: []byte("name"),
+							Value: &String{
+								Path: []string{"name"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"user":{"name":"Jens"}}}`, out.String())
+
+	storage, dataRef := res.ResolvedData()
+	assert.NotEqual(t, astjson.InvalidRef, dataRef)
+
+	user := storage.GetObjectField(dataRef, "user")
+	storage.SetObjectField(user, storage.AppendString("Jannik"), "name")
+	storage.SetObjectField(user, storage.AppendString("admin"), "role")
+
+	out.Reset()
+	err = storage.PrintNode(storage.Nodes[dataRef], out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"user":{"name":"Jannik","role":"admin"}}`, out.String())
+}
+
+func TestResolvable_DebugExtensions(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("hello"),
+				Value: &String{
+					Path: []string{"hello"},
+				},
+			},
+		},
+	}
+	extensions := []ResponseExtension{
+		{
+			Key: "region",
+			Render: func(ctx *Context, out io.Writer) error {
+				_, err := out.Write([]byte(`"eu-central-1"`))
+				return err
+			},
+		},
+		{
+			Key:       "planner",
+			DebugOnly: true,
+			Render: func(ctx *Context, out io.Writer) error {
+				_, err := out.Write([]byte(`{"cacheHit":true}`))
+				return err
+			},
+		},
+	}
+
+	resolve := func(t *testing.T, ctx *Context) string {
+		res := NewResolvable()
+		err := res.Init(ctx, []byte(`{"hello":"world"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("debug extensions disabled", func(t *testing.T) {
+		ctx := NewContext(context.Background())
+		ctx.ResponseExtensions = extensions
+		assert.Equal(t, `{"data":{"hello":"world"},"extensions":{"region":"eu-central-1"}}`, resolve(t, ctx))
+	})
+	t.Run("debug extensions enabled", func(t *testing.T) {
+		ctx := NewContext(context.Background())
+		ctx.ResponseExtensions = extensions
+		ctx.DebugExtensions = true
+		assert.Equal(t, `{"data":{"hello":"world"},"extensions":{"region":"eu-central-1","planner":{"cacheHit":true}}}`, resolve(t, ctx))
+	})
+	t.Run("only debug extensions", func(t *testing.T) {
+		ctx := NewContext(context.Background())
+		ctx.ResponseExtensions = extensions[1:]
+		assert.Equal(t, `{"data":{"hello":"world"}}`, resolve(t, ctx))
+	})
+}
+
+func TestResolvable_NullDataOnErrors(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path:     []string{"user"},
+					Nullable: true,
+					Fields: []*Field{
+						{
+							Name: []byte("name"),
+							Value: &String{
+								Path: []string{"name"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	postProcessing := PostProcessingConfiguration{
+		SelectResponseDataPath:   []string{"data"},
+		SelectResponseErrorsPath: []string{"errors"},
+	}
+
+	resolve := func(t *testing.T, ctx *Context, data string) (string, bool) {
+		res := NewResolvable()
+		err := res.InitSubscription(ctx, []byte(data), postProcessing)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String(), res.WroteErrorsWithoutData()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx := NewContext(context.Background())
+		out, errorsWithoutData := resolve(t, ctx, `{"errors":[{"message":"boom"}]}`)
+		assert.Equal(t, `{"errors":[{"message":"boom"}],"data":{"user":null}}`, out)
+		assert.False(t, errorsWithoutData)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		ctx := NewContext(context.Background())
+		ctx.NullDataOnErrors = true
+		out, errorsWithoutData := resolve(t, ctx, `{"errors":[{"message":"boom"}]}`)
+		assert.Equal(t, `{"errors":[{"message":"boom"}],"data":null}`, out)
+		assert.True(t, errorsWithoutData)
+	})
+	t.Run("enabled with partial data", func(t *testing.T) {
+		ctx := NewContext(context.Background())
+		ctx.NullDataOnErrors = true
+		out, errorsWithoutData := resolve(t, ctx, `{"data":{"user":{"name":"Jens"}},"errors":[{"message":"boom"}]}`)
+		assert.Equal(t, `{"errors":[{"message":"boom"}],"data":{"user":{"name":"Jens"}}}`, out)
+		assert.False(t, errorsWithoutData)
+	})
+}
+
+func TestResolvable_AuthorizationCacheStats(t *testing.T) {
+	authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+		if coordinate.FieldName == "email" {
+			return &AuthorizationDeny{Reason: "missing scope"}, nil
+		}
+		return nil, nil
+	})
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.SetAuthorizer(authorizer)
+	err := res.Init(ctx, []byte(`{"user":{"__typename":"User","id":"1"}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+
+	user := res.storage.Get(res.dataRoot, []string{"user"})
+	name := GraphCoordinate{TypeName: "User", FieldName: "name"}
+	email := GraphCoordinate{TypeName: "User", FieldName: "email"}
+
+	for i := 0; i < 3; i++ {
+		result, err := res.authorize(user, "users", name)
+		assert.NoError(t, err)
+		assert.Nil(t, result)
+	}
+	for i := 0; i < 2; i++ {
+		result, err := res.authorize(user, "users", email)
+		assert.NoError(t, err)
+		assert.Equal(t, &AuthorizationDeny{Reason: "missing scope"}, result)
+	}
+
+	assert.Equal(t, AuthorizationCacheStats{AllowHits: 2, DenyHits: 1, Misses: 2}, res.AuthorizationCacheStats())
+	assert.Equal(t, int64(2), authorizer.(*testAuthorizer).objectFieldCalls.Load())
+
+	res.Reset()
+	assert.Equal(t, AuthorizationCacheStats{}, res.AuthorizationCacheStats())
+}
+
+type maskingAuthorizer struct {
+	*testAuthorizer
+	transformCalls int
+}
+
+func (m *maskingAuthorizer) TransformObjectField(ctx *Context, dataSourceID string, value json.RawMessage, coordinate GraphCoordinate) (replacement json.RawMessage, err error) {
+	m.transformCalls++
+	if coordinate.FieldName != "creditCard" {
+		return nil, nil
+	}
+	var number string
+	if err := json.Unmarshal(value, &number); err != nil {
+		return nil, err
+	}
+	return json.Marshal("****" + number[len(number)-4:])
+}
+
+func TestResolvable_AuthorizationTransformer(t *testing.T) {
+	authorizer := &maskingAuthorizer{
+		testAuthorizer: createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+			return nil, nil
+		}).(*testAuthorizer),
+	}
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.SetAuthorizer(authorizer)
+	err := res.Init(ctx, []byte(`{"user":{"__typename":"User","name":"Jens","creditCard":"4111111111111111"}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+
+	info := func(fieldName string) *FieldInfo {
+		return &FieldInfo{
+			Name:                 fieldName,
+			ExactParentTypeName:  "User",
+			Source:               TypeFieldSource{IDs: []string{"users"}},
+			HasAuthorizationRule: true,
+		}
+	}
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{
+							Name: []byte("name"),
+							Value: &String{
+								Path: []string{"name"},
+							},
+							Info: info("name"),
+						},
+						{
+							Name: []byte("creditCard"),
+							Value: &String{
+								Path: []string{"creditCard"},
+							},
+							Info: info("creditCard"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"user":{"name":"Jens","creditCard":"****1111"}}}`, out.String())
+	assert.Equal(t, 2, authorizer.transformCalls)
+}
+
+type redactingAuthorizer struct {
+	*testAuthorizer
+	redactPaths map[string][][]string
+}
+
+func (r *redactingAuthorizer) RedactObjectField(ctx *Context, dataSourceID string, value json.RawMessage, coordinate GraphCoordinate) (redactPaths [][]string, err error) {
+	return r.redactPaths[coordinate.FieldName], nil
+}
+
+func TestResolvable_AuthorizationRedactor(t *testing.T) {
+	authorizer := &redactingAuthorizer{
+		testAuthorizer: createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+			return nil, nil
+		}).(*testAuthorizer),
+		redactPaths: map[string][][]string{
+			"user": {{"address", "street"}, {"cards", "[0]"}, {"unknown"}},
+		},
+	}
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.SetAuthorizer(authorizer)
+	err := res.Init(ctx, []byte(`{"user":{"__typename":"User","name":"Jens","address":{"street":"Main St","city":"Berlin"},"cards":["4111","5555"]}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{
+							Name: []byte("address"),
+							Value: &Object{
+								Path: []string{"address"},
+								Fields: []*Field{
+									{Name: []byte("street"), Value: &String{Path: []string{"street"}, Nullable: true}},
+									{Name: []byte("city"), Value: &String{Path: []string{"city"}}},
+								},
+							},
+						},
+						{Name: []byte("cards"), Value: &Array{Path: []string{"cards"}, Item: &String{Nullable: true}}},
+					},
+				},
+				Info: &FieldInfo{
+					Name:                 "user",
+					ExactParentTypeName:  "Query",
+					Source:               TypeFieldSource{IDs: []string{"users"}},
+					HasAuthorizationRule: true,
+				},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"user":{"name":"Jens","address":{"street":null,"city":"Berlin"},"cards":[null,"5555"]}}}`, out.String())
+}
+
+func TestResolvable_PassThrough(t *testing.T) {
+	data := `{"user":{"__typename":"User","id":"1","name":"Jens","age":33,"score":1.5,"admin":false,"tags":["a","b"],"meta":{"a":[1,2]},"friends":[{"__typename":"User","name":"Stefan","age":null},{"__typename":"User","name":true}],"pets":[{"__typename":"Cat","name":"Mietze","lives":7},{"__typename":"Dog","name":"Bello","barks":true}]}}`
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("__typename"), Value: &String{Path: []string{"__typename"}, IsTypeName: true}},
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("age"), Value: &Integer{Path: []string{"age"}}},
+						{Name: []byte("score"), Value: &Float{Path: []string{"score"}}},
+						{Name: []byte("admin"), Value: &Boolean{Path: []string{"admin"}}},
+						{Name: []byte("missing"), Value: &String{Path: []string{"missing"}, Nullable: true}},
+						{Name: []byte("tags"), Value: &Array{Path: []string{"tags"}, Item: &String{}}},
+						{Name: []byte("meta"), Value: &Scalar{Path: []string{"meta"}}},
+						{Name: []byte("empty"), Value: &EmptyObject{}},
+						{Name: []byte("skipped"), SkipDirectiveDefined: true, SkipVariableName: "skip", Value: &String{Path: []string{"name"}}},
+						{
+							Name: []byte("friends"),
+							Value: &Array{
+								Path: []string{"friends"},
+								Item: &Object{
+									Nullable: true,
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+										{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+									},
+								},
+							},
+						},
+						{
+							Name: []byte("pets"),
+							Value: &Array{
+								Path: []string{"pets"},
+								Item: &Object{
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+										{Name: []byte("lives"), Value: &Integer{Path: []string{"lives"}}, OnTypeNames: [][]byte{[]byte("Cat")}},
+										{Name: []byte("barks"), Value: &Boolean{Path: []string{"barks"}}, OnTypeNames: [][]byte{[]byte("Dog")}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resolve := func(t *testing.T, ctx *Context) (string, *Resolvable) {
+		res := NewResolvable()
+		ctx.Variables = []byte(`{"skip":true}`)
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String(), res
+	}
+
+	passThroughCtx := NewContext(context.Background())
+	passThrough, res := resolve(t, passThroughCtx)
+	assert.NotNil(t, res.passThroughRoot)
+
+	walkCtx := NewContext(context.Background())
+	// a type name rename requires the full walk
+	walkCtx.RenameTypeNames = []RenameTypeName{{From: []byte("Unknown"), To: []byte("Other")}}
+	walk, walkRes := resolve(t, walkCtx)
+	assert.Nil(t, walkRes.passThroughRoot)
+
+	expected := `{"errors":[{"message":"String cannot represent non-string value: \"true\"","path":["user","friends",1,"name"]}],"data":{"user":{"__typename":"User","name":"Jens","age":33,"score":1.5,"admin":false,"missing":null,"tags":["a","b"],"meta":{"a":[1,2]},"empty":{},"friends":[{"name":"Stefan","age":null},null],"pets":[{"name":"Mietze","lives":7},{"name":"Bello","barks":true}]}}}`
+	assert.Equal(t, expected, passThrough)
+	assert.Equal(t, expected, walk)
+	assert.Equal(t, walkCtx.Stats.ResolvedObjects, passThroughCtx.Stats.ResolvedObjects)
+
+	storage, dataRef := res.ResolvedData()
+	out := &bytes.Buffer{}
+	assert.NoError(t, storage.PrintNode(storage.Nodes[dataRef], out))
+	assert.Equal(t, `{"user":{"__typename":"User","name":"Jens","age":33,"score":1.5,"admin":false,"missing":null,"tags":["a","b"],"meta":{"a":[1,2]},"empty":{},"friends":[{"name":"Stefan","age":null},null],"pets":[{"name":"Mietze","lives":7},{"name":"Bello","barks":true}]}}`, out.String())
+}
+
+// TestResolvable_PassThroughOptions resolves the same data with each option of the Context, Field and Object
+// using the pass-through fast path, if eligible, and the full walk, which must print the same response.
+// Every exported field of these types must be listed, so that new options are compared as well.
+func TestResolvable_PassThroughOptions(t *testing.T) {
+	data := `{"user":{"__typename":"User","id":9007199254740993,"name":"Jöns","admin":true,"score":1.5,"nickname":null,"tags":["a","b","c"],"friends":[{"__typename":"User","name":"Stefan","admin":false}]},"version":"v1"}`
+	info := func(name string, deprecated bool) *FieldInfo {
+		return &FieldInfo{
+			Name:                name,
+			ExactParentTypeName: "User",
+			Source:              TypeFieldSource{IDs: []string{"users"}},
+			IsDeprecated:        deprecated,
+		}
+	}
+	// the nickname field and the user object are configured with the Field and Object options
+	newObject := func() (object *Object, nickname *Field, user *Object) {
+		nickname = &Field{Name: []byte("nickname"), Value: &String{Path: []string{"nickname"}, Nullable: true}, Info: info("nickname", true)}
+		user = &Object{
+			Path:     []string{"user"},
+			Nullable: true,
+			Fields: []*Field{
+				{Name: []byte("__typename"), Value: &String{Path: []string{"__typename"}, IsTypeName: true}, Info: info("__typename", false)},
+				{Name: []byte("id"), Value: &BigInt{Path: []string{"id"}}, Info: info("id", false)},
+				{Name: []byte("name"), Value: &String{Path: []string{"name"}}, Info: info("name", false)},
+				{Name: []byte("admin"), Value: &Boolean{Path: []string{"admin"}}, Info: info("admin", false)},
+				{Name: []byte("score"), Value: &Float{Path: []string{"score"}}, Info: info("score", false)},
+				nickname,
+				{Name: []byte("missing"), Value: &String{Path: []string{"missing"}, Nullable: true}, Info: info("missing", false)},
+				{Name: []byte("tags"), Value: &Array{Path: []string{"tags"}, Item: &String{}}, Info: info("tags", false)},
+				{
+					Name: []byte("friends"),
+					Value: &Array{
+						Path: []string{"friends"},
+						Item: &Object{
+							Fields: []*Field{
+								{Name: []byte("__typename"), Value: &String{Path: []string{"__typename"}, IsTypeName: true}},
+								{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+								{Name: []byte("admin"), Value: &Boolean{Path: []string{"admin"}}},
+							},
+						},
+					},
+					Info: info("friends", false),
+				},
+			},
+		}
+		object = &Object{
+			Fields: []*Field{
+				{Name: []byte("user"), Value: user},
+				{Name: []byte("version"), Value: &String{Path: []string{"version"}}},
+			},
+		}
+		return object, nickname, user
+	}
+	contextOptions := map[string]func(ctx *Context){
+		// options without effect on the printed data are listed as nil
+		"Variables": nil,
+		"Request":   func(ctx *Context) { ctx.Request.OperationName = "Me" },
+		"RenameTypeNames": func(ctx *Context) {
+			ctx.RenameTypeNames = []RenameTypeName{{From: []byte("User"), To: []byte("Account")}}
+		},
+		"TracingOptions":   func(ctx *Context) { ctx.TracingOptions.Enable = true },
+		"RateLimitOptions": func(ctx *Context) { ctx.RateLimitOptions.Enable = true },
+		"InitialPayload":   nil,
+		"Extensions":       nil,
+		"Stats":            nil,
+		"LoaderHooks":      nil,
+		"ResponseExtensions": func(ctx *Context) {
+			ctx.ResponseExtensions = []ResponseExtension{{Key: "custom", Render: func(ctx *Context, out io.Writer) error {
+				_, err := out.Write([]byte(`true`))
+				return err
+			}}}
+		},
+		"DebugExtensions":              func(ctx *Context) { ctx.DebugExtensions = true },
+		"NullDataOnErrors":             func(ctx *Context) { ctx.NullDataOnErrors = true },
+		"SortErrorsByPath":             func(ctx *Context) { ctx.SortErrorsByPath = true },
+		"NDJSONOutput":                 func(ctx *Context) { ctx.NDJSONOutput = true },
+		"SoftErrorExtension":           func(ctx *Context) { ctx.SoftErrorExtension = true },
+		"SuppressSoftErrorsTopLevel":   func(ctx *Context) { ctx.SuppressSoftErrorsTopLevel = true },
+		"SynthesizeEmptyResponseError": func(ctx *Context) { ctx.SynthesizeEmptyResponseError = true },
+		"PrefixSubgraphErrors":         func(ctx *Context) { ctx.PrefixSubgraphErrors = true },
+		"IncludeRetryableFields":       func(ctx *Context) { ctx.IncludeRetryableFields = true },
+		"NodeEncoder":                  func(ctx *Context) { ctx.NodeEncoder = callNodeEncoder{} },
+		"NonFiniteFloatMode":           func(ctx *Context) { ctx.NonFiniteFloatMode = NonFiniteFloatModeNull },
+		"NullMode":                     func(ctx *Context) { ctx.NullMode = NullModeOmitKey },
+		"TypeTransformers": func(ctx *Context) {
+			ctx.TypeTransformers = map[string]func(objectData []byte) ([]byte, error){
+				"User": func(objectData []byte) ([]byte, error) {
+					return []byte(`{"name":"transformed"}`), nil
+				},
+			}
+		},
+		"DetectDuplicateKeys":             func(ctx *Context) { ctx.DetectDuplicateKeys = true },
+		"TrustedDataSourceIDs":            func(ctx *Context) { ctx.TrustedDataSourceIDs = map[string]struct{}{"users": {}} },
+		"DefaultDeny":                     func(ctx *Context) { ctx.DefaultDeny = true },
+		"DefaultDenyExemptMetaFields":     func(ctx *Context) { ctx.DefaultDeny, ctx.DefaultDenyExemptMetaFields = true, true },
+		"OnResolveObject":                 func(ctx *Context) { ctx.OnResolveObject = func(typeName string, path string) {} },
+		"IncludeOperationNameInErrorPath": func(ctx *Context) { ctx.IncludeOperationNameInErrorPath = true },
+		"ErrorPathSeparator":              func(ctx *Context) { ctx.ErrorPathSeparator = "/" },
+		"RootTypeName":                    func(ctx *Context) { ctx.RootTypeName = "Query" },
+		"SimplifySingleError":             func(ctx *Context) { ctx.SimplifySingleError = true },
+		"ParallelRootFields":              func(ctx *Context) { ctx.ParallelRootFields = true },
+		"FailFast":                        func(ctx *Context) { ctx.FailFast = true },
+		"IncludeErrorCategory":            func(ctx *Context) { ctx.IncludeErrorCategory = true },
+		"OnDeprecatedFieldUsed":           func(ctx *Context) { ctx.OnDeprecatedFieldUsed = func(coordinate GraphCoordinate) {} },
+		"ASCIIOnlyStrings":                func(ctx *Context) { ctx.ASCIIOnlyStrings = true },
+		"FeatureFlags":                    func(ctx *Context) { ctx.FeatureFlags = map[string]bool{"beta": true} },
+		"IncludeUnknownFeatureFlags":      func(ctx *Context) { ctx.IncludeUnknownFeatureFlags = true },
+		"IncludeDataPresentExtension":     func(ctx *Context) { ctx.IncludeDataPresentExtension = true },
+		"OneBasedArrayIndices":            func(ctx *Context) { ctx.OneBasedArrayIndices = true },
+		"LargeIntAsString":                func(ctx *Context) { ctx.LargeIntAsString = true },
+		"LargeIntThreshold":               func(ctx *Context) { ctx.LargeIntAsString, ctx.LargeIntThreshold = true, 1 },
+		"BooleanAsInt":                    func(ctx *Context) { ctx.BooleanAsInt = true },
+		"FloatFormatter":                  func(ctx *Context) { ctx.FloatFormatter = func(raw []byte) []byte { return []byte("1.50") } },
+		"StrictExtraFields":               func(ctx *Context) { ctx.StrictExtraFields = true },
+		"LazyCustomNodes":                 func(ctx *Context) { ctx.LazyCustomNodes = true },
+		"MaxArrayItems":                   func(ctx *Context) { ctx.MaxArrayItems = 1 },
+		"MaxObjectFields":                 func(ctx *Context) { ctx.MaxObjectFields = 2 },
+		"StrictTypeName":                  func(ctx *Context) { ctx.StrictTypeName = true },
+		"BeforeDataWalk":                  func(ctx *Context) { ctx.BeforeDataWalk = func() {} },
+		"AfterDataWalk":                   func(ctx *Context) { ctx.AfterDataWalk = func(hadError bool) {} },
+		"OnNullBubble":                    func(ctx *Context) { ctx.OnNullBubble = func(path string, reason string) {} },
+		"IncrementalEnvelope":             func(ctx *Context) { ctx.IncrementalEnvelope = true },
+		"FieldPresenceCollector":          func(ctx *Context) { ctx.FieldPresenceCollector = NewFieldPresenceCollector() },
+		"ErrorSink":                       func(ctx *Context) { ctx.ErrorSink = func(err GraphQLError) {} },
+		"NonNullFallback": func(ctx *Context) {
+			ctx.NonNullFallback = func(path string, node Node) ([]byte, bool) { return nil, false }
+		},
+		"TraceFieldSources":           func(ctx *Context) { ctx.TraceFieldSources = true },
+		"IncludeFieldStatesExtension": func(ctx *Context) { ctx.IncludeFieldStatesExtension = true },
+		"IncludeCacheStatus":          func(ctx *Context) { ctx.IncludeCacheStatus = true },
+		"UniqueErrorPaths":            func(ctx *Context) { ctx.UniqueErrorPaths = true },
+		"MaskInternalErrors":          func(ctx *Context) { ctx.MaskInternalErrors = true },
+		"TypeNameRewriter": func(ctx *Context) {
+			ctx.TypeNameRewriter = func(original []byte, coordinate GraphCoordinate) []byte { return []byte("Person") }
+		},
+		"ConsolidateAuthDenials": func(ctx *Context) { ctx.ConsolidateAuthDenials = true },
+		"IntrospectionData": func(ctx *Context) {
+			ctx.IntrospectionData = &IntrospectionData{Data: []byte(`{"__schema":{"queryType":{"name":"Query"}}}`)}
+		},
+	}
+	fieldOptions := map[string]func(field *Field){
+		"Name":                    nil,
+		"Value":                   nil,
+		"Position":                nil,
+		"Defer":                   nil,
+		"Stream":                  nil,
+		"OnTypeNames":             func(field *Field) { field.OnTypeNames = [][]byte{[]byte("Admin")} },
+		"SkipDirectiveDefined":    func(field *Field) { field.SkipDirectiveDefined, field.SkipVariableName = true, "skip" },
+		"SkipVariableName":        func(field *Field) { field.SkipDirectiveDefined, field.SkipVariableName = true, "include" },
+		"IncludeDirectiveDefined": func(field *Field) { field.IncludeDirectiveDefined, field.IncludeVariableName = true, "include" },
+		"IncludeVariableName":     func(field *Field) { field.IncludeDirectiveDefined, field.IncludeVariableName = true, "skip" },
+		"Info":                    func(field *Field) { field.Info = nil },
+		"FeatureFlag":             func(field *Field) { field.FeatureFlag = "beta" },
+		"RequirePresence":         func(field *Field) { field.RequirePresence = true },
+		"CacheControl":            func(field *Field) { field.CacheControl = &CacheControl{MaxAge: 60} },
+		"NullPlaceholder":         func(field *Field) { field.NullPlaceholder = []byte(`""`) },
+		"RequiredVariables":       func(field *Field) { field.RequiredVariables = []string{"missing"} },
+	}
+	objectOptions := map[string]func(obj *Object){
+		"Nullable":    func(obj *Object) { obj.Nullable = false },
+		"Path":        nil,
+		"Fields":      nil,
+		"Fetch":       nil,
+		"FlattenInto": func(obj *Object) { obj.FlattenInto = true },
+		"TypeDiscriminator": func(obj *Object) {
+			obj.TypeDiscriminator = func(objectData []byte) string { return "Admin" }
+		},
+	}
+	for _, options := range []struct {
+		value  any
+		listed func(name string) bool
+	}{
+		{value: Context{}, listed: func(name string) bool { _, ok := contextOptions[name]; return ok }},
+		{value: Field{}, listed: func(name string) bool { _, ok := fieldOptions[name]; return ok }},
+		{value: Object{}, listed: func(name string) bool { _, ok := objectOptions[name]; return ok }},
+	} {
+		optionsType := reflect.TypeOf(options.value)
+		for i := 0; i < optionsType.NumField(); i++ {
+			if field := optionsType.Field(i); field.IsExported() {
+				assert.True(t, options.listed(field.Name), "%s.%s is not compared", optionsType.Name(), field.Name)
+			}
+		}
+	}
+
+	type testCase struct {
+		name      string
+		configure func(ctx *Context, nickname *Field, user *Object)
+	}
+	var testCases []testCase
+	for name, configure := range contextOptions {
+		configure := configure
+		if configure != nil {
+			testCases = append(testCases, testCase{name: "Context." + name, configure: func(ctx *Context, nickname *Field, user *Object) { configure(ctx) }})
+		}
+	}
+	for name, configure := range fieldOptions {
+		configure := configure
+		if configure != nil {
+			testCases = append(testCases, testCase{name: "Field." + name, configure: func(ctx *Context, nickname *Field, user *Object) { configure(nickname) }})
+		}
+	}
+	for name, configure := range objectOptions {
+		configure := configure
+		if configure != nil {
+			testCases = append(testCases, testCase{name: "Object." + name, configure: func(ctx *Context, nickname *Field, user *Object) { configure(user) }})
+		}
+	}
+
+	resolve := func(t *testing.T, ctx *Context, object *Object) (string, *Resolvable) {
+		res := NewResolvable()
+		ctx.Variables = []byte(`{"skip":true,"include":false}`)
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String(), res
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			passThroughCtx := NewContext(context.Background())
+			object, nickname, user := newObject()
+			tc.configure(passThroughCtx, nickname, user)
+			passThrough, _ := resolve(t, passThroughCtx, object)
+
+			walkCtx := NewContext(context.Background())
+			object, nickname, user = newObject()
+			tc.configure(walkCtx, nickname, user)
+			// OnResolveObject requires the full walk
+			walkCtx.OnResolveObject = func(typeName string, path string) {}
+			walk, walkRes := resolve(t, walkCtx, object)
+			assert.Nil(t, walkRes.passThroughRoot)
+
+			assert.Equal(t, walk, passThrough)
+			assert.Equal(t, walkCtx.Stats.ResolvedNodes, passThroughCtx.Stats.ResolvedNodes)
+			assert.Equal(t, walkCtx.Stats.ResolvedObjects, passThroughCtx.Stats.ResolvedObjects)
+		})
+	}
+}
+
+func BenchmarkResolvable_PassThrough(b *testing.B) {
+	data := &bytes.Buffer{}
+	fields := make([]*Field, 0, 8)
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("field%d", i)
+		fields = append(fields, &Field{Name: []byte(name), Value: &String{Path: []string{name}}})
+	}
+	data.WriteString(`{"items":[`)
+	for i := 0; i < 1024; i++ {
+		if i != 0 {
+			data.WriteString(",")
+		}
+		data.WriteString("{")
+		for j := range fields {
+			if j != 0 {
+				data.WriteString(",")
+			}
+			data.WriteString(fmt.Sprintf(`"%s":"value %d"`, fields[j].Name, i))
+		}
+		data.WriteString("}")
+	}
+	data.WriteString(`]}`)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name:  []byte("items"),
+				Value: &Array{Path: []string{"items"}, Item: &Object{Fields: fields}},
+			},
+		},
+	}
+
+	run := func(b *testing.B, renameTypeNames []RenameTypeName) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.RenameTypeNames = renameTypeNames
+		out := &bytes.Buffer{}
+		b.ReportAllocs()
+		b.SetBytes(int64(data.Len()))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			res.Reset()
+			out.Reset()
+			if err := res.Init(ctx, data.Bytes(), ast.OperationTypeQuery); err != nil {
+				b.Fatal(err)
+			}
+			if err := res.Resolve(context.Background(), object, nil, out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("walk", func(b *testing.B) {
+		run(b, []RenameTypeName{{From: []byte("Unknown"), To: []byte("Other")}})
+	})
+	b.Run("pass-through", func(b *testing.B) {
+		run(b, nil)
+	})
+}
+
+func TestResolvable_IntrospectionData(t *testing.T) {
+	resolve := func(t *testing.T, data string, introspection *IntrospectionData, object *Object) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.IntrospectionData = introspection
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("merge __schema into the data root", func(t *testing.T) {
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+				{
+					Name: []byte("__schema"),
+					Value: &Object{
+						Path: []string{"__schema"},
+						Fields: []*Field{
+							{
+								Name: []byte("queryType"),
+								Value: &Object{
+									Path: []string{"queryType"},
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		out := resolve(t, `{"user":{"name":"Jens"}}`, &IntrospectionData{
+			Data: []byte(`{"__schema":{"queryType":{"name":"Query"}}}`),
+		}, object)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens"},"__schema":{"queryType":{"name":"Query"}}}}`, out)
+	})
+	t.Run("merge at path with conflicts", func(t *testing.T) {
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("node"),
+					Value: &Object{
+						Path: []string{"node"},
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+							{
+								Name: []byte("meta"),
+								Value: &Object{
+									Path: []string{"meta"},
+									Fields: []*Field{
+										{Name: []byte("a"), Value: &String{Path: []string{"a"}}},
+										{Name: []byte("b"), Value: &String{Path: []string{"b"}}},
+									},
+								},
+							},
+							{
+								Name: []byte("__type"),
+								Value: &Object{
+									Path: []string{"__type"},
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		out := resolve(t, `{"node":{"id":"1","meta":{"a":"data"},"__type":{"name":"Stale"}}}`, &IntrospectionData{
+			Data:      []byte(`{"id":"2","meta":{"a":"introspection","b":"introspection"},"__type":{"name":"User"}}`),
+			MergePath: []string{"node"},
+		}, object)
+		assert.Equal(t, `{"data":{"node":{"id":"1","meta":{"a":"data","b":"introspection"},"__type":{"name":"User"}}}}`, out)
+	})
+	t.Run("merge at missing path", func(t *testing.T) {
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("node"),
+					Value: &Object{
+						Path: []string{"node"},
+						Fields: []*Field{
+							{
+								Name: []byte("__type"),
+								Value: &Object{
+									Path: []string{"__type"},
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		out := resolve(t, `{}`, &IntrospectionData{
+			Data:      []byte(`{"__type":{"name":"User"}}`),
+			MergePath: []string{"node"},
+		}, object)
+		assert.Equal(t, `{"data":{"node":{"__type":{"name":"User"}}}}`, out)
+	})
+}
+
+type scalarKindValidator struct{}
+
+func (scalarKindValidator) ValidateScalar(_ *Context, value []byte) error {
+	switch value[0] {
+	case '{', '[':
+		return fmt.Errorf("JSON scalar item must be a primitive value")
+	}
+	return nil
+}
+
+func TestResolvable_ScalarValidator(t *testing.T) {
+	resolve := func(t *testing.T, item *Scalar) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(`{"values":[1,"a",{"b":true},false,[2]]}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("values"),
+					Value: &Array{
+						Path:     []string{"values"},
+						Nullable: true,
+						Item:     item,
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("nullable items", func(t *testing.T) {
+		out := resolve(t, &Scalar{Nullable: true, Validator: scalarKindValidator{}})
+		assert.Equal(t, `{"errors":[{"message":"JSON scalar item must be a primitive value","path":["values",2]},{"message":"JSON scalar item must be a primitive value","path":["values",4]}],"data":{"values":[1,"a",null,false,null]}}`, out)
+	})
+	t.Run("non-nullable items", func(t *testing.T) {
+		out := resolve(t, &Scalar{Validator: scalarKindValidator{}})
+		assert.Equal(t, `{"errors":[{"message":"JSON scalar item must be a primitive value","path":["values",2]}],"data":{"values":null}}`, out)
+	})
+	t.Run("without validator", func(t *testing.T) {
+		out := resolve(t, &Scalar{})
+		assert.Equal(t, `{"data":{"values":[1,"a",{"b":true},false,[2]]}}`, out)
+	})
+}
+
+func TestResolvable_SortErrorsByPath(t *testing.T) {
+	resolve := func(t *testing.T, sortErrors bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SortErrorsByPath = sortErrors
+		err := res.Init(ctx, []byte(`{"z":{"name":1},"a":{"name":true},"list":[1,2,{},4,5,6,7,8,9,10,[]]}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		res.AppendInputError([]string{"a"}, "input error")
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("z"),
+					Value: &Object{
+						Path:     []string{"z"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+				{
+					Name: []byte("a"),
+					Value: &Object{
+						Path:     []string{"a"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+				{
+					Name: []byte("list"),
+					Value: &Array{
+						Path:     []string{"list"},
+						Nullable: true,
+						Item:     &Scalar{Nullable: true, Validator: scalarKindValidator{}},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("walk order", func(t *testing.T) {
+		out := resolve(t, false)
+		assert.Equal(t, `{"errors":[{"message":"input error","path":["a"]},{"message":"String cannot represent non-string value: \"1\"","path":["z","name"]},{"message":"String cannot represent non-string value: \"true\"","path":["a","name"]},{"message":"JSON scalar item must be a primitive value","path":["list",2]},{"message":"JSON scalar item must be a primitive value","path":["list",10]}],"data":{"z":null,"a":null,"list":[1,2,null,4,5,6,7,8,9,10,null]}}`, out)
+	})
+	t.Run("sorted by path", func(t *testing.T) {
+		expected := `{"errors":[{"message":"input error","path":["a"]},{"message":"String cannot represent non-string value: \"true\"","path":["a","name"]},{"message":"JSON scalar item must be a primitive value","path":["list",2]},{"message":"JSON scalar item must be a primitive value","path":["list",10]},{"message":"String cannot represent non-string value: \"1\"","path":["z","name"]}],"data":{"z":null,"a":null,"list":[1,2,null,4,5,6,7,8,9,10,null]}}`
+		assert.Equal(t, expected, resolve(t, true))
+		// the order is stable across runs
+		assert.Equal(t, expected, resolve(t, true))
+	})
+}
+
+func TestResolvable_Warnings(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"user":{"name":"Jens","age":"unknown"}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	res.AppendWarning([]string{"user", "name"}, "Field is deprecated")
+	res.AppendWarning(nil, "Value was truncated")
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path:     []string{"user"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+					},
+				},
+			},
+			{
+				Name: []byte("other"),
+				Value: &Object{
+					Path:     []string{"other"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"errors":[{"message":"Int cannot represent non-integer value: \"unknown\"","path":["user","age"]}],"data":{"user":null,"other":null},"extensions":{"warnings":[{"message":"Field is deprecated","path":["user","name"]},{"message":"Value was truncated","path":[]}]}}`, out.String())
+
+	t.Run("warnings only", func(t *testing.T) {
+		res.Reset()
+		err := res.Init(ctx, []byte(`{"user":{"name":"Jens"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		res.AppendWarning([]string{"user", "name"}, "Field is deprecated")
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+			},
+		}, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens"}},"extensions":{"warnings":[{"message":"Field is deprecated","path":["user","name"]}]}}`, out.String())
+	})
+}
+
+func TestResolvable_SharedStorage(t *testing.T) {
+	storage := &astjson.JSON{}
+	dataRoot, err := storage.AppendObject([]byte(`{"user":{"id":"1","name":"Jens","address":{"city":"Berlin"}}}`))
+	assert.NoError(t, err)
+	nodes := len(storage.Nodes)
+
+	resolve := func(t *testing.T, object *Object) string {
+		res := NewResolvableWithStorage(storage)
+		ctx := NewContext(context.Background())
+		err := res.InitWithDataRoot(ctx, dataRoot, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		res.Reset()
+		return out.String()
+	}
+
+	names := resolve(t, &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+					},
+				},
+			},
+		},
+	})
+	assert.Equal(t, `{"data":{"user":{"name":"Jens"}}}`, names)
+
+	addresses := resolve(t, &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("me"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+						{Name: []byte("city"), Value: &String{Path: []string{"address", "city"}}},
+					},
+				},
+			},
+		},
+	})
+	assert.Equal(t, `{"data":{"me":{"id":"1","city":"Berlin"}}}`, addresses)
+
+	// the parsed document is still available after Reset of the Resolvables
+	assert.True(t, len(storage.Nodes) > nodes)
+	assert.Equal(t, `{"user":{"id":"1","name":"Jens","address":{"city":"Berlin"}}}`, storage.DebugPrintNode(dataRoot))
+}
+
+func TestResolvable_FlattenInto(t *testing.T) {
+	resolve := func(t *testing.T, data string, object *Object) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("single level", func(t *testing.T) {
+		out := resolve(t, `{"user":{"node":{"id":"1","name":"Jens"}}}`, &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{
+								Name: []byte("node"),
+								Value: &Object{
+									Path:        []string{"node"},
+									FlattenInto: true,
+									Fields: []*Field{
+										{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+		assert.Equal(t, `{"data":{"user":{"id":"1","name":"Jens"}}}`, out)
+	})
+	t.Run("nested", func(t *testing.T) {
+		out := resolve(t, `{"user":{"node":{"id":"1","details":{"name":"Jens"}}}}`, &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{
+								Name: []byte("node"),
+								Value: &Object{
+									Path:        []string{"node"},
+									FlattenInto: true,
+									Fields: []*Field{
+										{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+										{
+											Name: []byte("details"),
+											Value: &Object{
+												Path:        []string{"details"},
+												FlattenInto: true,
+												Fields: []*Field{
+													{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+		assert.Equal(t, `{"data":{"user":{"id":"1","name":"Jens"}}}`, out)
+	})
+	t.Run("key collisions", func(t *testing.T) {
+		out := resolve(t, `{"user":{"id":"parent","name":"parent","node":{"id":"child","name":"child"}}}`, &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+							{
+								Name: []byte("node"),
+								Value: &Object{
+									Path:        []string{"node"},
+									FlattenInto: true,
+									Fields: []*Field{
+										{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+			},
+		})
+		assert.Equal(t, `{"data":{"user":{"id":"child","name":"parent"}}}`, out)
+	})
+	t.Run("null object", func(t *testing.T) {
+		out := resolve(t, `{"user":{"id":"1","node":null}}`, &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+							{
+								Name: []byte("node"),
+								Value: &Object{
+									Path:        []string{"node"},
+									Nullable:    true,
+									FlattenInto: true,
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+		assert.Equal(t, `{"data":{"user":{"id":"1"}}}`, out)
+	})
+}
+
+func TestResolvable_DefaultValue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    func(path string) Node
+		expected string
+	}{
+		{
+			name: "String",
+			value: func(path string) Node {
+				return &String{Path: []string{path}, Nullable: true, DefaultValue: []byte(`""`)}
+			},
+			expected: `""`,
+		},
+		{
+			name: "Boolean",
+			value: func(path string) Node {
+				return &Boolean{Path: []string{path}, Nullable: true, DefaultValue: []byte(`false`)}
+			},
+			expected: `false`,
+		},
+		{
+			name: "Integer",
+			value: func(path string) Node {
+				return &Integer{Path: []string{path}, Nullable: true, DefaultValue: []byte(`0`)}
+			},
+			expected: `0`,
+		},
+		{
+			name: "Float",
+			value: func(path string) Node {
+				return &Float{Path: []string{path}, Nullable: true, DefaultValue: []byte(`0.5`)}
+			},
+			expected: `0.5`,
+		},
+		{
+			name: "BigInt",
+			value: func(path string) Node {
+				return &BigInt{Path: []string{path}, Nullable: true, DefaultValue: []byte(`"0"`)}
+			},
+			expected: `"0"`,
+		},
+		{
+			name: "Scalar",
+			value: func(path string) Node {
+				return &Scalar{Path: []string{path}, Nullable: true, DefaultValue: []byte(`[]`)}
+			},
+			expected: `[]`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			object := &Object{
+				Fields: []*Field{
+					{Name: []byte("missing"), Value: tc.value("missing")},
+					{Name: []byte("explicitNull"), Value: tc.value("explicitNull")},
+				},
+			}
+			expected := fmt.Sprintf(`{"data":{"missing":%s,"explicitNull":null}}`, tc.expected)
+
+			res := NewResolvable()
+			ctx := NewContext(context.Background())
+			err := res.Init(ctx, []byte(`{"explicitNull":null}`), ast.OperationTypeQuery)
+			assert.NoError(t, err)
+			out := &bytes.Buffer{}
+			err = res.Resolve(context.Background(), object, nil, out)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, out.String())
+
+			// a type name rename forces the resolved tree to be built instead of the pass-through printer
+			res.Reset()
+			ctx.RenameTypeNames = []RenameTypeName{{From: []byte("A"), To: []byte("B")}}
+			object.Fields = append(object.Fields, &Field{Name: []byte("__typename"), Value: &String{Path: []string{"__typename"}, Nullable: true, IsTypeName: true}})
+			err = res.Init(ctx, []byte(`{"explicitNull":null}`), ast.OperationTypeQuery)
+			assert.NoError(t, err)
+			out.Reset()
+			err = res.Resolve(context.Background(), object, nil, out)
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf(`{"data":{"missing":%s,"explicitNull":null,"__typename":null}}`, tc.expected), out.String())
+		})
+	}
+}
+
+func TestResolvable_RequirePresence(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("patch"),
+				Value: &Object{
+					Path:     []string{"patch"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}, RequirePresence: true},
+					},
+				},
+			},
+		},
+	}
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "present",
+			data:     `{"patch":{"id":"1","name":"Jens"}}`,
+			expected: `{"data":{"patch":{"id":"1","name":"Jens"}}}`,
+		},
+		{
+			name:     "explicit null",
+			data:     `{"patch":{"id":"1","name":null}}`,
+			expected: `{"data":{"patch":{"id":"1","name":null}}}`,
+		},
+		{
+			name:     "absent",
+			data:     `{"patch":{"id":"1"}}`,
+			expected: `{"errors":[{"message":"Field 'Query.patch.name' must be present in the data.","path":["patch","name"]}],"data":{"patch":{"id":"1","name":null}}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewResolvable()
+			ctx := NewContext(context.Background())
+			err := res.Init(ctx, []byte(tc.data), ast.OperationTypeQuery)
+			assert.NoError(t, err)
+			out := &bytes.Buffer{}
+			err = res.Resolve(context.Background(), object, nil, out)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+
+	t.Run("absent non-nullable field nulls the parent", func(t *testing.T) {
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("patch"),
+					Value: &Object{
+						Path:     []string{"patch"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}, RequirePresence: true},
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}},
+						},
+					},
+				},
+			},
+		}
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(`{"patch":{"name":"Jens"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"Field 'Query.patch.id' must be present in the data.","path":["patch","id"]}],"data":{"patch":null}}`, out.String())
+	})
+}
+
+func TestResolvable_NDJSONOutput(t *testing.T) {
+	resolve := func(t *testing.T, data string, object *Object) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.NDJSONOutput = true
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+	users := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Value: &Array{
+					Path:     []string{"users"},
+					Nullable: true,
+					Item: &Object{
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("list items as lines", func(t *testing.T) {
+		out := resolve(t, `{"users":[{"id":"1","name":"Jens"},{"id":"2","name":"Dustin"},{"id":"3","name":"Stefan"}]}`, users)
+		assert.Equal(t, "{\"id\":\"1\",\"name\":\"Jens\"}\n{\"id\":\"2\",\"name\":\"Dustin\"}\n{\"id\":\"3\",\"name\":\"Stefan\"}\n", out)
+		lines := bytes.Split(bytes.TrimSuffix([]byte(out), []byte("\n")), []byte("\n"))
+		assert.Len(t, lines, 3)
+		for _, line := range lines {
+			assert.True(t, json.Valid(line))
+		}
+	})
+	t.Run("errors as trailing line", func(t *testing.T) {
+		out := resolve(t, `{"users":[{"id":"1","name":"Jens"},{"id":"2"}]}`, users)
+		assert.Equal(t, "{\"id\":\"1\",\"name\":\"Jens\"}\nnull\n{\"errors\":[{\"message\":\"Cannot return null for non-nullable field 'Query.users.name'.\",\"path\":[\"users\",1,\"name\"]}]}\n", out)
+	})
+	t.Run("non-list root falls back to the standard envelope", func(t *testing.T) {
+		out := resolve(t, `{"user":{"id":"1"}}`, &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+						},
+					},
+				},
+			},
+		})
+		assert.Equal(t, `{"data":{"user":{"id":"1"}}}`, out)
+	})
+}
+
+func TestResolvable_FieldByteSizes(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+					},
+				},
+			},
+			{
+				Name: []byte("ids"),
+				Value: &Array{
+					Path: []string{"ids"},
+					Item: &Integer{},
+				},
+			},
+			{
+				Name:  []byte("__typename"),
+				Value: &String{Path: []string{"__typename"}, IsTypeName: true},
+			},
+		},
+	}
+	resolve := func(t *testing.T, renameTypeNames []RenameTypeName) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.RenameTypeNames = renameTypeNames
+		err := res.Init(ctx, []byte(`{"user":{"id":"1","name":"Jens"},"ids":[1,2,3],"__typename":"Query"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"user":{"id":"1","name":"Jens"},"ids":[1,2,3],"__typename":"Query"}}`, out.String())
+
+		sizes := res.FieldByteSizes()
+		assert.Equal(t, map[string]int{
+			"user":       len(`"user":{"id":"1","name":"Jens"}`),
+			"ids":        len(`"ids":[1,2,3]`),
+			"__typename": len(`"__typename":"Query"`),
+		}, sizes)
+
+		// the data section additionally contains the braces and the commas between the fields
+		data := out.Len() - len(`{"data":}`)
+		sum := 0
+		for _, size := range sizes {
+			sum += size
+		}
+		assert.Equal(t, data, sum+len(`{}`)+len(sizes)-1)
+
+		res.Reset()
+		assert.Len(t, res.FieldByteSizes(), 0)
+	}
+
+	t.Run("pass-through", func(t *testing.T) {
+		resolve(t, nil)
+	})
+	t.Run("walk", func(t *testing.T) {
+		resolve(t, []RenameTypeName{{From: []byte("Foo"), To: []byte("Bar")}})
+	})
+}
+
+func TestResolvable_SoftErrorExtension(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path:     []string{"user"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}},
+					},
+				},
+			},
+			{
+				Name: []byte("other"),
+				Value: &Object{
+					Path:     []string{"other"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, softErrorExtension bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SoftErrorExtension = softErrorExtension
+		err := res.Init(ctx, []byte(`{"user":{"name":1},"other":{"id":true}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"String cannot represent non-string value: \"1\"","path":["user","name"]},{"message":"String cannot represent non-string value: \"true\"","path":["other","id"]}],"data":{"user":null,"other":null}}`, resolve(t, false))
+	})
+	t.Run("enabled", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"String cannot represent non-string value: \"true\"","path":["other","id"]}],"data":{"user":null,"other":null},"extensions":{"softErrors":[{"message":"String cannot represent non-string value: \"1\"","path":["user","name"]}]}}`, resolve(t, true))
+	})
+}
+
+func TestResolvable_RenderRepresentations(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"me":{"__typename":"User","id":"1","name":"Jens","owner":{"id":"2","name":"Dustin"}},"products":[{"__typename":"Product","upc":"top-1","name":"Trilby"},{"__typename":"Product","upc":"top-2","name":"Fedora"}],"untyped":{"id":"3"}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	data, dataRoot := res.storage, res.dataRoot
+
+	t.Run("single entity", func(t *testing.T) {
+		representations, err := res.RenderRepresentations(data.Get(dataRoot, []string{"me"}), [][]string{{"id"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`{"__typename":"User","id":"1"}`}, toStrings(representations))
+	})
+	t.Run("nested key fields", func(t *testing.T) {
+		representations, err := res.RenderRepresentations(data.Get(dataRoot, []string{"me"}), [][]string{{"id"}, {"owner", "id"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`{"__typename":"User","id":"1","owner":{"id":"2"}}`}, toStrings(representations))
+	})
+	t.Run("list of entities", func(t *testing.T) {
+		representations, err := res.RenderRepresentations(data.Get(dataRoot, []string{"products"}), [][]string{{"upc"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`{"__typename":"Product","upc":"top-1"}`, `{"__typename":"Product","upc":"top-2"}`}, toStrings(representations))
+	})
+	t.Run("all flat fields", func(t *testing.T) {
+		representations, err := res.RenderRepresentations(data.Get(dataRoot, []string{"me"}), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`{"__typename":"User","id":"1","name":"Jens"}`}, toStrings(representations))
+	})
+	t.Run("missing key field", func(t *testing.T) {
+		_, err := res.RenderRepresentations(data.Get(dataRoot, []string{"products"}), [][]string{{"id"}})
+		assert.EqualError(t, err, "entity at index 0: entity has no value for key field 'id'")
+	})
+	t.Run("missing __typename", func(t *testing.T) {
+		_, err := res.RenderRepresentations(data.Get(dataRoot, []string{"untyped"}), [][]string{{"id"}})
+		assert.EqualError(t, err, "entity at index 0: entity has no __typename")
+	})
+}
+
+func toStrings(values [][]byte) []string {
+	out := make([]string, 0, len(values))
+	for i := range values {
+		out = append(out, string(values[i]))
+	}
+	return out
+}
+
+func TestResolvable_NonFiniteFloatMode(t *testing.T) {
+	resolve := func(t *testing.T, mode NonFiniteFloatMode, value string, nullable bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.NonFiniteFloatMode = mode
+		err := res.Init(ctx, []byte(fmt.Sprintf(`{"product":{"price":%s}}`, value)), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("product"),
+					Value: &Object{
+						Path:     []string{"product"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("price"), Value: &Float{Path: []string{"price"}, Nullable: nullable}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	for _, value := range []string{`"NaN"`, `"Infinity"`, `"-Infinity"`, `1e999`} {
+		t.Run(value, func(t *testing.T) {
+			raw := strings.Trim(value, `"`)
+			t.Run("error", func(t *testing.T) {
+				out := resolve(t, NonFiniteFloatModeError, value, true)
+				assert.Equal(t, fmt.Sprintf(`{"errors":[{"message":"Float cannot represent non-finite value: \"%s\"","path":["product","price"]}],"data":{"product":null}}`, raw), out)
+			})
+			t.Run("null", func(t *testing.T) {
+				out := resolve(t, NonFiniteFloatModeNull, value, true)
+				assert.Equal(t, `{"data":{"product":{"price":null}}}`, out)
+			})
+			t.Run("null non-nullable", func(t *testing.T) {
+				out := resolve(t, NonFiniteFloatModeNull, value, false)
+				assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.product.price'.","path":["product","price"]}],"data":{"product":null}}`, out)
+			})
+		})
+	}
+	t.Run("finite float", func(t *testing.T) {
+		for _, mode := range []NonFiniteFloatMode{NonFiniteFloatModeDefault, NonFiniteFloatModeError, NonFiniteFloatModeNull} {
+			out := resolve(t, mode, `1.5e10`, false)
+			assert.Equal(t, `{"data":{"product":{"price":1.5e10}}}`, out)
+		}
+	})
+	t.Run("default mode", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"Float cannot represent non-float value: \"NaN\"","path":["product","price"]}],"data":{"product":null}}`, resolve(t, NonFiniteFloatModeDefault, `"NaN"`, true))
+		assert.Equal(t, `{"data":{"product":{"price":1e999}}}`, resolve(t, NonFiniteFloatModeDefault, `1e999`, true))
+	})
+	t.Run("error is a coercion error", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.NonFiniteFloatMode = NonFiniteFloatModeError
+		ctx.IncludeErrorCategory = true
+		err := res.Init(ctx, []byte(`{"price":"NaN"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), &Object{
+			Fields: []*Field{
+				{Name: []byte("price"), Value: &Float{Path: []string{"price"}, Nullable: true}},
+			},
+		}, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"Float cannot represent non-finite value: \"NaN\"","path":["price"],"extensions":{"category":"validation"}}],"data":null}`, out.String())
+	})
+}
+
+func TestResolvable_TypeTransformers(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{Name: []byte("email"), Value: &String{Path: []string{"email"}}},
+						},
+					},
+				},
+			},
+			{
+				Name: []byte("admin"),
+				Value: &Object{
+					Path:     []string{"admin"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("email"), Value: &String{Path: []string{"email"}}},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, transformers map[string]func(objectData []byte) ([]byte, error)) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.TypeTransformers = transformers
+		err := res.Init(ctx, []byte(`{"users":[{"__typename":"User","name":"Jens","email":"JENS@EXAMPLE.COM"},{"__typename":"User","name":"Dustin","email":"Dustin@Example.com"}],"admin":{"__typename":"Admin","name":"Stefan","email":"STEFAN@EXAMPLE.COM"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("transform objects of a type", func(t *testing.T) {
+		out := resolve(t, map[string]func(objectData []byte) ([]byte, error){
+			"User": func(objectData []byte) ([]byte, error) {
+				var user struct {
+					Email string `json:"email"`
+				}
+				if err := json.Unmarshal(objectData, &user); err != nil {
+					return nil, err
+				}
+				return json.Marshal(map[string]string{"email": strings.ToLower(user.Email)})
+			},
+		})
+		assert.Equal(t, `{"data":{"users":[{"name":"Jens","email":"jens@example.com"},{"name":"Dustin","email":"dustin@example.com"}],"admin":{"name":"Stefan","email":"STEFAN@EXAMPLE.COM"}}}`, out)
+	})
+	t.Run("transformer error", func(t *testing.T) {
+		out := resolve(t, map[string]func(objectData []byte) ([]byte, error){
+			"Admin": func(objectData []byte) ([]byte, error) {
+				return nil, fmt.Errorf("invalid admin")
+			},
+		})
+		assert.Equal(t, `{"errors":[{"message":"invalid admin","path":["admin"]}],"data":{"users":[{"name":"Jens","email":"JENS@EXAMPLE.COM"},{"name":"Dustin","email":"Dustin@Example.com"}],"admin":null}}`, out)
+	})
+	t.Run("aliases and shared storage transform once", func(t *testing.T) {
+		storage := &astjson.JSON{}
+		dataRoot, err := storage.AppendObject([]byte(`{"admin":{"__typename":"Admin","name":"Stefan"}}`))
+		assert.NoError(t, err)
+		calls := 0
+		transformers := map[string]func(objectData []byte) ([]byte, error){
+			"Admin": func(objectData []byte) ([]byte, error) {
+				calls++
+				var admin struct {
+					Name string `json:"name"`
+				}
+				if err := json.Unmarshal(objectData, &admin); err != nil {
+					return nil, err
+				}
+				return json.Marshal(map[string]string{"name": admin.Name + "!"})
+			},
+		}
+		admin := func(alias string) *Field {
+			return &Field{
+				Name: []byte(alias),
+				Value: &Object{
+					Path: []string{"admin"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+					},
+				},
+			}
+		}
+		object := &Object{Fields: []*Field{admin("a"), admin("b")}}
+		for i := 0; i < 2; i++ {
+			res := NewResolvableWithStorage(storage)
+			ctx := NewContext(context.Background())
+			ctx.TypeTransformers = transformers
+			err := res.InitWithDataRoot(ctx, dataRoot, ast.OperationTypeQuery)
+			assert.NoError(t, err)
+			out := &bytes.Buffer{}
+			err = res.Resolve(context.Background(), object, nil, out)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"data":{"a":{"name":"Stefan!"},"b":{"name":"Stefan!"}}}`, out.String())
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestResolvable_ReverseRenameType(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.RenameTypeNames = []RenameTypeName{
+		{From: []byte("User"), To: []byte("Account")},
+		{From: []byte("Product"), To: []byte("Item")},
+	}
+	err := res.Init(ctx, []byte(`{"me":{"__typename":"User"}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("me"),
+				Value: &Object{
+					Path: []string{"me"},
+					Fields: []*Field{
+						{Name: []byte("__typename"), Value: &String{Path: []string{"__typename"}, IsTypeName: true}},
+					},
+				},
+			},
+		},
+	}, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"me":{"__typename":"Account"}}}`, out.String())
+
+	// round trip of the rendered type name
+	assert.Equal(t, "User", string(res.ReverseRenameType([]byte("Account"))))
+	assert.Equal(t, "Product", string(res.ReverseRenameType([]byte("Item"))))
+	assert.Equal(t, "Query", string(res.ReverseRenameType([]byte("Query"))))
+
+	res.Reset()
+	err = res.Init(NewContext(context.Background()), nil, ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	assert.Equal(t, "Account", string(res.ReverseRenameType([]byte("Account"))))
+}
+
+func TestResolvable_DetectDuplicateKeys(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("name"), Value: &String{Path: []string{"nickname"}}},
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}, OnTypeNames: [][]byte{[]byte("User")}},
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}, OnTypeNames: [][]byte{[]byte("Admin")}},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, detect bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.DetectDuplicateKeys = detect
+		err := res.Init(ctx, []byte(`{"user":{"__typename":"User","id":"1","name":"Jens","nickname":"jensneuse"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"user":{"name":"jensneuse","id":"1"}}}`, resolve(t, false))
+	})
+	t.Run("enabled", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"Duplicate response key 'name'.","path":["user"]}],"data":{"user":{"name":"jensneuse","id":"1"}}}`, resolve(t, true))
+	})
+}
+
+func TestResolvable_TrustedDataSourceIDs(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{
+							Name:  []byte("name"),
+							Value: &String{Path: []string{"name"}, Nullable: true},
+							Info: &FieldInfo{
+								Name:                 "name",
+								ExactParentTypeName:  "User",
+								Source:               TypeFieldSource{IDs: []string{"internal"}},
+								HasAuthorizationRule: true,
+							},
+						},
+						{
+							Name:  []byte("email"),
+							Value: &String{Path: []string{"email"}, Nullable: true},
+							Info: &FieldInfo{
+								Name:                 "email",
+								ExactParentTypeName:  "User",
+								Source:               TypeFieldSource{IDs: []string{"accounts"}},
+								HasAuthorizationRule: true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, trusted map[string]struct{}) (string, []string) {
+		var authorized []string
+		authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+			authorized = append(authorized, dataSourceID)
+			return &AuthorizationDeny{Reason: "denied"}, nil
+		})
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SetAuthorizer(authorizer)
+		ctx.TrustedDataSourceIDs = trusted
+		err := res.Init(ctx, []byte(`{"user":{"__typename":"User","name":"Jens","email":"jens@example.com"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String(), authorized
+	}
+
+	t.Run("untrusted", func(t *testing.T) {
+		out, authorized := resolve(t, nil)
+		assert.Equal(t, []string{"internal", "accounts"}, authorized)
+		assert.Equal(t, `{"errors":[{"message":"Unauthorized to load field 'Query.user.name', Reason: denied.","path":["user","name"]},{"message":"Unauthorized to load field 'Query.user.email', Reason: denied.","path":["user","email"]}],"data":{"user":{"name":null,"email":null}}}`, out)
+	})
+	t.Run("trusted", func(t *testing.T) {
+		out, authorized := resolve(t, map[string]struct{}{"internal": {}})
+		assert.Equal(t, []string{"accounts"}, authorized)
+		assert.Equal(t, `{"errors":[{"message":"Unauthorized to load field 'Query.user.email', Reason: denied.","path":["user","email"]}],"data":{"user":{"name":"Jens","email":null}}}`, out)
+	})
+}
+
+func TestResolvable_OnResolveObject(t *testing.T) {
+	type resolvedObject struct {
+		typeName string
+		path     string
+	}
+	var resolved []resolvedObject
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.OnResolveObject = func(typeName string, path string) {
+		resolved = append(resolved, resolvedObject{typeName: typeName, path: path})
+	}
+	err := res.Init(ctx, []byte(`{"me":{"__typename":"User","name":"Jens","pets":[{"__typename":"Cat","name":"Mietze"},{"__typename":"Dog","name":"Bello"}],"address":{"city":"Berlin"}}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("me"),
+				Value: &Object{
+					Path: []string{"me"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{
+							Name: []byte("pets"),
+							Value: &Array{
+								Path: []string{"pets"},
+								Item: &Object{
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+						},
+						{
+							Name: []byte("address"),
+							Value: &Object{
+								Path: []string{"address"},
+								Fields: []*Field{
+									{Name: []byte("city"), Value: &String{Path: []string{"city"}}, Info: &FieldInfo{Name: "city", ExactParentTypeName: "Address"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"me":{"name":"Jens","pets":[{"name":"Mietze"},{"name":"Bello"}],"address":{"city":"Berlin"}}}}`, out.String())
+	assert.Equal(t, []resolvedObject{
+		{typeName: "User", path: "me"},
+		{typeName: "Cat", path: "me.pets.0"},
+		{typeName: "Dog", path: "me.pets.1"},
+		{typeName: "Address", path: "me.address"},
+	}, resolved)
+}
+
+func TestResolvable_CountDuplicateArrayItems(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"unique":[{"id":1},{"id":2},{"id":3}],"duplicates":[{"id":1,"tags":["a"]},{"id":2},{"id":1,"tags":["a"]},{"id":1,"tags":["a"]},{"id":2}],"scalars":[1,"1",1,true,null,null],"reordered":[{"a":1,"b":2},{"b":2,"a":1}],"empty":[],"object":{}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	get := func(path string) int {
+		return res.storage.Get(res.dataRoot, []string{path})
+	}
+
+	assert.Equal(t, 0, res.CountDuplicateArrayItems(get("unique")))
+	assert.Equal(t, 3, res.CountDuplicateArrayItems(get("duplicates")))
+	assert.Equal(t, 2, res.CountDuplicateArrayItems(get("scalars")))
+	assert.Equal(t, 0, res.CountDuplicateArrayItems(get("reordered")))
+	assert.Equal(t, 0, res.CountDuplicateArrayItems(get("empty")))
+	assert.Equal(t, 0, res.CountDuplicateArrayItems(get("object")))
+	assert.Equal(t, 0, res.CountDuplicateArrayItems(get("missing")))
+}
+
+func TestResolvable_IncludeOperationNameInErrorPath(t *testing.T) {
+	resolve := func(t *testing.T, operationName string, include bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.Request.OperationName = operationName
+		ctx.IncludeOperationNameInErrorPath = include
+		err := res.Init(ctx, []byte(`{"user":{"name":null}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path:     []string{"user"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+			},
+		}, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("named operation", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'MyQuery.user.name'.","path":["user","name"]}],"data":{"user":null}}`, resolve(t, "MyQuery", true))
+	})
+	t.Run("anonymous operation", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.name'.","path":["user","name"]}],"data":{"user":null}}`, resolve(t, "", true))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.name'.","path":["user","name"]}],"data":{"user":null}}`, resolve(t, "MyQuery", false))
+	})
+}
+
+func TestResolvable_Errors(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"users":[{"name":"Jens"},{"name":"<b>\"Dustin\"</b>","age":"old"},{}]}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	res.AppendInputError([]string{"filter"}, `Value "x" is invalid`)
+	assert.Equal(t, []GraphQLError{{Message: `Value "x" is invalid`, Path: []any{"filter"}}}, res.Errors())
+
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+						},
+					},
+				},
+			},
+		},
+	}, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"errors":[{"message":"Value \"x\" is invalid","path":["filter"]},{"message":"Int cannot represent non-integer value: \"old\"","path":["users",1,"age"]},{"message":"Cannot return null for non-nullable field 'Query.users.name'.","path":["users",2,"name"]}],"data":{"users":[{"name":"Jens","age":null},null,null]}}`, out.String())
+
+	graphQLErrors := res.Errors()
+	assert.Equal(t, []GraphQLError{
+		{Message: `Value "x" is invalid`, Path: []any{"filter"}},
+		{Message: `Int cannot represent non-integer value: "old"`, Path: []any{"users", 1, "age"}},
+		{Message: `Cannot return null for non-nullable field 'Query.users.name'.`, Path: []any{"users", 2, "name"}},
+	}, graphQLErrors)
+
+	// the structured errors round trip to the rendered errors
+	encoded, err := json.Marshal(graphQLErrors)
+	assert.NoError(t, err)
+	var response struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &response))
+	assert.Equal(t, string(response.Errors), string(encoded))
+}
+
+func TestResolvable_ConnectionInfo(t *testing.T) {
+	const pageSize = 2
+	computePageInfo := func(ctx *Context, edges [][]byte) (PageInfo, error) {
+		if len(edges) == 0 {
+			return PageInfo{}, nil
+		}
+		var edge struct {
+			Cursor string `json:"cursor"`
+		}
+		if err := json.Unmarshal(edges[len(edges)-1], &edge); err != nil {
+			return PageInfo{}, err
+		}
+		return PageInfo{HasNextPage: len(edges) == pageSize, EndCursor: &edge.Cursor}, nil
+	}
+	connection := func(path string) *Field {
+		return &Field{
+			Name: []byte(path),
+			Value: &Object{
+				Path: []string{path},
+				Fields: []*Field{
+					{
+						Name: []byte("edges"),
+						Value: &Array{
+							Path: []string{"edges"},
+							Item: &Object{
+								Fields: []*Field{
+									{Name: []byte("cursor"), Value: &String{Path: []string{"cursor"}}},
+								},
+							},
+						},
+					},
+					{
+						Name:  []byte("pageInfo"),
+						Value: &ConnectionInfo{EdgesPath: []string{"edges"}, ComputePageInfo: computePageInfo},
+					},
+				},
+			},
+		}
+	}
+
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"users":{"edges":[{"cursor":"YQ=="},{"cursor":"Yg=="}]},"posts":{"edges":[{"cursor":"Yw=="}]},"comments":{"edges":[]}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), &Object{
+		Fields: []*Field{connection("users"), connection("posts"), connection("comments")},
+	}, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"users":{"edges":[{"cursor":"YQ=="},{"cursor":"Yg=="}],"pageInfo":{"hasNextPage":true,"endCursor":"Yg=="}},"posts":{"edges":[{"cursor":"Yw=="}],"pageInfo":{"hasNextPage":false,"endCursor":"Yw=="}},"comments":{"edges":[],"pageInfo":{"hasNextPage":false,"endCursor":null}}}}`, out.String())
+
+	t.Run("max array items", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.MaxArrayItems = 1
+		err := res.Init(ctx, []byte(`{"users":{"edges":[{"cursor":"YQ=="},{"cursor":"Yg=="}]}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), &Object{Fields: []*Field{connection("users")}}, nil, out)
+		assert.NoError(t, err)
+		// the endCursor is the cursor of the last printed edge
+		assert.Equal(t, `{"data":{"users":{"edges":[{"cursor":"YQ=="}],"pageInfo":{"hasNextPage":false,"endCursor":"YQ=="}}},"extensions":{"warnings":[{"message":"List truncated to 1 items, 1 items omitted.","path":["users","edges"]}]}}`, out.String())
+	})
+
+	failing := func(ctx *Context, edges [][]byte) (PageInfo, error) {
+		return PageInfo{}, errors.New("invalid cursor")
+	}
+	resolveFailing := func(t *testing.T, nullable bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.IncludeErrorCategory = true
+		err := res.Init(ctx, []byte(`{"users":{"edges":[]}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("users"),
+					Value: &Object{
+						Path:     []string{"users"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("pageInfo"), Value: &ConnectionInfo{EdgesPath: []string{"edges"}, Nullable: nullable, ComputePageInfo: failing}},
+						},
+					},
+				},
+			},
+		}, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+	t.Run("nullable error", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"invalid cursor","path":["users"],"extensions":{"category":"resolver"}}],"data":{"users":{"pageInfo":null}}}`, resolveFailing(t, true))
+	})
+	t.Run("error", func(t *testing.T) {
+		assert.Equal(t, `{"errors":[{"message":"invalid cursor","path":["users"],"extensions":{"category":"resolver"}}],"data":{"users":null}}`, resolveFailing(t, false))
+	})
+}
+
+func TestResolvable_CacheControl(t *testing.T) {
+	resolve := func(t *testing.T, fields []*Field) (CacheControl, bool) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.Variables = []byte(`{"withSecret":false}`)
+		err := res.Init(ctx, []byte(`{"me":{"name":"Jens","email":"jens@example.com","secret":"s"},"products":[{"name":"Trilby"}]}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		err = res.Resolve(context.Background(), &Object{Fields: fields}, nil, &bytes.Buffer{})
+		assert.NoError(t, err)
+		return res.CacheControl()
+	}
+	me := func(cacheControl *CacheControl, fields ...*Field) *Field {
+		return &Field{
+			Name:         []byte("me"),
+			Value:        &Object{Path: []string{"me"}, Fields: fields},
+			CacheControl: cacheControl,
+		}
+	}
+	products := &Field{
+		Name: []byte("products"),
+		Value: &Array{
+			Path: []string{"products"},
+			Item: &Object{
+				Fields: []*Field{
+					{Name: []byte("name"), Value: &String{Path: []string{"name"}}, CacheControl: &CacheControl{MaxAge: 300}},
+				},
+			},
+		},
+		CacheControl: &CacheControl{MaxAge: 600, Scope: CacheControlScopePublic},
+	}
+
+	t.Run("no hints", func(t *testing.T) {
+		_, ok := resolve(t, []*Field{me(nil, &Field{Name: []byte("name"), Value: &String{Path: []string{"name"}}})})
+		assert.False(t, ok)
+	})
+	t.Run("minimum max age", func(t *testing.T) {
+		cacheControl, ok := resolve(t, []*Field{products})
+		assert.True(t, ok)
+		assert.Equal(t, CacheControl{MaxAge: 300, Scope: CacheControlScopePublic}, cacheControl)
+	})
+	t.Run("most restrictive scope", func(t *testing.T) {
+		cacheControl, ok := resolve(t, []*Field{
+			products,
+			me(&CacheControl{MaxAge: 120, Scope: CacheControlScopePrivate},
+				&Field{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+				&Field{Name: []byte("email"), Value: &String{Path: []string{"email"}}, CacheControl: &CacheControl{MaxAge: 60}},
+			),
+		})
+		assert.True(t, ok)
+		assert.Equal(t, CacheControl{MaxAge: 60, Scope: CacheControlScopePrivate}, cacheControl)
+	})
+	t.Run("excluded fields are ignored", func(t *testing.T) {
+		cacheControl, ok := resolve(t, []*Field{
+			products,
+			me(nil,
+				&Field{Name: []byte("secret"), Value: &String{Path: []string{"secret"}}, CacheControl: &CacheControl{MaxAge: 0, Scope: CacheControlScopePrivate}, IncludeDirectiveDefined: true, IncludeVariableName: "withSecret"},
+			),
+		})
+		assert.True(t, ok)
+		assert.Equal(t, CacheControl{MaxAge: 300, Scope: CacheControlScopePublic}, cacheControl)
+	})
+}
+
+func TestResolvable_ResolveSkeleton(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.Variables = []byte(`{"withEmail":false}`)
+	err := res.Init(ctx, []byte(`{"me":{"id":1,"name":"Jens","email":"jens@example.com","friends":[{"name":"Stefan"}]}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("me"),
+				Value: &Object{
+					Path: []string{"me"},
+					Fields: []*Field{
+						{Name: []byte("email"), Value: &String{Path: []string{"email"}}, IncludeDirectiveDefined: true, IncludeVariableName: "withEmail"},
+						{Name: []byte("id"), Value: &Integer{Path: []string{"id"}}},
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}, OnTypeNames: [][]byte{[]byte("User")}},
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}, OnTypeNames: [][]byte{[]byte("Admin")}},
+						{
+							Name: []byte("friends"),
+							Value: &Array{
+								Path: []string{"friends"},
+								Item: &Object{Fields: []*Field{{Name: []byte("name"), Value: &String{Path: []string{"name"}}}}},
+							},
+						},
+						{Name: []byte("settings"), Value: &EmptyObject{}},
+						{Name: []byte("posts"), Value: &StreamedArray{Path: []string{"posts"}, Item: &String{}}},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.ResolveSkeleton(object, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"me":{"id":null,"name":null,"friends":[],"settings":{},"posts":[]}}}`, out.String())
+}
+
+func TestResolvable_RootTypeName(t *testing.T) {
+	resolve := func(t *testing.T, data string, renameTypeNames []RenameTypeName) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.RootTypeName = "Query"
+		ctx.RenameTypeNames = renameTypeNames
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("__typename"), Value: &String{Path: []string{"__typename"}, IsTypeName: true}},
+				{Name: []byte("hello"), Value: &String{Path: []string{"hello"}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("absent from data", func(t *testing.T) {
+		out := resolve(t, `{"hello":"world"}`, nil)
+		assert.Equal(t, `{"data":{"__typename":"Query","hello":"world"}}`, out)
+	})
+	t.Run("present in data", func(t *testing.T) {
+		out := resolve(t, `{"__typename":"RootQuery","hello":"world"}`, nil)
+		assert.Equal(t, `{"data":{"__typename":"RootQuery","hello":"world"}}`, out)
+	})
+	t.Run("renamed", func(t *testing.T) {
+		out := resolve(t, `{"hello":"world"}`, []RenameTypeName{{From: []byte("Query"), To: []byte("PublicQuery")}})
+		assert.Equal(t, `{"data":{"__typename":"PublicQuery","hello":"world"}}`, out)
+	})
+}
+
+func TestResolvable_SimplifySingleError(t *testing.T) {
+	resolve := func(t *testing.T, data string, inputErrors ...string) (string, bool) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SimplifySingleError = true
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		for _, message := range inputErrors {
+			res.AppendInputError(nil, message)
+		}
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+				{Name: []byte("age"), Value: &Integer{Path: []string{"age"}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String(), res.WroteErrorsWithoutData()
+	}
+
+	t.Run("single error", func(t *testing.T) {
+		out, wroteErrorsWithoutData := resolve(t, `{"age":1}`)
+		assert.Equal(t, `{"error":"Cannot return null for non-nullable field 'Query.name'."}`, out)
+		assert.True(t, wroteErrorsWithoutData)
+	})
+	t.Run("multiple errors", func(t *testing.T) {
+		out, wroteErrorsWithoutData := resolve(t, `{"age":1}`, "Variable \"id\" is invalid")
+		assert.Equal(t, `{"errors":[{"message":"Variable \"id\" is invalid","path":[]},{"message":"Cannot return null for non-nullable field 'Query.name'.","path":["name"]}],"data":null}`, out)
+		assert.True(t, wroteErrorsWithoutData)
+	})
+	t.Run("data", func(t *testing.T) {
+		out, wroteErrorsWithoutData := resolve(t, `{"name":"Jens","age":1}`)
+		assert.Equal(t, `{"data":{"name":"Jens","age":1}}`, out)
+		assert.False(t, wroteErrorsWithoutData)
+	})
+}
+
+func TestResolvable_InitSubscriptionDelta(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("stock"),
+				Value: &Object{
+					Path: []string{"stock"},
+					Fields: []*Field{
+						{Name: []byte("symbol"), Value: &String{Path: []string{"symbol"}}},
+						{Name: []byte("price"), Value: &Float{Path: []string{"price"}}},
+						{
+							Name: []byte("exchange"),
+							Value: &Object{
+								Path: []string{"exchange"},
+								Fields: []*Field{
+									{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									{Name: []byte("open"), Value: &Boolean{Path: []string{"open"}}},
+								},
+							},
+						},
+						{Name: []byte("tags"), Value: &Array{Path: []string{"tags"}, Item: &String{}}},
+					},
+				},
+			},
+		},
+	}
+	postProcessing := PostProcessingConfiguration{
+		SelectResponseDataPath:   []string{"data"},
+		SelectResponseErrorsPath: []string{"errors"},
+	}
+	resolve := func(t *testing.T, data, previous string) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.InitSubscriptionDelta(ctx, []byte(data), []byte(previous), postProcessing)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	first := resolve(t, `{"data":{"stock":{"symbol":"ACME","price":1.5,"exchange":{"name":"NYSE","open":true},"tags":["a","b"]}}}`, "")
+	assert.Equal(t, `{"data":{"stock":{"symbol":"ACME","price":1.5,"exchange":{"name":"NYSE","open":true},"tags":["a","b"]}}}`, first)
+
+	t.Run("changed fields", func(t *testing.T) {
+		out := resolve(t, `{"data":{"stock":{"symbol":"ACME","price":1.7,"exchange":{"name":"NYSE","open":false},"tags":["a","b"]}}}`, first)
+		assert.Equal(t, `{"data":{"stock":{"price":1.7,"exchange":{"open":false}}}}`, out)
+	})
+	t.Run("changed list", func(t *testing.T) {
+		out := resolve(t, `{"data":{"stock":{"symbol":"ACME","price":1.5,"exchange":{"name":"NYSE","open":true},"tags":["a","c"]}}}`, first)
+		assert.Equal(t, `{"data":{"stock":{"tags":["a","c"]}}}`, out)
+	})
+	t.Run("unchanged", func(t *testing.T) {
+		out := resolve(t, `{"data":{"stock":{"symbol":"ACME","price":1.5,"exchange":{"name":"NYSE","open":true},"tags":["a","b"]}}}`, first)
+		assert.Equal(t, `{"data":{}}`, out)
+	})
+}
+
+func TestResolvable_NullPlaceholder(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}, NullPlaceholder: []byte(`""`)},
+						{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}, NullPlaceholder: []byte(`-1`)},
+						{Name: []byte("tags"), Value: &Array{Path: []string{"tags"}, Nullable: true, Item: &String{}}, NullPlaceholder: []byte(`[]`)},
+						{
+							Name: []byte("address"),
+							Value: &Object{
+								Path:     []string{"address"},
+								Nullable: true,
+								Fields:   []*Field{{Name: []byte("city"), Value: &String{Path: []string{"city"}}}},
+							},
+							NullPlaceholder: []byte(`{}`),
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.NoError(t, ValidateNullPlaceholders(object))
+
+	t.Run("null values", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(`{"user":{"name":null,"tags":null,"address":{}}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.address.city'.","path":["user","address","city"]}],"data":{"user":{"name":"","age":-1,"tags":[],"address":{}}}}`, out.String())
+	})
+	t.Run("values", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(`{"user":{"name":"Jens","age":33,"tags":["a"],"address":{"city":"Berlin"}}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens","age":33,"tags":["a"],"address":{"city":"Berlin"}}}}`, out.String())
+	})
+	t.Run("validation", func(t *testing.T) {
+		err := ValidateNullPlaceholders(&Object{Fields: []*Field{{Name: []byte("name"), Value: &String{Path: []string{"name"}}, NullPlaceholder: []byte(`""`)}}})
+		assert.EqualError(t, err, "null placeholder of field 'name' is set on a non-nullable field")
+		err = ValidateNullPlaceholders(&Object{Fields: []*Field{{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}, NullPlaceholder: []byte(`"`)}}})
+		assert.EqualError(t, err, "null placeholder of field 'name' is not valid JSON: \"")
+	})
+	t.Run("invalid placeholder without validation", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(`{"name":null}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), &Object{Fields: []*Field{{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}, NullPlaceholder: []byte(`"`)}}}, nil, out)
+		assert.Error(t, err)
+	})
+}
+
+func wideRootObject(rootFields, items int) (*Object, []byte) {
+	data := &bytes.Buffer{}
+	fields := make([]*Field, 0, rootFields)
+	data.WriteString(`{`)
+	for i := 0; i < rootFields; i++ {
+		name := fmt.Sprintf("field%d", i)
+		fields = append(fields, &Field{
+			Name: []byte(name),
+			Value: &Array{
+				Path: []string{name},
+				Item: &Object{
+					Fields: []*Field{
+						{Name: []byte("__typename"), Value: &String{Path: []string{"__typename"}, IsTypeName: true}},
+						{Name: []byte("id"), Value: &Integer{Path: []string{"id"}}},
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}},
+					},
+				},
+			},
+		})
+		if i != 0 {
+			data.WriteString(",")
+		}
+		data.WriteString(fmt.Sprintf(`"%s":[`, name))
+		for j := 0; j < items; j++ {
+			if j != 0 {
+				data.WriteString(",")
+			}
+			data.WriteString(fmt.Sprintf(`{"__typename":"User","id":%d,"name":"user %d"}`, j, j))
+		}
+		data.WriteString(`]`)
+	}
+	data.WriteString(`}`)
+	return &Object{Fields: fields}, data.Bytes()
+}
+
+func TestResolvable_ParallelRootFields(t *testing.T) {
+	object, data := wideRootObject(16, 32)
+	object.Fields[3].SkipDirectiveDefined = true
+	object.Fields[3].SkipVariableName = "skip"
+
+	resolve := func(t *testing.T, parallel bool) (string, Stats, map[string]int) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.Variables = []byte(`{"skip":true}`)
+		ctx.RenameTypeNames = []RenameTypeName{{From: []byte("User"), To: []byte("Account")}}
+		ctx.ParallelRootFields = parallel
+		err := res.Init(ctx, data, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String(), ctx.Stats, res.FieldByteSizes()
+	}
+
+	expected, expectedStats, expectedSizes := resolve(t, false)
+	actual, actualStats, actualSizes := resolve(t, true)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, expectedStats.ResolvedNodes, actualStats.ResolvedNodes)
+	assert.Equal(t, expectedStats.ResolvedObjects, actualStats.ResolvedObjects)
+	assert.Equal(t, expectedStats.ResolvedLeafs, actualStats.ResolvedLeafs)
+	assert.Equal(t, expectedSizes, actualSizes)
+	assert.NotContains(t, actual, `"field3"`)
+	assert.Contains(t, actual, `{"__typename":"Account","id":0,"name":"user 0"}`)
+}
+
+type invalidCustomResolve struct{}
+
+func (invalidCustomResolve) Resolve(ctx *Context, value []byte) ([]byte, error) {
+	return []byte(`{`), nil
+}
+
+func TestResolvable_ParallelRootFieldsErrors(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.ParallelRootFields = true
+	err := res.Init(ctx, []byte(`{"a":"a","b":"b","c":"c"}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{Name: []byte("a"), Value: &CustomNode{CustomResolve: invalidCustomResolve{}, Path: []string{"a"}, Nullable: true}},
+			{Name: []byte("b"), Value: &CustomNode{CustomResolve: invalidCustomResolve{}, Path: []string{"b"}, Nullable: true}},
+			{Name: []byte("c"), Value: &String{Path: []string{"c"}}},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	// the errors of the print walk are merged from the forks
+	assert.Len(t, res.storage.Nodes[res.errorsRoot].ArrayValues, 2)
+	errs := &bytes.Buffer{}
+	err = res.storage.PrintNode(res.storage.Nodes[res.errorsRoot], errs)
+	assert.NoError(t, err)
+	assert.True(t, json.Valid(errs.Bytes()))
+	assert.Contains(t, errs.String(), `"path":["a"]`)
+	assert.Contains(t, errs.String(), `"path":["b"]`)
+}
+
+func TestResolvable_ParallelRootFieldsSharedData(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	// all root fields walk the same data, so the forks append to the same nodes concurrently
+	user := &Object{
+		Path:     []string{"user"},
+		Nullable: true,
+		Fields: []*Field{
+			{Name: []byte("email"), Value: &String{Path: []string{"email"}}},
+			{
+				Name: []byte("posts"),
+				Value: &Array{
+					Path: []string{"posts"},
+					Item: &Object{
+						Fields: []*Field{
+							{
+								Name: []byte("node"),
+								Value: &Object{
+									Path:        []string{"node"},
+									FlattenInto: true,
+									Fields: []*Field{
+										{Name: []byte("title"), Value: &String{Path: []string{"title"}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	object := &Object{}
+	for i := 0; i < 8; i++ {
+		object.Fields = append(object.Fields, &Field{Name: []byte(fmt.Sprintf("user%d", i)), Value: user})
+	}
+	data := []byte(`{"user":{"__typename":"User","email":"JENS@EXAMPLE.COM","bio":"bio","posts":[{"node":{"title":"a"}},{"node":{"title":"b"}},{"node":{"title":"c"}}]}}`)
+
+	resolve := func(t *testing.T, parallel bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.ParallelRootFields = parallel
+		ctx.MaxArrayItems = 2
+		ctx.TypeTransformers = map[string]func(objectData []byte) ([]byte, error){
+			"User": func(objectData []byte) ([]byte, error) {
+				return []byte(`{"email":"jens@example.com"}`), nil
+			},
+		}
+		err := res.Init(ctx, data, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	expected := resolve(t, false)
+	assert.Contains(t, expected, `"user0":{"email":"jens@example.com","posts":[{"title":"a"},{"title":"b"}]}`)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, expected, resolve(t, true))
+	}
+}
+
+func BenchmarkResolvable_ParallelRootFields(b *testing.B) {
+	object, data := wideRootObject(32, 512)
+
+	run := func(b *testing.B, parallel bool) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.RenameTypeNames = []RenameTypeName{{From: []byte("User"), To: []byte("Account")}}
+		ctx.ParallelRootFields = parallel
+		out := &bytes.Buffer{}
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			res.Reset()
+			out.Reset()
+			err := res.Init(ctx, data, ast.OperationTypeQuery)
+			if err != nil {
+				b.Fatal(err)
+			}
+			err = res.Resolve(context.Background(), object, nil, out)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		run(b, false)
+	})
+	b.Run("parallel", func(b *testing.B) {
+		run(b, true)
+	})
+}
+
+func TestResolvable_ContentHash(t *testing.T) {
+	resolve := func(t *testing.T, data string) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+				{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+			},
+		}
+		err = res.Resolve(context.Background(), object, nil, &bytes.Buffer{})
+		assert.NoError(t, err)
+		return res.ContentHash()
+	}
+
+	jens := resolve(t, `{"name":"Jens","age":33}`)
+	assert.NotEmpty(t, jens)
+	assert.Equal(t, jens, resolve(t, `{"age":33,"name":"Jens","unused":true}`))
+	assert.NotEqual(t, jens, resolve(t, `{"name":"Jens","age":34}`))
+	assert.NotEqual(t, jens, resolve(t, `{"name":"Jens"}`))
+	assert.Empty(t, resolve(t, `{"age":33}`))
+}
+
+func TestResolvable_FailFast(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+						},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, failFast bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.FailFast = failFast
+		err := res.Init(ctx, []byte(`{"users":[{"name":"Jens","age":"old"},{},{"name":"Stefan","age":"young"}]}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("collect all", func(t *testing.T) {
+		out := resolve(t, false)
+		assert.Equal(t, `{"errors":[{"message":"Int cannot represent non-integer value: \"old\"","path":["users",0,"age"]},{"message":"Cannot return null for non-nullable field 'Query.users.name'.","path":["users",1,"name"]},{"message":"Int cannot represent non-integer value: \"young\"","path":["users",2,"age"]}],"data":{"users":[null,null,null]}}`, out)
+	})
+	t.Run("fail fast", func(t *testing.T) {
+		out := resolve(t, true)
+		assert.Equal(t, `{"errors":[{"message":"Int cannot represent non-integer value: \"old\"","path":["users",0,"age"]}],"data":null}`, out)
+	})
+}
+
+func TestResolvable_IncludeErrorCategory(t *testing.T) {
+	authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+		if coordinate.FieldName == "secret" {
+			return &AuthorizationDeny{Reason: "missing scope"}, nil
+		}
+		return nil, nil
+	})
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+							{
+								Name:  []byte("secret"),
+								Value: &String{Path: []string{"secret"}, Nullable: true},
+								Info: &FieldInfo{
+									Name:                 "secret",
+									ExactParentTypeName:  "User",
+									Source:               TypeFieldSource{IDs: []string{"users"}},
+									HasAuthorizationRule: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, includeErrorCategory bool) []GraphQLError {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.IncludeErrorCategory = includeErrorCategory
+		ctx.SetAuthorizer(authorizer)
+		err := res.Init(ctx, []byte(`{"users":[{"name":"Jens","age":"old"},{},{"name":"Stefan","secret":"s"}]}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		res.AppendInputError([]string{"filter"}, "invalid filter")
+		err = res.Resolve(context.Background(), object, nil, &bytes.Buffer{})
+		assert.NoError(t, err)
+		return res.Errors()
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		assert.Equal(t, []GraphQLError{
+			{Message: "invalid filter", Path: []any{"filter"}, Extensions: map[string]any{"category": ErrorCategoryValidation}},
+			{Message: `Int cannot represent non-integer value: "old"`, Path: []any{"users", 0, "age"}, Extensions: map[string]any{"category": ErrorCategoryValidation}},
+			{Message: "Cannot return null for non-nullable field 'Query.users.name'.", Path: []any{"users", 1, "name"}, Extensions: map[string]any{"category": ErrorCategoryInternal}},
+			{Message: "Unauthorized to load field 'Query.users.secret', Reason: missing scope.", Path: []any{"users", 2, "secret"}, Extensions: map[string]any{"category": ErrorCategoryAuthorization}},
+		}, resolve(t, true))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		for _, graphQLError := range resolve(t, false) {
+			assert.Nil(t, graphQLError.Extensions)
+		}
+	})
+}
+
+func TestResolvable_ResolveBatch(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{Name: []byte("hello"), Value: &String{Path: []string{"hello"}}},
+		},
+	}
+	res := NewResolvable()
+	out := &bytes.Buffer{}
+	err := res.ResolveBatch([]BatchItem{
+		{Ctx: NewContext(context.Background()), Data: []byte(`{"hello":"world"}`), OperationType: ast.OperationTypeQuery, RootData: object},
+		{Ctx: NewContext(context.Background()), Data: []byte(`{"hello":null}`), OperationType: ast.OperationTypeQuery, RootData: object},
+	}, out)
+	assert.NoError(t, err)
+	assert.True(t, json.Valid(out.Bytes()))
+	assert.Equal(t, `[{"data":{"hello":"world"}},{"errors":[{"message":"Cannot return null for non-nullable field 'Query.hello'.","path":["hello"]}],"data":null}]`, out.String())
+
+	out.Reset()
+	err = res.ResolveBatch(nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, out.String())
+}
+
+func TestResolvable_OnDeprecatedFieldUsed(t *testing.T) {
+	deprecated := func(fieldName string) *FieldInfo {
+		return &FieldInfo{Name: fieldName, ExactParentTypeName: "User", IsDeprecated: true}
+	}
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}, Info: &FieldInfo{Name: "name", ExactParentTypeName: "User"}},
+							{Name: []byte("username"), Value: &String{Path: []string{"username"}, Nullable: true}, Info: deprecated("username")},
+							{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}, Info: deprecated("age"), SkipDirectiveDefined: true, SkipVariableName: "skip"},
+						},
+					},
+				},
+			},
+		},
+	}
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.Variables = []byte(`{"skip":true}`)
+	var used []GraphCoordinate
+	ctx.OnDeprecatedFieldUsed = func(coordinate GraphCoordinate) {
+		used = append(used, coordinate)
+	}
+	err := res.Init(ctx, []byte(`{"users":[{"name":"Jens","username":"jens","age":33},{"name":"Stefan","age":34},{"name":"Dustin","username":null}]}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"users":[{"name":"Jens","username":"jens"},{"name":"Stefan","username":null},{"name":"Dustin","username":null}]}}`, out.String())
+	assert.Equal(t, []GraphCoordinate{
+		{TypeName: "User", FieldName: "username"},
+		{TypeName: "User", FieldName: "username"},
+	}, used)
+}
+
+func TestResolvable_ASCIIOnlyStrings(t *testing.T) {
+	resolve := func(t *testing.T, data string) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.ASCIIOnlyStrings = true
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+				{Name: []byte("tags"), Value: &Array{Path: []string{"tags"}, Nullable: true, Item: &String{}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("accented characters", func(t *testing.T) {
+		out := resolve(t, `{"name":"Jérôme Müller","tags":["ß"]}`)
+		assert.Equal(t, `{"data":{"name":"J\u00e9r\u00f4me M\u00fcller","tags":["\u00df"]}}`, out)
+	})
+	t.Run("emoji", func(t *testing.T) {
+		out := resolve(t, `{"name":"rocket 🚀","tags":["€"]}`)
+		assert.Equal(t, `{"data":{"name":"rocket \ud83d\ude80","tags":["\u20ac"]}}`, out)
+		var decoded struct {
+			Data struct {
+				Name string `json:"name"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(out), &decoded))
+		assert.Equal(t, "rocket 🚀", decoded.Data.Name)
+	})
+	t.Run("control characters", func(t *testing.T) {
+		out := resolve(t, `{"name":"tab\tnew\nline\u0001 \"quoted\" ü","tags":null}`)
+		assert.Equal(t, `{"data":{"name":"tab\tnew\nline\u0001 \"quoted\" \u00fc","tags":null}}`, out)
+	})
+	t.Run("errors", func(t *testing.T) {
+		out := resolve(t, `{"name":"Jens","tags":[1]}`)
+		assert.Equal(t, `{"errors":[{"message":"String cannot represent non-string value: \"1\"","path":["tags",0]}],"data":{"name":"Jens","tags":null}}`, out)
+	})
+}
+
+func TestResolvable_FeatureFlags(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("nickname"), Value: &String{Path: []string{"nickname"}, Nullable: true}, FeatureFlag: "nicknames"},
+						{Name: []byte("score"), Value: &Integer{Path: []string{"score"}}, FeatureFlag: "scores"},
+						{Name: []byte("beta"), Value: &Boolean{Path: []string{"beta"}}, FeatureFlag: "unknown"},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, featureFlags map[string]bool, includeUnknown bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.FeatureFlags = featureFlags
+		ctx.IncludeUnknownFeatureFlags = includeUnknown
+		err := res.Init(ctx, []byte(`{"user":{"name":"Jens","nickname":"jensneuse","score":7,"beta":true}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		out := resolve(t, map[string]bool{"nicknames": true, "scores": true}, false)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens","nickname":"jensneuse","score":7}}}`, out)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		out := resolve(t, map[string]bool{"nicknames": false, "scores": false}, false)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens"}}}`, out)
+	})
+	t.Run("unknown included", func(t *testing.T) {
+		out := resolve(t, map[string]bool{"nicknames": false}, true)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens","score":7,"beta":true}}}`, out)
+	})
+	t.Run("unknown excluded by default", func(t *testing.T) {
+		out := resolve(t, nil, false)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens"}}}`, out)
+	})
+}
+
+func TestResolvable_IncludeDataPresentExtension(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{Name: []byte("user"), Value: &Object{Path: []string{"user"}, Nullable: true, Fields: []*Field{{Name: []byte("name"), Value: &String{Path: []string{"name"}}}}}},
+			{Name: []byte("version"), Value: &String{Path: []string{"version"}, Nullable: true}},
+		},
+	}
+	resolve := func(t *testing.T, data string) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.IncludeDataPresentExtension = true
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("populated", func(t *testing.T) {
+		out := resolve(t, `{"user":{"name":"Jens"},"version":null}`)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens"},"version":null},"extensions":{"dataPresent":true}}`, out)
+	})
+	t.Run("all null", func(t *testing.T) {
+		out := resolve(t, `{"user":null}`)
+		assert.Equal(t, `{"data":{"user":null,"version":null},"extensions":{"dataPresent":false}}`, out)
+	})
+	t.Run("errors only", func(t *testing.T) {
+		out := resolve(t, `{"user":{}}`)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.name'.","path":["user","name"]}],"data":{"user":null,"version":null},"extensions":{"dataPresent":false}}`, out)
+	})
+}
+
+func TestResolvable_OneBasedArrayIndices(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("list"),
+				Value: &Array{
+					Path: []string{"list"},
+					Item: &Object{
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, oneBased bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.OneBasedArrayIndices = oneBased
+		err := res.Init(ctx, []byte(`{"list":[{"name":"a"},{},{"name":"c"}]}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.list.name'.","path":["list",1,"name"]}],"data":{"list":[{"name":"a"},null,{"name":"c"}]}}`, resolve(t, false))
+	assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.list.name'.","path":["list",2,"name"]}],"data":{"list":[{"name":"a"},null,{"name":"c"}]}}`, resolve(t, true))
+}
+
+func TestResolvable_ResolveCached(t *testing.T) {
+	resolve := func(t *testing.T, cached string, extensions []ResponseExtension) (string, error) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.ResponseExtensions = extensions
+		err := res.Init(ctx, nil, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.ResolveCached([]byte(cached), out)
+		return out.String(), err
+	}
+	cacheStatus := []ResponseExtension{
+		{
+			Key: "cache",
+			Render: func(ctx *Context, out io.Writer) error {
+				_, err := out.Write([]byte(`{"hit":true}`))
+				return err
+			},
+		},
+	}
+
+	t.Run("without extensions", func(t *testing.T) {
+		out, err := resolve(t, `{"data":{"hello":"world"}}`, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"world"}}`, out)
+	})
+	t.Run("with extensions", func(t *testing.T) {
+		out, err := resolve(t, `{"errors":[{"message":"boom"}],"data":{"hello":"world"}}`+"\n", cacheStatus)
+		assert.NoError(t, err)
+		assert.True(t, json.Valid([]byte(out)))
+		assert.Equal(t, `{"errors":[{"message":"boom"}],"data":{"hello":"world"},"extensions":{"cache":{"hit":true}}}`, out)
+	})
+	t.Run("empty object", func(t *testing.T) {
+		out, err := resolve(t, `{}`, cacheStatus)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"extensions":{"cache":{"hit":true}}}`, out)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := resolve(t, `[]`, cacheStatus)
+		assert.EqualError(t, err, "cached response must be a JSON object")
+	})
+	t.Run("tracing", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.TracingOptions.Enable = true
+		ctx.TracingOptions.IncludeTraceOutputInResponseExtensions = true
+		ctx.ResponseExtensions = cacheStatus
+		err := res.Init(ctx, nil, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.ResolveCached([]byte(`{"data":{"hello":"world"}}`), out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"world"},"extensions":{"cache":{"hit":true}}}`, out.String())
+	})
+}
+
+func TestResolvable_ResolveErrors(t *testing.T) {
+	resolve := func(t *testing.T, nullDataOnErrors bool, graphQLErrors ...GraphQLError) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.NullDataOnErrors = nullDataOnErrors
+		err := res.Init(ctx, nil, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.ResolveErrors(graphQLErrors, out)
+		assert.NoError(t, err)
+		assert.True(t, res.WroteErrorsWithoutData())
+		return out.String()
+	}
+
+	t.Run("single error", func(t *testing.T) {
+		out := resolve(t, false, GraphQLError{Message: `Cannot query field "foo" on type "Query".`, Locations: []Location{{Line: 1, Column: 3}}})
+		assert.Equal(t, `{"errors":[{"message":"Cannot query field \"foo\" on type \"Query\".","locations":[{"line":1,"column":3}]}]}`, out)
+	})
+	t.Run("multiple errors", func(t *testing.T) {
+		out := resolve(t, true,
+			GraphQLError{Message: `Variable "$id" of required type "ID!" was not provided.`},
+			GraphQLError{Message: "<invalid>", Path: []any{"user", 0}, Extensions: map[string]any{"code": "BAD_USER_INPUT"}},
+		)
+		assert.Equal(t, `{"errors":[{"message":"Variable \"$id\" of required type \"ID!\" was not provided."},{"message":"<invalid>","path":["user",0],"extensions":{"code":"BAD_USER_INPUT"}}],"data":null}`, out)
+	})
+	t.Run("tracing", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.TracingOptions.Enable = true
+		ctx.TracingOptions.IncludeTraceOutputInResponseExtensions = true
+		err := res.Init(ctx, nil, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.ResolveErrors([]GraphQLError{{Message: "boom"}}, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"boom"}]}`, out.String())
+	})
+}
+
+func TestResolvable_MergeEntities(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("me"),
+				Value: &Object{
+					Path: []string{"me"},
+					Fields: []*Field{
+						{
+							Name: []byte("reviews"),
+							Value: &Array{
+								Path: []string{"reviews"},
+								Item: &Object{
+									Fields: []*Field{
+										{
+											Name: []byte("product"),
+											Value: &Object{
+												Path: []string{"product"},
+												Fields: []*Field{
+													{Name: []byte("upc"), Value: &String{Path: []string{"upc"}}},
+													{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	targetPaths := [][]astjson.PathElement{
+		{{Name: "me"}, {Name: "reviews"}, {ArrayIndex: 0}, {Name: "product"}},
+		{{Name: "me"}, {Name: "reviews"}, {ArrayIndex: 1}, {Name: "product"}},
+		{{Name: "me"}, {Name: "reviews"}, {ArrayIndex: 2}, {Name: "product"}},
+	}
+	data := `{"me":{"reviews":[{"product":{"__typename":"Product","upc":"top-1"}},{"product":{"__typename":"Product","upc":"top-2"}},{"product":{"__typename":"Product","upc":"top-3"}}]}}`
+
+	t.Run("entities", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		err = res.MergeEntities([]byte(`{"data":{"_entities":[{"name":"Trilby"},{"name":"Fedora"},{"name":"Boater"}]}}`), targetPaths)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"me":{"reviews":[{"product":{"upc":"top-1","name":"Trilby"}},{"product":{"upc":"top-2","name":"Fedora"}},{"product":{"upc":"top-3","name":"Boater"}}]}}}`, out.String())
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		err = res.MergeEntities([]byte(`[{"name":"Trilby"}]`), targetPaths)
+		assert.EqualError(t, err, "got 1 entities for 3 target paths")
+		err = res.MergeEntities([]byte(`[{"name":"Trilby"}]`), [][]astjson.PathElement{{{Name: "me"}, {Name: "reviews"}, {ArrayIndex: 3}}})
+		assert.EqualError(t, err, "no placeholder object for entity 0")
+	})
+}
+
+func TestResolvable_LargeIntAsString(t *testing.T) {
+	resolve := func(t *testing.T, data string, threshold int64) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.LargeIntAsString = true
+		ctx.LargeIntThreshold = threshold
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("int"), Value: &Integer{Path: []string{"int"}}},
+				{Name: []byte("bigInt"), Value: &BigInt{Path: []string{"bigInt"}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("below 2^53", func(t *testing.T) {
+		out := resolve(t, `{"int":9007199254740991,"bigInt":-9007199254740991}`, 0)
+		assert.Equal(t, `{"data":{"int":9007199254740991,"bigInt":-9007199254740991}}`, out)
+	})
+	t.Run("above 2^53", func(t *testing.T) {
+		out := resolve(t, `{"int":9007199254740993,"bigInt":-9007199254740993}`, 0)
+		assert.Equal(t, `{"data":{"int":"9007199254740993","bigInt":"-9007199254740993"}}`, out)
+	})
+	t.Run("BigInt beyond int64", func(t *testing.T) {
+		out := resolve(t, `{"int":1,"bigInt":123456789012345678901234567890}`, 0)
+		assert.Equal(t, `{"data":{"int":1,"bigInt":"123456789012345678901234567890"}}`, out)
+	})
+	t.Run("BigInt string", func(t *testing.T) {
+		out := resolve(t, `{"int":1,"bigInt":"123456789012345678901234567890"}`, 0)
+		assert.Equal(t, `{"data":{"int":1,"bigInt":"123456789012345678901234567890"}}`, out)
+	})
+	t.Run("custom threshold", func(t *testing.T) {
+		out := resolve(t, `{"int":1000,"bigInt":1001}`, 1000)
+		assert.Equal(t, `{"data":{"int":1000,"bigInt":"1001"}}`, out)
+	})
+}
+
+func TestResolvable_WithCatapultTracing(t *testing.T) {
+	res := NewResolvable()
+	background := SetTraceStart(context.Background(), true)
+	SetPlannerStats(background, PhaseStats{DurationNano: 2000, DurationSinceStartNano: 1000})
+	ctx := NewContext(background)
+	ctx.TracingOptions.Enable = true
+	ctx.TracingOptions.EnablePredictableDebugTimings = true
+	ctx.TracingOptions.IncludeTraceOutputInResponseExtensions = true
+	ctx.TracingOptions.Format = TraceFormatCatapultJSON
+	err := res.Init(ctx, []byte(`{"hello":"world"}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fetch: &SingleFetch{
+			Info: &FetchInfo{DataSourceID: "products"},
+			Trace: &DataSourceLoadTrace{
+				DurationSinceStartNano: 30000,
+				DurationLoadNano:       5000,
+				Path:                   "query",
+			},
+		},
+		Fields: []*Field{
+			{Name: []byte("hello"), Value: &String{Path: []string{"hello"}}},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(ctx.ctx, object, object, out)
+	assert.NoError(t, err)
+
+	var response struct {
+		Extensions struct {
+			Trace []CatapultTraceEvent `json:"trace"`
+		} `json:"extensions"`
+	}
+	err = json.Unmarshal(out.Bytes(), &response)
+	assert.NoError(t, err)
+	events := response.Extensions.Trace
+	assert.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, "X", event.Phase)
+		assert.NotEmpty(t, event.Name)
+	}
+	assert.Equal(t, "plan", events[0].Name)
+	assert.Equal(t, "phase", events[0].Category)
+	assert.Equal(t, "products", events[1].Name)
+	assert.Equal(t, "fetch", events[1].Category)
+	assert.Equal(t, float64(30), events[1].Timestamp)
+	assert.Equal(t, float64(5), events[1].Duration)
+	assert.Equal(t, "query", events[1].Args["path"])
+}
+
+func TestResolvable_ResolveAndValidateSchema(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"required": ["user"],
+		"properties": {
+			"user": {
+				"type": "object",
+				"required": ["id", "age"],
+				"properties": {
+					"id": {"type": "string"},
+					"age": {"type": "integer", "minimum": 0}
+				}
+			}
+		}
+	}`)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path:     []string{"user"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+						{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, data string) []SchemaViolation {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		violations, err := res.ResolveAndValidateSchema(object, schema)
+		assert.NoError(t, err)
+		return violations
+	}
+
+	t.Run("conforming", func(t *testing.T) {
+		violations := resolve(t, `{"user":{"id":"1","age":42}}`)
+		assert.Empty(t, violations)
+	})
+	t.Run("non-conforming", func(t *testing.T) {
+		violations := resolve(t, `{"user":{"id":"1","age":-1}}`)
+		assert.Equal(t, []SchemaViolation{{Path: "/user/age", Message: "must be >= 0 but found -1"}}, violations)
+	})
+	t.Run("null bubbles up", func(t *testing.T) {
+		violations := resolve(t, `{"user":{"id":null,"age":1}}`)
+		assert.Equal(t, []SchemaViolation{{Path: "/user", Message: "expected object, but got null"}}, violations)
+	})
+	t.Run("invalid schema", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), []byte(`{}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		_, err = res.ResolveAndValidateSchema(object, []byte(`{"type": 1}`))
+		assert.Error(t, err)
+	})
+	t.Run("tracing", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.TracingOptions.Enable = true
+		ctx.TracingOptions.IncludeTraceOutputInResponseExtensions = true
+		err := res.Init(ctx, []byte(`{"user":{"id":"1","age":42}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		violations, err := res.ResolveAndValidateSchema(object, schema)
+		assert.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+}
+
+func TestResolvable_StrictExtraFields(t *testing.T) {
+	resolve := func(t *testing.T, strict bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.StrictExtraFields = strict
+		err := res.Init(ctx, []byte(`{"user":{"__typename":"User","id":"1","name":"Jens","email":"jens@example.com"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+							{Name: []byte("fullName"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		out := resolve(t, true)
+		assert.Equal(t, `{"data":{"user":{"id":"1","fullName":"Jens"}},"extensions":{"warnings":[{"message":"Unexpected field 'email' in the response of the subgraph.","path":["user","email"]}]}}`, out)
+	})
+	t.Run("lenient", func(t *testing.T) {
+		out := resolve(t, false)
+		assert.Equal(t, `{"data":{"user":{"id":"1","fullName":"Jens"}}}`, out)
+	})
+}
+
+type countingCustomResolve struct {
+	calls int
+	err   error
+}
+
+func (c *countingCustomResolve) Resolve(ctx *Context, value []byte) ([]byte, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []byte(`"` + strings.ToUpper(string(value)) + `"`), nil
+}
+
+func TestResolvable_LazyCustomNodes(t *testing.T) {
+	resolve := func(t *testing.T, lazy bool, custom *countingCustomResolve) (string, error) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.LazyCustomNodes = lazy
+		err := res.Init(ctx, []byte(`{"custom":"abc"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("custom"), Value: &CustomNode{CustomResolve: custom, Path: []string{"custom"}, Nullable: true}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		return out.String(), err
+	}
+
+	t.Run("lazy", func(t *testing.T) {
+		custom := &countingCustomResolve{}
+		out, err := resolve(t, true, custom)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"custom":"ABC"}}`, out)
+		assert.Equal(t, 1, custom.calls)
+	})
+	t.Run("eager", func(t *testing.T) {
+		custom := &countingCustomResolve{}
+		out, err := resolve(t, false, custom)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"custom":"ABC"}}`, out)
+		assert.Equal(t, 2, custom.calls)
+	})
+	t.Run("lazy error", func(t *testing.T) {
+		custom := &countingCustomResolve{err: errors.New("custom failed")}
+		out, err := resolve(t, true, custom)
+		assert.EqualError(t, err, "custom failed")
+		assert.Equal(t, 1, custom.calls)
+		// the response is aborted after the data key, so it must be discarded
+		assert.Equal(t, `{"data":`, out)
+		assert.False(t, json.Valid([]byte(out)))
+	})
+	t.Run("eager error", func(t *testing.T) {
+		custom := &countingCustomResolve{err: errors.New("custom failed")}
+		out, err := resolve(t, false, custom)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, custom.calls)
+		assert.Equal(t, `{"errors":[{"message":"custom failed","path":["custom"]}],"data":null}`, out)
+	})
+}
+
+func TestResolvable_MaxArrayItems(t *testing.T) {
+	resolve := func(t *testing.T, data string, maxItems int) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.MaxArrayItems = maxItems
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("items"),
+					Value: &Array{
+						Path: []string{"items"},
+						Item: &Object{
+							Fields: []*Field{
+								{Name: []byte("id"), Value: &Integer{Path: []string{"id"}}},
+							},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		items := make([]string, 100)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"id":%d}`, i)
+		}
+		out := resolve(t, `{"items":[`+strings.Join(items, ",")+`]}`, 3)
+		assert.Equal(t, `{"data":{"items":[{"id":0},{"id":1},{"id":2}]},"extensions":{"warnings":[{"message":"List truncated to 3 items, 97 items omitted.","path":["items"]}]}}`, out)
+	})
+	t.Run("invalid items beyond the limit are not resolved", func(t *testing.T) {
+		out := resolve(t, `{"items":[{"id":1},{"id":2},{"id":null}]}`, 2)
+		assert.Equal(t, `{"data":{"items":[{"id":1},{"id":2}]},"extensions":{"warnings":[{"message":"List truncated to 2 items, 1 items omitted.","path":["items"]}]}}`, out)
+	})
+	t.Run("non-null item in the prefix", func(t *testing.T) {
+		out := resolve(t, `{"items":[{"id":1},{"id":null},{"id":3}]}`, 2)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.items.id'.","path":["items",1,"id"]}],"data":null,"extensions":{"warnings":[{"message":"List truncated to 2 items, 1 items omitted.","path":["items"]}]}}`, out)
+	})
+	t.Run("within the limit", func(t *testing.T) {
+		out := resolve(t, `{"items":[{"id":1},{"id":2}]}`, 2)
+		assert.Equal(t, `{"data":{"items":[{"id":1},{"id":2}]}}`, out)
+	})
+	t.Run("shared storage keeps all items", func(t *testing.T) {
+		storage := &astjson.JSON{}
+		dataRoot, err := storage.AppendObject([]byte(`{"items":[{"id":1},{"id":2},{"id":3}]}`))
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("items"),
+					Value: &Array{
+						Path: []string{"items"},
+						Item: &Object{
+							Fields: []*Field{
+								{Name: []byte("id"), Value: &Integer{Path: []string{"id"}}},
+							},
+						},
+					},
+				},
+			},
+		}
+		resolveShared := func(t *testing.T, maxItems int) string {
+			res := NewResolvableWithStorage(storage)
+			ctx := NewContext(context.Background())
+			ctx.MaxArrayItems = maxItems
+			err := res.InitWithDataRoot(ctx, dataRoot, ast.OperationTypeQuery)
+			assert.NoError(t, err)
+			out := &bytes.Buffer{}
+			err = res.Resolve(context.Background(), object, nil, out)
+			assert.NoError(t, err)
+			return out.String()
+		}
+		assert.Equal(t, `{"data":{"items":[{"id":1}]},"extensions":{"warnings":[{"message":"List truncated to 1 items, 2 items omitted.","path":["items"]}]}}`, resolveShared(t, 1))
+		assert.Equal(t, `{"data":{"items":[{"id":1},{"id":2},{"id":3}]}}`, resolveShared(t, 0))
+	})
+}
+
+// decodeMsgpack decodes the subset of MessagePack written by ResolveMsgpack
+func decodeMsgpack(t *testing.T, data []byte) (any, []byte) {
+	t.Helper()
+	readLength := func(size int) int {
+		var n int
+		for i := 0; i < size; i++ {
+			n = n<<8 | int(data[1+i])
+		}
+		return n
+	}
+	b := data[0]
+	switch {
+	case b <= 0x7f:
+		return int64(b), data[1:]
+	case b >= 0xe0:
+		return int64(int8(b)), data[1:]
+	case b&0xf0 == 0x80:
+		return decodeMsgpackMap(t, data[1:], int(b&0x0f))
+	case b&0xf0 == 0x90:
+		return decodeMsgpackArray(t, data[1:], int(b&0x0f))
+	case b&0xe0 == 0xa0:
+		n := int(b & 0x1f)
+		return string(data[1 : 1+n]), data[1+n:]
+	}
+	switch b {
+	case 0xc0:
+		return nil, data[1:]
+	case 0xc2:
+		return false, data[1:]
+	case 0xc3:
+		return true, data[1:]
+	case 0xd0:
+		return int64(int8(data[1])), data[2:]
+	case 0xd1:
+		return int64(int16(readLength(2))), data[3:]
+	case 0xd2:
+		return int64(int32(readLength(4))), data[5:]
+	case 0xd3:
+		return int64(readLength(8)), data[9:]
+	case 0xcf:
+		var n uint64
+		for i := 1; i <= 8; i++ {
+			n = n<<8 | uint64(data[i])
+		}
+		return n, data[9:]
+	case 0xcb:
+		var n uint64
+		for i := 1; i <= 8; i++ {
+			n = n<<8 | uint64(data[i])
+		}
+		return math.Float64frombits(n), data[9:]
+	case 0xd9:
+		n := readLength(1)
+		return string(data[2 : 2+n]), data[2+n:]
+	case 0xda:
+		n := readLength(2)
+		return string(data[3 : 3+n]), data[3+n:]
+	case 0xdc:
+		return decodeMsgpackArray(t, data[3:], readLength(2))
+	case 0xde:
+		return decodeMsgpackMap(t, data[3:], readLength(2))
+	}
+	t.Fatalf("unexpected msgpack type 0x%x", b)
+	return nil, nil
+}
+
+func decodeMsgpackMap(t *testing.T, data []byte, n int) (any, []byte) {
+	out := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		var key, value any
+		key, data = decodeMsgpack(t, data)
+		value, data = decodeMsgpack(t, data)
+		out[key.(string)] = value
+	}
+	return out, data
+}
+
+func decodeMsgpackArray(t *testing.T, data []byte, n int) (any, []byte) {
+	out := make([]any, n)
+	for i := range out {
+		out[i], data = decodeMsgpack(t, data)
+	}
+	return out, data
+}
+
+func TestResolvable_ResolveMsgpack(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path:     []string{"user"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("bio"), Value: &String{Path: []string{"bio"}, Nullable: true}},
+						{Name: []byte("age"), Value: &Integer{Path: []string{"age"}}},
+						{Name: []byte("balance"), Value: &Float{Path: []string{"balance"}}},
+						{Name: []byte("followers"), Value: &BigInt{Path: []string{"followers"}}},
+						{Name: []byte("active"), Value: &Boolean{Path: []string{"active"}}},
+						{Name: []byte("tags"), Value: &Array{Path: []string{"tags"}, Item: &String{}}},
+						{Name: []byte("score"), Value: &Integer{Path: []string{"score"}, Nullable: true}},
+					},
+				},
+			},
+		},
+	}
+	// the msgpack response must decode to the same structure as the JSON response
+	roundTrip := func(t *testing.T, data string, expected string) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.ResolveMsgpack(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+
+		decoded, rest := decodeMsgpack(t, out.Bytes())
+		assert.Empty(t, rest)
+		actual, err := json.Marshal(decoded)
+		assert.NoError(t, err)
+
+		var expectedValue any
+		decoder := json.NewDecoder(strings.NewReader(expected))
+		decoder.UseNumber()
+		assert.NoError(t, decoder.Decode(&expectedValue))
+		expectedJSON, err := json.Marshal(expectedValue)
+		assert.NoError(t, err)
+		assert.Equal(t, string(expectedJSON), string(actual))
+	}
+
+	t.Run("data", func(t *testing.T) {
+		roundTrip(t,
+			`{"user":{"name":"Jens \"jensneuse\" Neuse é","bio":null,"age":-300,"balance":12.5,"followers":18446744073709551615,"active":true,"tags":["a","b"],"score":70000}}`,
+			`{"data":{"user":{"name":"Jens \"jensneuse\" Neuse é","bio":null,"age":-300,"balance":12.5,"followers":18446744073709551615,"active":true,"tags":["a","b"],"score":70000}}}`,
+		)
+	})
+	t.Run("errors", func(t *testing.T) {
+		roundTrip(t,
+			`{"user":{"name":null,"age":1,"balance":1,"followers":1,"active":false,"tags":[]}}`,
+			`{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.name'.","path":["user","name"]}],"data":{"user":null}}`,
+		)
+	})
+	t.Run("number out of the range of float64", func(t *testing.T) {
+		roundTrip(t,
+			`{"user":{"name":"a","age":1,"balance":1e400,"followers":-1e400,"active":true,"tags":[]}}`,
+			`{"data":{"user":{"name":"a","bio":null,"age":1,"balance":"1e400","followers":"-1e400","active":true,"tags":[],"score":null}}}`,
+		)
+	})
+	t.Run("matches the JSON response", func(t *testing.T) {
+		data := `{"user":{"name":"a","age":5,"balance":0.25,"followers":-9007199254740993,"active":false,"tags":["x"],"score":null}}`
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		roundTrip(t, data, out.String())
+	})
+	t.Run("extensions", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), []byte(`{"user":null}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		res.AppendWarning([]string{"user"}, "deprecated")
+		out := &bytes.Buffer{}
+		err = res.ResolveMsgpack(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		decoded, _ := decodeMsgpack(t, out.Bytes())
+		assert.Equal(t, map[string]any{
+			"data": map[string]any{"user": nil},
+			"extensions": map[string]any{
+				"warnings": []any{map[string]any{"message": "deprecated", "path": []any{"user"}}},
+			},
+		}, decoded)
+	})
+	t.Run("context options", func(t *testing.T) {
+		name := &Object{
+			Fields: []*Field{
+				{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+			},
+		}
+		resolve := func(t *testing.T, data string, configure func(ctx *Context), msgpack bool) string {
+			res := NewResolvable()
+			ctx := NewContext(context.Background())
+			walks := 0
+			ctx.BeforeDataWalk = func() {
+				walks++
+			}
+			configure(ctx)
+			err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+			assert.NoError(t, err)
+			out := &bytes.Buffer{}
+			if !msgpack {
+				err = res.Resolve(context.Background(), name, nil, out)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, walks)
+				return out.String()
+			}
+			err = res.ResolveMsgpack(context.Background(), name, nil, out)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, walks)
+			decoded, rest := decodeMsgpack(t, out.Bytes())
+			assert.Empty(t, rest)
+			actual, err := json.Marshal(decoded)
+			assert.NoError(t, err)
+			return string(actual)
+		}
+
+		t.Run("simplify single error", func(t *testing.T) {
+			configure := func(ctx *Context) {
+				ctx.SimplifySingleError = true
+			}
+			assert.Equal(t, `{"error":"Cannot return null for non-nullable field 'Query.name'."}`, resolve(t, `{"name":null}`, configure, false))
+			assert.Equal(t, `{"error":"Cannot return null for non-nullable field 'Query.name'."}`, resolve(t, `{"name":null}`, configure, true))
+		})
+		t.Run("incremental envelope", func(t *testing.T) {
+			configure := func(ctx *Context) {
+				ctx.IncrementalEnvelope = true
+			}
+			assert.Equal(t, `{"data":{"name":"Jens"},"hasNext":false}`, resolve(t, `{"name":"Jens"}`, configure, false))
+			assert.Equal(t, `{"data":{"name":"Jens"},"hasNext":false}`, resolve(t, `{"name":"Jens"}`, configure, true))
+		})
+	})
+}
+
+func TestResolvable_StrictTypeName(t *testing.T) {
+	resolve := func(t *testing.T, data string, strict bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.StrictTypeName = strict
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("pet"),
+					Value: &Object{
+						Path:     []string{"pet"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{Name: []byte("barks"), Value: &Boolean{Path: []string{"barks"}}, OnTypeNames: [][]byte{[]byte("Dog")}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("string", func(t *testing.T) {
+		out := resolve(t, `{"pet":{"__typename":"Dog","name":"Rex","barks":true}}`, true)
+		assert.Equal(t, `{"data":{"pet":{"name":"Rex","barks":true}}}`, out)
+	})
+	t.Run("number", func(t *testing.T) {
+		out := resolve(t, `{"pet":{"__typename":42,"name":"Rex","barks":true}}`, true)
+		assert.Equal(t, `{"errors":[{"message":"invalid __typename value: 42","path":["pet","__typename"]}],"data":{"pet":null}}`, out)
+	})
+	t.Run("null", func(t *testing.T) {
+		out := resolve(t, `{"pet":{"__typename":null,"name":"Rex","barks":true}}`, true)
+		assert.Equal(t, `{"errors":[{"message":"invalid __typename value: null","path":["pet","__typename"]}],"data":{"pet":null}}`, out)
+	})
+	t.Run("absent", func(t *testing.T) {
+		out := resolve(t, `{"pet":{"name":"Rex","barks":true}}`, true)
+		assert.Equal(t, `{"data":{"pet":{"name":"Rex"}}}`, out)
+	})
+	t.Run("lenient number", func(t *testing.T) {
+		out := resolve(t, `{"pet":{"__typename":42,"name":"Rex","barks":true}}`, false)
+		assert.Equal(t, `{"data":{"pet":{"name":"Rex"}}}`, out)
+	})
+}
+
+func TestResolvable_DataWalkCallbacks(t *testing.T) {
+	resolve := func(t *testing.T, data string) []string {
+		var calls []string
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.BeforeDataWalk = func() {
+			calls = append(calls, "before")
+		}
+		ctx.AfterDataWalk = func(hadError bool) {
+			calls = append(calls, fmt.Sprintf("after hadError=%t", hadError))
+		}
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return calls
+	}
+
+	t.Run("success", func(t *testing.T) {
+		assert.Equal(t, []string{"before", "after hadError=false"}, resolve(t, `{"name":"Jens"}`))
+	})
+	t.Run("error", func(t *testing.T) {
+		assert.Equal(t, []string{"before", "after hadError=true"}, resolve(t, `{"name":null}`))
+	})
+}
+
+func TestResolvable_ErrorLocations(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"user":{"id":"1","name":null}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Info: &FieldInfo{Name: "user", ExactParentTypeName: "Query", Line: 1, Column: 3},
+				Value: &Object{
+					Path:     []string{"user"},
+					Nullable: true,
+					Fields: []*Field{
+						{
+							Name:  []byte("id"),
+							Info:  &FieldInfo{Name: "id", ExactParentTypeName: "User", Line: 2, Column: 5},
+							Value: &String{Path: []string{"id"}},
+						},
+						{
+							Name:  []byte("name"),
+							Info:  &FieldInfo{Name: "name", ExactParentTypeName: "User", Line: 3, Column: 5},
+							Value: &String{Path: []string{"name"}},
+						},
+					},
+				},
+			},
+			{
+				// fields without a location render errors without locations
+				Name:  []byte("count"),
+				Value: &Integer{Path: []string{"count"}},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.name'.","locations":[{"line":3,"column":5}],"path":["user","name"]},{"message":"Cannot return null for non-nullable field 'Query.count'.","path":["count"]}],"data":null}`, out.String())
+}
+
+func TestResolvable_FileRef(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"documents":[{"name":"a.pdf","file":"file-1"},{"name":"b.pdf","file":null},{"name":"c.pdf","file":"file-3"}]}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("documents"),
+				Value: &Array{
+					Path: []string{"documents"},
+					Item: &Object{
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{Name: []byte("file"), Value: &FileRef{Path: []string{"file"}, Nullable: true}},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"documents":[{"name":"a.pdf","file":0},{"name":"b.pdf","file":null},{"name":"c.pdf","file":1}]}}`, out.String())
+	assert.Equal(t, []FileRefPart{
+		{Index: 0, Path: []any{"documents", 0, "file"}, Value: "file-1"},
+		{Index: 1, Path: []any{"documents", 2, "file"}, Value: "file-3"},
+	}, res.FileRefs())
+
+	res.Reset()
+	assert.Empty(t, res.FileRefs())
+
+	t.Run("aliases", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), []byte(`{"document":{"file":"file-1"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("a"), Value: &Object{Path: []string{"document"}, Fields: []*Field{{Name: []byte("file"), Value: &FileRef{Path: []string{"file"}}}}}},
+				{Name: []byte("b"), Value: &Object{Path: []string{"document"}, Fields: []*Field{{Name: []byte("file"), Value: &FileRef{Path: []string{"file"}}}}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"a":{"file":0},"b":{"file":1}}}`, out.String())
+		assert.Equal(t, []FileRefPart{
+			{Index: 0, Path: []any{"document", "file"}, Value: "file-1"},
+			{Index: 1, Path: []any{"document", "file"}, Value: "file-1"},
+		}, res.FileRefs())
+	})
+}
+
+func TestResolvable_ErrorsJSON(t *testing.T) {
+	postProcessing := PostProcessingConfiguration{
+		SelectResponseDataPath:   []string{"data"},
+		SelectResponseErrorsPath: []string{"errors"},
+	}
+
+	t.Run("subgraph errors", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.InitSubscription(NewContext(context.Background()), []byte(`{"data":{"user":null},"errors":[{"message":"boom","path":["user"]},{"message":"bang"}]}`), postProcessing)
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"message":"boom","path":["user"]},{"message":"bang"}]`, string(res.ErrorsJSON()))
+	})
+	t.Run("no errors", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.InitSubscription(NewContext(context.Background()), []byte(`{"data":{"user":null}}`), postProcessing)
+		assert.NoError(t, err)
+		assert.Nil(t, res.ErrorsJSON())
+	})
+	t.Run("input errors", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), nil, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		res.AppendInputError([]string{"input"}, "invalid input")
+		assert.Equal(t, `[{"message":"invalid input","path":["input"]}]`, string(res.ErrorsJSON()))
+	})
+}
+
+func TestResolvable_InitSubscriptionFromDataSource(t *testing.T) {
+	postProcessing := PostProcessingConfiguration{
+		SelectResponseDataPath:   []string{"data"},
+		SelectResponseErrorsPath: []string{"errors"},
+	}
+	data := []byte(`{"data":{"user":null},"errors":[{"message":"boom","path":["user"]}]}`)
+
+	t.Run("prefixed errors", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.PrefixSubgraphErrors = true
+		err := res.InitSubscriptionFromDataSource(ctx, "Users", data, postProcessing)
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"message":"[Users] boom","path":["user"]}]`, string(res.ErrorsJSON()))
+	})
+	t.Run("without PrefixSubgraphErrors", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.InitSubscriptionFromDataSource(NewContext(context.Background()), "Users", data, postProcessing)
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"message":"boom","path":["user"]}]`, string(res.ErrorsJSON()))
+	})
+	t.Run("without data source", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.PrefixSubgraphErrors = true
+		err := res.InitSubscription(ctx, data, postProcessing)
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"message":"boom","path":["user"]}]`, string(res.ErrorsJSON()))
+	})
+}
+
+// unbufferedWriter counts the writes, e.g. the syscalls of a writer to a connection
+type unbufferedWriter struct {
+	writes int
+	bytes  int
+}
+
+func (w *unbufferedWriter) Write(p []byte) (int, error) {
+	w.writes++
+	w.bytes += len(p)
+	return len(p), nil
+}
+
+func TestResolvable_UnbufferedWriter(t *testing.T) {
+	object, data := wideRootObject(10, 100)
+	resolve := func(out io.Writer) {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), data, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+	}
+	expected := &bytes.Buffer{}
+	resolve(expected)
+	out := &unbufferedWriter{}
+	resolve(out)
+	assert.Equal(t, expected.Len(), out.bytes)
+	assert.Equal(t, (expected.Len()+printChunkSize-1)/printChunkSize, out.writes)
+}
+
+func BenchmarkResolvable_UnbufferedWriter(b *testing.B) {
+	object, data := wideRootObject(20, 500)
+	res := NewResolvable()
+	b.Run("buffered", func(b *testing.B) {
+		benchmarkResolvableWriter(b, res, object, data, func() io.Writer { return &bytes.Buffer{} })
+	})
+	b.Run("unbuffered", func(b *testing.B) {
+		benchmarkResolvableWriter(b, res, object, data, func() io.Writer { return &unbufferedWriter{} })
+	})
+}
+
+func benchmarkResolvableWriter(b *testing.B, res *Resolvable, object *Object, data []byte, newWriter func() io.Writer) {
+	out := newWriter()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res.Reset()
+		if err := res.Init(NewContext(context.Background()), data, ast.OperationTypeQuery); err != nil {
+			b.Fatal(err)
+		}
+		if err := res.Resolve(context.Background(), object, nil, out); err != nil {
+			b.Fatal(err)
+		}
+		if buf, ok := out.(*bytes.Buffer); ok {
+			buf.Reset()
+		}
+	}
+	if w, ok := out.(*unbufferedWriter); ok {
+		b.ReportMetric(float64(w.writes)/float64(b.N), "writes/op")
+	}
+}
+
+func TestResolvable_Enum(t *testing.T) {
+	resolve := func(t *testing.T, data string, enum *Enum) string {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		enum.Path = []string{"status"}
+		enum.TypeName = "Status"
+		enum.AllowedValues = []string{"ACTIVE", "INACTIVE", "UNKNOWN"}
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("status"), Value: enum},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("allowed value", func(t *testing.T) {
+		out := resolve(t, `{"status":"ACTIVE"}`, &Enum{})
+		assert.Equal(t, `{"data":{"status":"ACTIVE"}}`, out)
+	})
+	t.Run("non-string value", func(t *testing.T) {
+		out := resolve(t, `{"status":1}`, &Enum{Nullable: true})
+		assert.Equal(t, `{"errors":[{"message":"Enum \"Status\" cannot represent non-string value: 1","path":["status"]}],"data":null}`, out)
+	})
+	t.Run("unknown value with error strategy", func(t *testing.T) {
+		out := resolve(t, `{"status":"SUSPENDED"}`, &Enum{Nullable: true, UnknownValueStrategy: EnumUnknownValueError})
+		assert.Equal(t, `{"errors":[{"message":"Enum \"Status\" cannot represent value: \"SUSPENDED\"","path":["status"]}],"data":null}`, out)
+	})
+	t.Run("unknown value with null strategy", func(t *testing.T) {
+		out := resolve(t, `{"status":"SUSPENDED"}`, &Enum{Nullable: true, UnknownValueStrategy: EnumUnknownValueNull})
+		assert.Equal(t, `{"data":{"status":null}}`, out)
+	})
+	t.Run("unknown non-nullable value with null strategy", func(t *testing.T) {
+		out := resolve(t, `{"status":"SUSPENDED"}`, &Enum{UnknownValueStrategy: EnumUnknownValueNull})
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.status'.","path":["status"]}],"data":null}`, out)
+	})
+	t.Run("unknown value with default strategy", func(t *testing.T) {
+		out := resolve(t, `{"status":"SUSPENDED"}`, &Enum{UnknownValueStrategy: EnumUnknownValueDefault, DefaultValue: "UNKNOWN"})
+		assert.Equal(t, `{"data":{"status":"UNKNOWN"}}`, out)
+	})
+}
+
+func TestResolvable_RequiredVariables(t *testing.T) {
+	resolve := func(t *testing.T, variables string, nullable bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.Variables = []byte(variables)
+		err := res.Init(ctx, []byte(`{"user":{"name":"Jens"},"version":"1.0"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name:              []byte("user"),
+					RequiredVariables: []string{"id"},
+					Value: &Object{
+						Path:     []string{"user"},
+						Nullable: nullable,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+				{Name: []byte("version"), Value: &String{Path: []string{"version"}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("present", func(t *testing.T) {
+		out := resolve(t, `{"id":"1"}`, true)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens"},"version":"1.0"}}`, out)
+	})
+	t.Run("missing", func(t *testing.T) {
+		out := resolve(t, `{"other":"1"}`, true)
+		assert.Equal(t, `{"errors":[{"message":"Variable \"$id\" required by field 'user' was not provided.","path":["user"]}],"data":{"user":null,"version":"1.0"}}`, out)
+	})
+	t.Run("no variables", func(t *testing.T) {
+		out := resolve(t, ``, true)
+		assert.Equal(t, `{"errors":[{"message":"Variable \"$id\" required by field 'user' was not provided.","path":["user"]}],"data":{"user":null,"version":"1.0"}}`, out)
+	})
+	t.Run("missing on non-nullable field", func(t *testing.T) {
+		out := resolve(t, `{}`, false)
+		assert.Equal(t, `{"errors":[{"message":"Variable \"$id\" required by field 'user' was not provided.","path":["user"]}],"data":null}`, out)
+	})
+	t.Run("remaining fields of the nullable object are walked", func(t *testing.T) {
+		res := NewResolvable()
+		err := res.Init(NewContext(context.Background()), []byte(`{"me":{"user":"Jens"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("me"),
+					Value: &Object{
+						Path:     []string{"me"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("user"), RequiredVariables: []string{"id"}, Value: &String{Path: []string{"user"}}},
+							{Name: []byte("age"), Value: &Integer{Path: []string{"age"}}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"Variable \"$id\" required by field 'user' was not provided.","path":["me","user"]},{"message":"Cannot return null for non-nullable field 'Query.me.age'.","path":["me","age"]}],"data":{"me":null}}`, out.String())
+	})
+}
+
+func TestResolvable_OnNullBubble(t *testing.T) {
+	type bubble struct {
+		path   string
+		reason string
+	}
+	var bubbles []bubble
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.OnNullBubble = func(path string, reason string) {
+		bubbles = append(bubbles, bubble{path: path, reason: reason})
+	}
+	err := res.Init(ctx, []byte(`{"user":{"friends":[{"name":"Jens"},{"name":null}],"address":{"city":null}}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{
+							Name: []byte("friends"),
+							Value: &Array{
+								Path:     []string{"friends"},
+								Nullable: true,
+								Item: &Object{
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+						},
+						{
+							Name: []byte("address"),
+							Value: &Object{
+								Path:     []string{"address"},
+								Nullable: true,
+								Fields: []*Field{
+									{Name: []byte("city"), Value: &String{Path: []string{"city"}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.friends.name'.","path":["user","friends",1,"name"]},{"message":"Cannot return null for non-nullable field 'Query.user.address.city'.","path":["user","address","city"]}],"data":{"user":{"friends":null,"address":null}}}`, out.String())
+	assert.Equal(t, []bubble{
+		{path: "user.friends", reason: "Cannot return null for non-nullable field 'Query.user.friends.name'."},
+		{path: "user.address", reason: "Cannot return null for non-nullable field 'Query.user.address.city'."},
+	}, bubbles)
+}
+
+func TestResolvable_Aliases(t *testing.T) {
+	// Field.Name is the response key, which is the alias if present, and the path of the value is the key in the data
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("me"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("displayName"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("kind"), Value: &String{Path: []string{"__typename"}, IsTypeName: true}},
+					},
+				},
+			},
+			{
+				Name: []byte("author"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+					},
+				},
+			},
+		},
+	}
+	data := `{"user":{"__typename":"User","id":"1","name":"Jens"}}`
+	expected := `{"data":{"me":{"name":"Jens","displayName":"Jens","kind":"User"},"author":{"id":"1"}}}`
+
+	for name, configure := range map[string]func(ctx *Context){
+		"pass-through": func(ctx *Context) {},
+		"walk": func(ctx *Context) {
+			ctx.OnResolveObject = func(typeName, path string) {}
+		},
+		"parallel root fields": func(ctx *Context) {
+			ctx.ParallelRootFields = true
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res := NewResolvable()
+			ctx := NewContext(context.Background())
+			configure(ctx)
+			err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+			assert.NoError(t, err)
+			out := &bytes.Buffer{}
+			err = res.Resolve(context.Background(), object, nil, out)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, out.String())
+		})
+	}
+}
+
+func TestResolvable_AliasedFieldAuthorization(t *testing.T) {
+	var coordinates []GraphCoordinate
+	authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+		coordinates = append(coordinates, coordinate)
+		return nil, nil
+	})
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.SetAuthorizer(authorizer)
+	err := res.Init(ctx, []byte(`{"user":{"__typename":"User","name":"Jens"}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{
+							Name:  []byte("displayName"),
+							Value: &String{Path: []string{"name"}},
+							Info: &FieldInfo{
+								Name:                 "name",
+								ExactParentTypeName:  "User",
+								Source:               TypeFieldSource{IDs: []string{"users"}},
+								HasAuthorizationRule: true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"user":{"displayName":"Jens"}}}`, out.String())
+	// the authorizer is called with the schema name of the field, not the alias
+	assert.Equal(t, []GraphCoordinate{{TypeName: "User", FieldName: "name"}}, coordinates)
+}
+
+func TestResolvable_IncrementalEnvelope(t *testing.T) {
+	resolve := func(t *testing.T, data string, envelope bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.IncrementalEnvelope = envelope
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		out := resolve(t, `{"name":"Jens"}`, true)
+		assert.Equal(t, `{"data":{"name":"Jens"},"hasNext":false}`, out)
+	})
+	t.Run("enabled with errors", func(t *testing.T) {
+		out := resolve(t, `{"name":null}`, true)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.name'.","path":["name"]}],"data":null,"hasNext":false}`, out)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		out := resolve(t, `{"name":"Jens"}`, false)
+		assert.Equal(t, `{"data":{"name":"Jens"}}`, out)
+	})
+}
+
+func TestResolvable_MaxBytes(t *testing.T) {
+	resolve := func(t *testing.T, data string, value Node) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("bio"), Value: value},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("under limit", func(t *testing.T) {
+		out := resolve(t, `{"bio":"hello"}`, &String{Path: []string{"bio"}, MaxBytes: 5})
+		assert.Equal(t, `{"data":{"bio":"hello"}}`, out)
+	})
+	t.Run("over limit", func(t *testing.T) {
+		out := resolve(t, `{"bio":"hello world"}`, &String{Path: []string{"bio"}, MaxBytes: 5})
+		assert.Equal(t, `{"data":{"bio":"hello"},"extensions":{"warnings":[{"message":"String value truncated from 11 to 5 bytes.","path":["bio"]}]}}`, out)
+	})
+	t.Run("multibyte character on boundary", func(t *testing.T) {
+		out := resolve(t, `{"bio":"abäöü"}`, &String{Path: []string{"bio"}, MaxBytes: 5})
+		assert.Equal(t, `{"data":{"bio":"abä"},"extensions":{"warnings":[{"message":"String value truncated from 8 to 5 bytes.","path":["bio"]}]}}`, out)
+	})
+	t.Run("escape sequence on boundary", func(t *testing.T) {
+		out := resolve(t, `{"bio":"abä\"cd"}`, &String{Path: []string{"bio"}, MaxBytes: 5})
+		assert.Equal(t, `{"data":{"bio":"abä"},"extensions":{"warnings":[{"message":"String value truncated from 8 to 5 bytes.","path":["bio"]}]}}`, out)
+	})
+	t.Run("surrogate pair on boundary", func(t *testing.T) {
+		out := resolve(t, `{"bio":"a\ud83d\ude00b"}`, &String{Path: []string{"bio"}, MaxBytes: 10})
+		assert.Equal(t, `{"data":{"bio":"a"},"extensions":{"warnings":[{"message":"String value truncated from 14 to 10 bytes.","path":["bio"]}]}}`, out)
+	})
+	t.Run("error mode", func(t *testing.T) {
+		out := resolve(t, `{"bio":"hello world"}`, &String{Path: []string{"bio"}, MaxBytes: 5, MaxBytesMode: MaxBytesError})
+		assert.Equal(t, `{"errors":[{"message":"String value of 11 bytes exceeds the limit of 5 bytes.","path":["bio"]}],"data":null}`, out)
+	})
+	t.Run("scalar", func(t *testing.T) {
+		out := resolve(t, `{"bio":"hello world"}`, &Scalar{Path: []string{"bio"}, MaxBytes: 5})
+		assert.Equal(t, `{"data":{"bio":"hello"},"extensions":{"warnings":[{"message":"String value truncated from 11 to 5 bytes.","path":["bio"]}]}}`, out)
+	})
+	t.Run("scalar with non-string value", func(t *testing.T) {
+		out := resolve(t, `{"bio":{"text":"hello world"}}`, &Scalar{Path: []string{"bio"}, MaxBytes: 5})
+		assert.Equal(t, `{"data":{"bio":{"text":"hello world"}}}`, out)
+	})
+}
+
+func TestResolvable_FieldPresenceCollector(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.FieldPresenceCollector = NewFieldPresenceCollector()
+	err := res.Init(ctx, []byte(`{"users":[{"__typename":"User","name":"Jens","email":null},{"__typename":"Admin","name":null},{"__typename":"User"}]}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Info: &FieldInfo{Name: "users", ExactParentTypeName: "Query"},
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Fields: []*Field{
+							{
+								Name:  []byte("name"),
+								Info:  &FieldInfo{Name: "name", ExactParentTypeName: "User"},
+								Value: &String{Path: []string{"name"}, Nullable: true},
+							},
+							{
+								Name:  []byte("mail"),
+								Info:  &FieldInfo{Name: "email", ExactParentTypeName: "User"},
+								Value: &String{Path: []string{"email"}, Nullable: true},
+							},
+							{
+								Name:  []byte("id"),
+								Value: &String{Path: []string{"id"}, Nullable: true},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"users":[{"name":"Jens","mail":null,"id":null},{"name":null,"mail":null,"id":null},{"name":null,"mail":null,"id":null}]}}`, out.String())
+	assert.Equal(t, map[GraphCoordinate]FieldPresence{
+		{TypeName: "Query", FieldName: "users"}: {Present: 1},
+		{TypeName: "User", FieldName: "name"}:   {Present: 1, Absent: 1},
+		{TypeName: "Admin", FieldName: "name"}:  {Null: 1},
+		{TypeName: "User", FieldName: "email"}:  {Null: 1, Absent: 1},
+		{TypeName: "Admin", FieldName: "email"}: {Absent: 1},
+	}, ctx.FieldPresenceCollector.Fields())
+}
+
+func TestResolvable_ErrorSink(t *testing.T) {
+	var sunk []GraphQLError
+	authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+		if coordinate.FieldName == "email" {
+			return &AuthorizationDeny{Reason: "not allowed"}, nil
+		}
+		return nil, nil
+	})
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.SetAuthorizer(authorizer)
+	ctx.ErrorSink = func(err GraphQLError) {
+		sunk = append(sunk, err)
+	}
+	err := res.Init(ctx, []byte(`{"users":[{"__typename":"User","name":"Jens","age":"old","email":"jens@example.com"},{"__typename":"User","name":null,"age":1}]}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{
+								Name:  []byte("email"),
+								Value: &String{Path: []string{"email"}, Nullable: true},
+								Info: &FieldInfo{
+									Name:                 "email",
+									ExactParentTypeName:  "User",
+									Source:               TypeFieldSource{IDs: []string{"users"}},
+									HasAuthorizationRule: true,
+								},
+							},
+							{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"errors":[{"message":"Unauthorized to load field 'Query.users.email', Reason: not allowed.","path":["users",0,"email"]},{"message":"Int cannot represent non-integer value: \"old\"","path":["users",0,"age"]},{"message":"Cannot return null for non-nullable field 'Query.users.name'.","path":["users",1,"name"]}],"data":{"users":[null,null]}}`, out.String())
+	assert.Equal(t, []GraphQLError{
+		{Message: "Unauthorized to load field 'Query.users.email', Reason: not allowed.", Path: []any{"users", 0, "email"}},
+		{Message: `Int cannot represent non-integer value: "old"`, Path: []any{"users", 0, "age"}},
+		{Message: "Cannot return null for non-nullable field 'Query.users.name'.", Path: []any{"users", 1, "name"}},
+	}, sunk)
+}
+
+func TestResolvable_BooleanAsInt(t *testing.T) {
+	resolve := func(t *testing.T, data string, booleanAsInt bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.BooleanAsInt = booleanAsInt
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("active"), Value: &Boolean{Path: []string{"active"}}},
+				{Name: []byte("admin"), Value: &Boolean{Path: []string{"admin"}, Nullable: true}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		out := resolve(t, `{"active":true,"admin":false}`, false)
+		assert.Equal(t, `{"data":{"active":true,"admin":false}}`, out)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		out := resolve(t, `{"active":true,"admin":false}`, true)
+		assert.Equal(t, `{"data":{"active":1,"admin":0}}`, out)
+	})
+	t.Run("null", func(t *testing.T) {
+		out := resolve(t, `{"active":true,"admin":null}`, true)
+		assert.Equal(t, `{"data":{"active":1,"admin":null}}`, out)
+	})
+	t.Run("non-boolean value", func(t *testing.T) {
+		out := resolve(t, `{"active":1,"admin":false}`, true)
+		assert.Equal(t, `{"errors":[{"message":"Bool cannot represent non-boolean value: \"1\"","path":["active"]}],"data":null}`, out)
+	})
+}
+
+func TestResolvable_UnescapeDepth(t *testing.T) {
+	resolve := func(t *testing.T, data string, value *String) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("config"), Value: value},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("single", func(t *testing.T) {
+		out := resolve(t, `{"config":"{\"a\":1}"}`, &String{Path: []string{"config"}, UnescapeResponseJson: true, UnescapeDepth: 2})
+		assert.Equal(t, `{"data":{"config":{"a":1}}}`, out)
+	})
+	t.Run("double", func(t *testing.T) {
+		out := resolve(t, `{"config":"\"{\\\"a\\\":\\\"b\\\"}\""}`, &String{Path: []string{"config"}, UnescapeResponseJson: true, UnescapeDepth: 2})
+		assert.Equal(t, `{"data":{"config":{"a":"b"}}}`, out)
+	})
+	t.Run("double with depth 1", func(t *testing.T) {
+		out := resolve(t, `{"config":"\"{\\\"a\\\":\\\"b\\\"}\""}`, &String{Path: []string{"config"}, UnescapeResponseJson: true, StrictUnescape: true})
+		assert.Equal(t, `{"data":{"config":"{\"a\":\"b\"}"}}`, out)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		out := resolve(t, `{"config":"\"{\\\"a\\\":\""}`, &String{Path: []string{"config"}, UnescapeResponseJson: true, UnescapeDepth: 2})
+		assert.Equal(t, `{"data":{"config":"{\"a\":"}}`, out)
+	})
+	t.Run("malformed first level", func(t *testing.T) {
+		out := resolve(t, `{"config":"{a"}`, &String{Path: []string{"config"}, UnescapeResponseJson: true, UnescapeDepth: 2})
+		assert.Equal(t, `{"data":{"config":"{a"}}`, out)
+	})
+	t.Run("malformed strict", func(t *testing.T) {
+		out := resolve(t, `{"config":"\"{\\\"a\\\":\""}`, &String{Path: []string{"config"}, Nullable: true, UnescapeResponseJson: true, UnescapeDepth: 2, StrictUnescape: true})
+		assert.Equal(t, `{"errors":[{"message":"String cannot represent invalid nested JSON value: \"\\\"{\\\\\\\"a\\\\\\\":\\\"\"","path":["config"]}],"data":null}`, out)
+	})
+}
+
+func TestResolvable_TraceFieldSources(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.TraceFieldSources = true
+	err := res.Init(ctx, []byte(`{"users":[{"name":"Jens","reviews":[{"body":"great"}]},{"name":"Nick","reviews":[]}]}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Info: &FieldInfo{Name: "users", Source: TypeFieldSource{IDs: []string{"accounts"}}},
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Fields: []*Field{
+							{
+								Name:  []byte("name"),
+								Info:  &FieldInfo{Name: "name", Source: TypeFieldSource{IDs: []string{"accounts"}}},
+								Value: &String{Path: []string{"name"}},
+							},
+							{
+								Name: []byte("reviews"),
+								Info: &FieldInfo{Name: "reviews", Source: TypeFieldSource{IDs: []string{"reviews"}}},
+								Value: &Array{
+									Path: []string{"reviews"},
+									Item: &Object{
+										Fields: []*Field{
+											{
+												Name:  []byte("body"),
+												Info:  &FieldInfo{Name: "body", Source: TypeFieldSource{IDs: []string{"reviews", "legacy-reviews"}}},
+												Value: &String{Path: []string{"body"}},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"users":[{"name":"Jens","reviews":[{"body":"great"}]},{"name":"Nick","reviews":[]}]},"extensions":{"fieldSources":{"users":["accounts"],"users.0.name":["accounts"],"users.0.reviews":["reviews"],"users.0.reviews.0.body":["reviews","legacy-reviews"],"users.1.name":["accounts"],"users.1.reviews":["reviews"]}}}`, out.String())
+}
+
+func TestResolvable_UniqueErrorPaths(t *testing.T) {
+	resolve := func(t *testing.T, uniqueErrorPaths bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.UniqueErrorPaths = uniqueErrorPaths
+		err := res.Init(ctx, []byte(`{"user":{"name":"Jens","email":"jens@example.com"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("a"), RequiredVariables: []string{"id"}, Value: &String{Path: []string{"name"}, Nullable: true}},
+							{Name: []byte("b"), RequiredVariables: []string{"id"}, Value: &String{Path: []string{"name"}, Nullable: true}},
+							{Name: []byte("email"), RequiredVariables: []string{"id"}, Value: &String{Path: []string{"email"}, Nullable: true}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		out := resolve(t, false)
+		assert.Equal(t, `{"errors":[{"message":"Variable \"$id\" required by field 'a' was not provided.","path":["user","name"]},{"message":"Variable \"$id\" required by field 'b' was not provided.","path":["user","name"]},{"message":"Variable \"$id\" required by field 'email' was not provided.","path":["user","email"]}],"data":{"user":{"a":null,"b":null,"email":null}}}`, out)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		out := resolve(t, true)
+		assert.Equal(t, `{"errors":[{"message":"Variable \"$id\" required by field 'a' was not provided.","path":["user","name"]},{"message":"Variable \"$id\" required by field 'email' was not provided.","path":["user","email"]}],"data":{"user":{"a":null,"b":null,"email":null}}}`, out)
+	})
+	t.Run("soft errors are deduplicated separately", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.UniqueErrorPaths = true
+		err := res.Init(ctx, []byte(`{"user":{"name":"Jens"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		res.pushNodePathElement([]string{"user", "name"})
+		for i := 0; i < 2; i++ {
+			res.appendGraphQLError(res.errorsRoot, "error", ErrorCategoryValidation)
+			res.appendGraphQLError(res.softErrorsRoot, "soft error", ErrorCategoryValidation)
+		}
+		res.popNodePathElement([]string{"user", "name"})
+		assert.Len(t, res.storage.Nodes[res.errorsRoot].ArrayValues, 1)
+		assert.Len(t, res.storage.Nodes[res.softErrorsRoot].ArrayValues, 1)
+	})
+}
+
+type testArraySource struct {
+	arrays map[string]string
+}
+
+func (s *testArraySource) OpenArray(ctx *Context, value []byte) (io.Reader, error) {
+	array, ok := s.arrays[string(value)]
+	if !ok {
+		return nil, fmt.Errorf("unknown array %s", value)
+	}
+	return io.NopCloser(strings.NewReader(array)), nil
+}
+
+func TestResolvable_StreamedArray(t *testing.T) {
+	item := &Object{
+		Fields: []*Field{
+			{Name: []byte("id"), Value: &Integer{Path: []string{"id"}}},
+			{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+		},
+	}
+	resolve := func(t *testing.T, data string, users Node) (string, error) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("users"), Value: users},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		return out.String(), err
+	}
+
+	t.Run("large array", func(t *testing.T) {
+		users := &strings.Builder{}
+		users.WriteString("[")
+		for i := 0; i < 10000; i++ {
+			if i != 0 {
+				users.WriteString(",")
+			}
+			fmt.Fprintf(users, `{"id":%d,"name":"user %d","email":"user%d@example.com"}`, i, i, i)
+		}
+		users.WriteString("]")
+		source := &testArraySource{arrays: map[string]string{"all-users": users.String()}}
+
+		streamed, err := resolve(t, `{"users":"all-users"}`, &StreamedArray{Path: []string{"users"}, Item: item, Source: source})
+		assert.NoError(t, err)
+		inMemory, err := resolve(t, `{"users":`+users.String()+`}`, &Array{Path: []string{"users"}, Item: item})
+		assert.NoError(t, err)
+		assert.Equal(t, inMemory, streamed)
+	})
+	t.Run("empty array", func(t *testing.T) {
+		source := &testArraySource{arrays: map[string]string{"no-users": ` [ ] `}}
+		out, err := resolve(t, `{"users":"no-users"}`, &StreamedArray{Path: []string{"users"}, Item: item, Source: source})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"users":[]}}`, out)
+	})
+	t.Run("invalid item of nullable array", func(t *testing.T) {
+		source := &testArraySource{arrays: map[string]string{"users": `[{"id":1,"name":"Jens"},{"id":2,"name":null}]`}}
+		out, err := resolve(t, `{"users":"users"}`, &StreamedArray{Path: []string{"users"}, Nullable: true, Item: item, Source: source})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.users.name'.","path":["users",1,"name"]}],"data":{"users":null}}`, out)
+	})
+	t.Run("invalid item", func(t *testing.T) {
+		source := &testArraySource{arrays: map[string]string{"users": `[{"id":1,"name":"Jens"},{"id":2,"name":null}]`}}
+		out, err := resolve(t, `{"users":"users"}`, &StreamedArray{Path: []string{"users"}, Item: item, Source: source})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.users.name'.","path":["users",1,"name"]}],"data":null}`, out)
+	})
+	t.Run("items are not held in the storage", func(t *testing.T) {
+		users := &strings.Builder{}
+		users.WriteString("[")
+		for i := 0; i < 1000; i++ {
+			if i != 0 {
+				users.WriteString(",")
+			}
+			fmt.Fprintf(users, `{"id":%d,"name":"user %d"}`, i, i)
+		}
+		users.WriteString("]")
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(`{"users":"all-users"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("users"), Value: &StreamedArray{Path: []string{"users"}, Item: item, Source: &testArraySource{arrays: map[string]string{"all-users": users.String()}}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), `{"id":999,"name":"user 999"}]}}`)
+		assert.Less(t, len(res.storage.Nodes), 100)
+	})
+	t.Run("type transformers", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.TypeTransformers = map[string]func(objectData []byte) ([]byte, error){
+			"User": func(objectData []byte) ([]byte, error) {
+				return []byte(`{"name":"hidden"}`), nil
+			},
+		}
+		err := res.Init(ctx, []byte(`{"users":"users"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		source := &testArraySource{arrays: map[string]string{"users": `[{"__typename":"User","id":1,"name":"Jens"},{"__typename":"User","id":2,"name":"Dustin"},{"__typename":"User","id":3,"name":"Stefan"}]`}}
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("users"), Value: &StreamedArray{Path: []string{"users"}, Item: item, Source: source}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"users":[{"id":1,"name":"hidden"},{"id":2,"name":"hidden"},{"id":3,"name":"hidden"}]}}`, out.String())
+	})
+	t.Run("truncated array", func(t *testing.T) {
+		source := &testArraySource{arrays: map[string]string{"users": `[{"id":1,"name":"Jens"}`}}
+		_, err := resolve(t, `{"users":"users"}`, &StreamedArray{Path: []string{"users"}, Item: item, Source: source})
+		assert.Error(t, err)
+	})
+	t.Run("source error", func(t *testing.T) {
+		source := &testArraySource{}
+		_, err := resolve(t, `{"users":"users"}`, &StreamedArray{Path: []string{"users"}, Item: item, Source: source})
+		assert.EqualError(t, err, "unknown array users")
+	})
+}
+
+func TestResolvable_MaskInternalErrors(t *testing.T) {
+	var sunk []GraphQLError
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.MaskInternalErrors = true
+	ctx.ErrorSink = func(err GraphQLError) {
+		sunk = append(sunk, err)
+	}
+	err := res.Init(ctx, []byte(`{"user":{"name":"Jens","age":"old"},"account":{"id":null}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path:     []string{"user"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("age"), Value: &Integer{Path: []string{"age"}}},
+					},
+				},
+			},
+			{
+				Name: []byte("account"),
+				Value: &Object{
+					Path:     []string{"account"},
+					Nullable: true,
+					Fields: []*Field{
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+
+	assert.Len(t, sunk, 2)
+	assert.Equal(t, `Int cannot represent non-integer value: "old"`, sunk[0].Message)
+	assert.Equal(t, "Cannot return null for non-nullable field 'Query.account.id'.", sunk[1].Message)
+	ageReferenceID, ok := sunk[0].Extensions["referenceId"].(string)
+	assert.True(t, ok)
+	idReferenceID, ok := sunk[1].Extensions["referenceId"].(string)
+	assert.True(t, ok)
+	assert.NotEqual(t, ageReferenceID, idReferenceID)
+
+	expected := `{"errors":[{"message":"Internal server error.","path":["user","age"],"extensions":{"referenceId":"` + ageReferenceID + `"}},` +
+		`{"message":"Internal server error.","path":["account","id"],"extensions":{"referenceId":"` + idReferenceID + `"}}],` +
+		`"data":{"user":null,"account":null}}`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestResolvable_TypeNameRewriter(t *testing.T) {
+	var coordinates []GraphCoordinate
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.RenameTypeNames = []RenameTypeName{{From: []byte("Admin"), To: []byte("Staff")}, {From: []byte("User"), To: []byte("Member")}}
+	ctx.TypeNameRewriter = func(original []byte, coordinate GraphCoordinate) []byte {
+		coordinates = append(coordinates, coordinate)
+		if coordinate.TypeName == "Account" && string(original) == "User" {
+			return []byte("TenantUser")
+		}
+		return nil
+	}
+	err := res.Init(ctx, []byte(`{"me":{"__typename":"User"},"accounts":[{"__typename":"User"},{"__typename":"Admin"}]}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("me"),
+				Value: &Object{
+					Path: []string{"me"},
+					Fields: []*Field{
+						{
+							Name:  []byte("__typename"),
+							Info:  &FieldInfo{Name: "__typename", ExactParentTypeName: "User"},
+							Value: &String{Path: []string{"__typename"}, IsTypeName: true},
+						},
+					},
+				},
+			},
+			{
+				Name: []byte("accounts"),
+				Value: &Array{
+					Path: []string{"accounts"},
+					Item: &Object{
+						Fields: []*Field{
+							{
+								Name:  []byte("__typename"),
+								Info:  &FieldInfo{Name: "__typename", ExactParentTypeName: "Account"},
+								Value: &String{Path: []string{"__typename"}, IsTypeName: true},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"me":{"__typename":"Member"},"accounts":[{"__typename":"TenantUser"},{"__typename":"Staff"}]}}`, out.String())
+	assert.Equal(t, []GraphCoordinate{
+		{TypeName: "User", FieldName: "__typename"},
+		{TypeName: "Account", FieldName: "__typename"},
+		{TypeName: "Account", FieldName: "__typename"},
+	}, coordinates)
+}
+
+func TestResolvable_TypeDiscriminator(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"pets":[{"name":"Rex","barks":true},{"name":"Tom","meows":true},{"name":"Nemo","__typename":"Fish"}]}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("pets"),
+				Value: &Array{
+					Path: []string{"pets"},
+					Item: &Object{
+						TypeDiscriminator: func(objectData []byte) string {
+							switch {
+							case bytes.Contains(objectData, []byte(`"barks"`)):
+								return "Dog"
+							case bytes.Contains(objectData, []byte(`"meows"`)):
+								return "Cat"
+							default:
+								return ""
+							}
+						},
+						Fields: []*Field{
+							{Name: []byte("__typename"), Value: &String{Path: []string{"__typename"}, IsTypeName: true}},
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{Name: []byte("barks"), OnTypeNames: [][]byte{[]byte("Dog")}, Value: &Boolean{Path: []string{"barks"}}},
+							{Name: []byte("meows"), OnTypeNames: [][]byte{[]byte("Cat")}, Value: &Boolean{Path: []string{"meows"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"pets":[{"__typename":"Dog","name":"Rex","barks":true},{"__typename":"Cat","name":"Tom","meows":true},{"__typename":"Fish","name":"Nemo"}]}}`, out.String())
+}
+
+func TestResolvable_ResolveFramed(t *testing.T) {
+	resolve := func(t *testing.T, data string) []byte {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.ResolveFramed(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.Bytes()
+	}
+
+	t.Run("data", func(t *testing.T) {
+		out := resolve(t, `{"name":"Jens"}`)
+		assert.Equal(t, []byte{0, 0, 0, 24}, out[:4])
+		assert.Equal(t, `{"data":{"name":"Jens"}}`, string(out[4:]))
+		assert.Equal(t, len(out)-4, int(binary.BigEndian.Uint32(out[:4])))
+	})
+	t.Run("large response", func(t *testing.T) {
+		out := resolve(t, `{"name":"`+strings.Repeat("a", 70000)+`"}`)
+		assert.Equal(t, len(out)-4, int(binary.BigEndian.Uint32(out[:4])))
+		assert.Equal(t, 70000+len(`{"data":{"name":""}}`), len(out)-4)
+	})
+	t.Run("errors", func(t *testing.T) {
+		out := resolve(t, `{"name":null}`)
+		assert.Equal(t, len(out)-4, int(binary.BigEndian.Uint32(out[:4])))
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.name'.","path":["name"]}],"data":null}`, string(out[4:]))
+	})
+}
+
+func TestResolvable_ConsolidateAuthDenials(t *testing.T) {
+	authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+		if coordinate.FieldName == "email" || coordinate.FieldName == "ssn" {
+			return &AuthorizationDeny{Reason: "not allowed"}, nil
+		}
+		return nil, nil
+	})
+	authorized := func(name string, nullable bool) *Field {
+		return &Field{
+			Name:  []byte(name),
+			Value: &String{Path: []string{name}, Nullable: nullable},
+			Info: &FieldInfo{
+				Name:                 name,
+				ExactParentTypeName:  "User",
+				Source:               TypeFieldSource{IDs: []string{"users"}},
+				HasAuthorizationRule: true,
+			},
+		}
+	}
+	resolve := func(t *testing.T, ssnNullable bool) (string, []GraphQLError) {
+		var sunk []GraphQLError
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SetAuthorizer(authorizer)
+		ctx.ConsolidateAuthDenials = true
+		ctx.ErrorSink = func(err GraphQLError) {
+			sunk = append(sunk, err)
+		}
+		err := res.Init(ctx, []byte(`{"users":[{"name":"Jens","email":"jens@example.com"},{"name":"Nick","email":"nick@example.com"}],"me":{"name":"Jens","ssn":"123"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("users"),
+					Value: &Array{
+						Path: []string{"users"},
+						Item: &Object{
+							Fields: []*Field{authorized("name", false), authorized("email", true)},
+						},
+					},
+				},
+				{
+					Name: []byte("me"),
+					Value: &Object{
+						Path:     []string{"me"},
+						Nullable: true,
+						Fields:   []*Field{authorized("name", false), authorized("ssn", ssnNullable)},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String(), sunk
+	}
+	denials := []GraphQLError{
+		{Message: "Unauthorized to load field 'Query.users.email', Reason: not allowed.", Path: []any{"users", 0, "email"}},
+		{Message: "Unauthorized to load field 'Query.users.email', Reason: not allowed.", Path: []any{"users", 1, "email"}},
+		{Message: "Unauthorized to load field 'Query.me.ssn', Reason: not allowed.", Path: []any{"me", "ssn"}},
+	}
+
+	t.Run("nullable fields", func(t *testing.T) {
+		out, sunk := resolve(t, true)
+		assert.Equal(t, `{"data":{"users":[{"name":"Jens","email":null},{"name":"Nick","email":null}],"me":{"name":"Jens","ssn":null}},"extensions":{"deniedFields":["users.0.email","users.1.email","me.ssn"]}}`, out)
+		assert.Equal(t, denials, sunk)
+	})
+	t.Run("non-nullable field", func(t *testing.T) {
+		out, sunk := resolve(t, false)
+		assert.Equal(t, `{"errors":[{"message":"Unauthorized to load field 'Query.me.ssn', Reason: not allowed.","path":["me","ssn"]}],"data":{"users":[{"name":"Jens","email":null},{"name":"Nick","email":null}],"me":null},"extensions":{"deniedFields":["users.0.email","users.1.email","me.ssn"]}}`, out)
+		assert.Equal(t, denials, sunk)
+	})
+}
+
+func TestResolvable_ErrorPathSeparator(t *testing.T) {
+	authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+		return &AuthorizationDeny{Reason: "not allowed"}, nil
+	})
+	resolve := func(t *testing.T, separator string) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SetAuthorizer(authorizer)
+		ctx.ErrorPathSeparator = separator
+		err := res.Init(ctx, []byte(`{"user":{"name":null,"email":"jens@example.com"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path:     []string{"user"},
+						Nullable: true,
+						Fields: []*Field{
+							{
+								Name:  []byte("email"),
+								Value: &String{Path: []string{"email"}, Nullable: true},
+								Info: &FieldInfo{
+									Name:                 "email",
+									ExactParentTypeName:  "User",
+									Source:               TypeFieldSource{IDs: []string{"users"}},
+									HasAuthorizationRule: true,
+								},
+							},
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("default", func(t *testing.T) {
+		out := resolve(t, "")
+		assert.Equal(t, `{"errors":[{"message":"Unauthorized to load field 'Query.user.email', Reason: not allowed.","path":["user","email"]},{"message":"Cannot return null for non-nullable field 'Query.user.name'.","path":["user","name"]}],"data":{"user":null}}`, out)
+	})
+	t.Run("slash", func(t *testing.T) {
+		out := resolve(t, "/")
+		assert.Equal(t, `{"errors":[{"message":"Unauthorized to load field 'Query/user/email', Reason: not allowed.","path":["user","email"]},{"message":"Cannot return null for non-nullable field 'Query/user/name'.","path":["user","name"]}],"data":{"user":null}}`, out)
+	})
+}
+
+func jsonScalarData(items int) []byte {
+	data := &bytes.Buffer{}
+	data.WriteString(`{"config":{"items":[`)
+	for i := 0; i < items; i++ {
+		if i != 0 {
+			data.WriteString(",")
+		}
+		fmt.Fprintf(data, `{"id":%d,"name":"item \"%d\"","enabled":%t,"tags":["a","b"],"meta":{"weight":%d.5,"parent":null}}`, i, i, i%2 == 0, i)
+	}
+	data.WriteString(`],"empty":{},"list":[]}}`)
+	return data.Bytes()
+}
+
+func TestResolvable_JSONScalar(t *testing.T) {
+	data := jsonScalarData(100)
+	object := &Object{
+		Fields: []*Field{
+			{Name: []byte("config"), Value: &Scalar{Path: []string{"config"}}},
+		},
+	}
+	resolve := func(t *testing.T, ctx *Context) (string, *Resolvable) {
+		res := NewResolvable()
+		err := res.Init(ctx, data, ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String(), res
+	}
+
+	passThrough, _ := resolve(t, NewContext(context.Background()))
+	walkCtx := NewContext(context.Background())
+	// OnResolveObject requires the full walk
+	walkCtx.OnResolveObject = func(typeName string, path string) {}
+	walk, res := resolve(t, walkCtx)
+	assert.Nil(t, res.passThroughRoot)
+
+	expected := `{"data":` + string(data) + `}`
+	assert.Equal(t, expected, passThrough)
+	assert.Equal(t, expected, walk)
+
+	// the resolved scalar is a copy, so nulling a value of the data doesn't change it
+	storage, dataRef := res.ResolvedData()
+	storage.Nodes[storage.Get(res.dataRoot, []string{"config", "empty"})].Kind = astjson.NodeKindNull
+	out := &bytes.Buffer{}
+	assert.NoError(t, storage.PrintNode(storage.Nodes[dataRef], out))
+	assert.Equal(t, string(data), out.String())
+}
+
+func BenchmarkResolvable_JSONScalar(b *testing.B) {
+	data := jsonScalarData(1024)
+	object := &Object{
+		Fields: []*Field{
+			{Name: []byte("config"), Value: &Scalar{Path: []string{"config"}}},
+		},
+	}
+	res := NewResolvable()
+	out := &bytes.Buffer{}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res.Reset()
+		ctx := NewContext(context.Background())
+		// OnResolveObject requires the full walk, which copies the scalar into the resolved tree
+		ctx.OnResolveObject = func(typeName string, path string) {}
+		if err := res.Init(ctx, data, ast.OperationTypeQuery); err != nil {
+			b.Fatal(err)
+		}
+		out.Reset()
+		if err := res.Resolve(context.Background(), object, nil, out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestResolvable_DefaultDeny(t *testing.T) {
+	authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+		return nil, nil
+	})
+	field := func(name string, hasAuthorizationRule bool, source string) *Field {
+		return &Field{
+			Name:  []byte(name),
+			Value: &String{Path: []string{name}, Nullable: true},
+			Info: &FieldInfo{
+				Name:                 name,
+				ExactParentTypeName:  "User",
+				Source:               TypeFieldSource{IDs: []string{source}},
+				HasAuthorizationRule: hasAuthorizationRule,
+			},
+		}
+	}
+	resolve := func(t *testing.T, defaultDeny, exemptMetaFields bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SetAuthorizer(authorizer)
+		ctx.DefaultDeny = defaultDeny
+		ctx.DefaultDenyExemptMetaFields = exemptMetaFields
+		ctx.TrustedDataSourceIDs = map[string]struct{}{"internal": {}}
+		err := res.Init(ctx, []byte(`{"user":{"__typename":"User","name":"Jens","email":"jens@example.com","id":"1"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							field("__typename", false, "users"),
+							field("name", true, "users"),
+							field("email", false, "users"),
+							field("id", false, "internal"),
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		out := resolve(t, false, false)
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","name":"Jens","email":"jens@example.com","id":"1"}}}`, out)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		out := resolve(t, true, false)
+		assert.Equal(t, `{"errors":[{"message":"Unauthorized to load field 'Query.user.__typename', Reason: field has no authorization rule.","path":["user","__typename"]},{"message":"Unauthorized to load field 'Query.user.email', Reason: field has no authorization rule.","path":["user","email"]}],"data":{"user":{"__typename":null,"name":"Jens","email":null,"id":"1"}}}`, out)
+	})
+	t.Run("exempt meta fields", func(t *testing.T) {
+		out := resolve(t, true, true)
+		assert.Equal(t, `{"errors":[{"message":"Unauthorized to load field 'Query.user.email', Reason: field has no authorization rule.","path":["user","email"]}],"data":{"user":{"__typename":"User","name":"Jens","email":null,"id":"1"}}}`, out)
+	})
+}
+
+func TestResolvable_ResolveIndexed(t *testing.T) {
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	err := res.Init(ctx, []byte(`{"user":{"name":"Jens","friends":[{"name":"Nick"},{"name":null}]}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{
+							Name: []byte("friends"),
+							Value: &Array{
+								Path: []string{"friends"},
+								Item: &Object{
+									Nullable: true,
+									Fields: []*Field{
+										{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	response, result, err := res.ResolveIndexed(context.Background(), object, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.friends.name'.","path":["user","friends",1,"name"]}],"data":{"user":{"name":"Jens","friends":[{"name":"Nick"},null]}}}`, string(response))
+	assert.Equal(t, string(response), result.Raw)
+	assert.Equal(t, "Jens", result.Get("data.user.name").String())
+	assert.Equal(t, "Nick", result.Get("data.user.friends.0.name").String())
+	assert.Equal(t, gjson.Null, result.Get("data.user.friends.1").Type)
+	assert.Equal(t, int64(1), result.Get("errors.0.path.2").Int())
+	assert.False(t, result.Get("extensions").Exists())
+}
+
+func TestResolvable_SuppressSoftErrorsTopLevel(t *testing.T) {
+	resolve := func(t *testing.T, data string) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SuppressSoftErrorsTopLevel = true
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path:     []string{"user"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("age"), Value: &Integer{Path: []string{"age"}, Nullable: true}},
+						},
+					},
+				},
+				{
+					Name: []byte("account"),
+					Value: &Object{
+						Path:     []string{"account"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("all soft", func(t *testing.T) {
+		out := resolve(t, `{"user":{"age":"old"},"account":{"id":"1"}}`)
+		assert.Equal(t, `{"data":{"user":null,"account":{"id":"1"}},"extensions":{"softErrors":[{"message":"Int cannot represent non-integer value: \"old\"","path":["user","age"]}]}}`, out)
+	})
+	t.Run("mixed", func(t *testing.T) {
+		out := resolve(t, `{"user":{"age":"old"},"account":{"id":null}}`)
+		assert.Equal(t, `{"errors":[{"message":"Int cannot represent non-integer value: \"old\"","path":["user","age"]},{"message":"Cannot return null for non-nullable field 'Query.account.id'.","path":["account","id"]}],"data":{"user":null,"account":null}}`, out)
+	})
+	t.Run("all hard", func(t *testing.T) {
+		out := resolve(t, `{"user":{"age":1},"account":{"id":null}}`)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.account.id'.","path":["account","id"]}],"data":{"user":{"age":1},"account":null}}`, out)
+	})
+}
+
+func TestResolvable_MaxObjectFields(t *testing.T) {
+	resolve := func(t *testing.T, data string, maxObjectFields int) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.MaxObjectFields = maxObjectFields
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+							{Name: []byte("email"), Value: &String{Path: []string{"email"}}},
+							{Name: []byte("age"), Value: &Integer{Path: []string{"age"}}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("under limit", func(t *testing.T) {
+		out := resolve(t, `{"user":{"id":"1","name":"Jens","email":"jens@example.com","age":33}}`, 4)
+		assert.Equal(t, `{"data":{"user":{"id":"1","name":"Jens","email":"jens@example.com","age":33}}}`, out)
+	})
+	t.Run("over limit", func(t *testing.T) {
+		out := resolve(t, `{"user":{"id":"1","name":"Jens","email":null,"age":33}}`, 2)
+		assert.Equal(t, `{"data":{"user":{"id":"1","name":"Jens"}},"extensions":{"warnings":[{"message":"Object truncated to 2 fields.","path":["user"]}]}}`, out)
+	})
+	t.Run("non-nullable field within limit", func(t *testing.T) {
+		out := resolve(t, `{"user":{"id":"1","name":null,"email":"jens@example.com","age":33}}`, 2)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.name'.","path":["user","name"]}],"data":null}`, out)
+	})
+	t.Run("root object", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.MaxObjectFields = 1
+		err := res.Init(ctx, []byte(`{"user":{"id":"1"},"me":{"id":"2"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("user"), Value: &Object{Path: []string{"user"}, Fields: []*Field{{Name: []byte("id"), Value: &String{Path: []string{"id"}}}}}},
+				{Name: []byte("me"), Value: &Object{Path: []string{"me"}, Fields: []*Field{{Name: []byte("id"), Value: &String{Path: []string{"id"}}}}}, Info: &FieldInfo{Line: 3, Column: 5}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"user":{"id":"1"}},"extensions":{"warnings":[{"message":"Object truncated to 1 fields."}]}}`, out.String())
+	})
+}
+
+func TestResolvable_SynthesizeEmptyResponseError(t *testing.T) {
+	resolve := func(t *testing.T, data string, synthesize bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.SynthesizeEmptyResponseError = synthesize
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("user"), Value: &Object{Path: []string{"user"}, Nullable: true}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("empty without flag", func(t *testing.T) {
+		out := resolve(t, `{}`, false)
+		assert.Equal(t, `{"data":{"user":null}}`, out)
+	})
+	t.Run("empty", func(t *testing.T) {
+		out := resolve(t, `{}`, true)
+		assert.Equal(t, `{"errors":[{"message":"No data resolved.","path":[]}],"data":{"user":null}}`, out)
+	})
+	t.Run("data", func(t *testing.T) {
+		out := resolve(t, `{"user":null}`, true)
+		assert.Equal(t, `{"data":{"user":null}}`, out)
+	})
+}
+
+func TestResolvable_RenderExtensions(t *testing.T) {
+	fetchTree := &Object{
+		Fields: []*Field{
+			{Name: []byte("hello"), Value: &String{Path: []string{"hello"}}},
+		},
+	}
+
+	t.Run("rate limit and trace", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.rateLimiter = &testRateLimiter{policy: "policy", allowed: 10}
+		ctx.RateLimitOptions = RateLimitOptions{Enable: true, IncludeStatsInResponseExtension: true}
+		ctx.TracingOptions.Enable = true
+		ctx.TracingOptions.IncludeTraceOutputInResponseExtensions = true
+		err := res.Init(ctx, []byte(`{"hello":"world"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.RenderExtensions(ctx.ctx, fetchTree, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"rateLimit":{"Policy":"policy","Allowed":10,"Used":0},"trace":{"node_type":"object","fields":[{"name":"hello","value":{"node_type":"string","path":["hello"]}}]}}`, out.String())
+	})
+	t.Run("no extensions", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(`{"hello":"world"}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.RenderExtensions(ctx.ctx, fetchTree, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `{}`, out.String())
+	})
+}
+
+func TestResolvable_FloatFormatter(t *testing.T) {
+	fixed := func(raw []byte) []byte {
+		value, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			return raw
+		}
+		return strconv.AppendFloat(nil, value, 'f', 2, 64)
+	}
+	resolve := func(t *testing.T, data string, formatter func(raw []byte) []byte) (string, error) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.FloatFormatter = formatter
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("price"), Value: &Float{Path: []string{"price"}}},
+				{Name: []byte("discount"), Value: &Float{Path: []string{"discount"}, Nullable: true}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		return out.String(), err
+	}
+
+	t.Run("default", func(t *testing.T) {
+		out, err := resolve(t, `{"price":12.3456,"discount":1e-1}`, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"price":12.3456,"discount":1e-1}}`, out)
+	})
+	t.Run("fixed decimals", func(t *testing.T) {
+		out, err := resolve(t, `{"price":12.3456,"discount":1e-1}`, fixed)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"price":12.35,"discount":0.10}}`, out)
+	})
+	t.Run("null", func(t *testing.T) {
+		out, err := resolve(t, `{"price":3,"discount":null}`, fixed)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"data":{"price":3.00,"discount":null}}`, out)
+	})
+	t.Run("invalid number", func(t *testing.T) {
+		_, err := resolve(t, `{"price":3,"discount":null}`, func(raw []byte) []byte {
+			return []byte(`"3"`)
+		})
+		assert.EqualError(t, err, `float formatter returned an invalid number: "3"`)
+	})
+}
+
+func TestResolvable_IncludeRetryableFields(t *testing.T) {
+	postProcessing := PostProcessingConfiguration{
+		SelectResponseDataPath:   []string{"data"},
+		SelectResponseErrorsPath: []string{"errors"},
+	}
+	object := &Object{
+		Fields: []*Field{
+			{Name: []byte("user"), Value: &Object{Path: []string{"user"}, Nullable: true}},
+			{Name: []byte("posts"), Value: &Object{Path: []string{"posts"}, Nullable: true}},
+			{Name: []byte("stats"), Value: &Object{Path: []string{"stats"}, Nullable: true}},
+		},
+	}
+	data := `{"data":{"user":null,"posts":null,"stats":null},"errors":[` +
+		`{"message":"timeout","path":["user"],"extensions":{"transient":true}},` +
+		`{"message":"not found","path":["posts"],"extensions":{"transient":false}},` +
+		`{"message":"invalid","path":["stats"]},` +
+		`{"message":"unavailable","extensions":{"transient":true}}]}`
+	resolve := func(t *testing.T, includeRetryableFields bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.IncludeRetryableFields = includeRetryableFields
+		err := res.InitSubscription(ctx, []byte(data), postProcessing)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+	response := `{"errors":[{"message":"timeout","path":["user"],"extensions":{"transient":true}},{"message":"not found","path":["posts"],"extensions":{"transient":false}},{"message":"invalid","path":["stats"]},{"message":"unavailable","extensions":{"transient":true}}],"data":{"user":null,"posts":null,"stats":null}`
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Equal(t, response+`}`, resolve(t, false))
+	})
+	t.Run("transient errors", func(t *testing.T) {
+		assert.Equal(t, response+`,"extensions":{"retryableFields":[["user"]]}}`, resolve(t, true))
+	})
+}
+
+// markerNodeEncoder wraps all string values in >> and << to prove that it's used
+type markerNodeEncoder struct{}
+
+func (e markerNodeEncoder) EncodeNode(storage *astjson.JSON, ref int, out io.Writer) error {
+	node := storage.Nodes[ref]
+	switch node.Kind {
+	case astjson.NodeKindString:
+		_, err := fmt.Fprintf(out, `">>%s<<"`, node.ValueBytes(storage))
+		return err
+	case astjson.NodeKindObject:
+		if _, err := out.Write([]byte(`{`)); err != nil {
+			return err
+		}
+		for i, field := range node.ObjectFields {
+			if i != 0 {
+				if _, err := out.Write([]byte(`,`)); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintf(out, `"%s":`, storage.ObjectFieldKey(field)); err != nil {
+				return err
+			}
+			if err := e.EncodeNode(storage, storage.ObjectFieldValue(field), out); err != nil {
+				return err
+			}
+		}
+		_, err := out.Write([]byte(`}`))
+		return err
+	case astjson.NodeKindArray:
+		if _, err := out.Write([]byte(`[`)); err != nil {
+			return err
+		}
+		for i, value := range node.ArrayValues {
+			if i != 0 {
+				if _, err := out.Write([]byte(`,`)); err != nil {
+					return err
+				}
+			}
+			if err := e.EncodeNode(storage, value, out); err != nil {
+				return err
+			}
+		}
+		_, err := out.Write([]byte(`]`))
+		return err
+	default:
+		return DefaultNodeEncoder{}.EncodeNode(storage, ref, out)
+	}
+}
+
+func TestResolvable_NodeEncoder(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+			{Name: []byte("age"), Value: &Integer{Path: []string{"age"}}},
+			{Name: []byte("tags"), Value: &Array{Path: []string{"tags"}, Item: &String{}}},
+		},
+	}
+	resolve := func(t *testing.T, encoder NodeEncoder, passThrough bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.NodeEncoder = encoder
+		if !passThrough {
+			ctx.OnResolveObject = func(typeName string, path string) {}
+		}
+		err := res.Init(ctx, []byte(`{"name":"Jens","age":42,"tags":["a","b"]}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("default", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"name":"Jens","age":42,"tags":["a","b"]}}`, resolve(t, nil, false))
+		assert.Equal(t, `{"data":{"name":"Jens","age":42,"tags":["a","b"]}}`, resolve(t, DefaultNodeEncoder{}, false))
+	})
+	t.Run("custom encoder", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"name":">>Jens<<","age":42,"tags":[">>a<<",">>b<<"]}}`, resolve(t, markerNodeEncoder{}, false))
+	})
+	t.Run("custom encoder with pass-through", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"name":">>Jens<<","age":42,"tags":[">>a<<",">>b<<"]}}`, resolve(t, markerNodeEncoder{}, true))
+	})
+	t.Run("called with the value of each root field", func(t *testing.T) {
+		expected := `{"data":{"name":/**/"Jens","age":/**/42,"tags":/**/["a","b"]}}`
+		assert.Equal(t, expected, resolve(t, callNodeEncoder{}, false))
+		assert.Equal(t, expected, resolve(t, callNodeEncoder{}, true))
+	})
+}
+
+// callNodeEncoder prefixes each encoded node with an empty comment to reveal the nodes it's called with
+type callNodeEncoder struct{}
+
+func (callNodeEncoder) EncodeNode(storage *astjson.JSON, ref int, out io.Writer) error {
+	if _, err := out.Write([]byte(`/**/`)); err != nil {
+		return err
+	}
+	return storage.PrintNode(storage.Nodes[ref], out)
+}
+
+func TestResolvable_IncludeFieldStatesExtension(t *testing.T) {
+	resolve := func(t *testing.T, data string, includeFieldStates bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.IncludeFieldStatesExtension = includeFieldStates
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path:     []string{"user"},
+						Nullable: true,
+						Fields: []*Field{
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}},
+							{Name: []byte("email"), Value: &String{Path: []string{"email"}, Nullable: true}},
+							{Name: []byte("phone"), Value: &String{Path: []string{"phone"}, Nullable: true}},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		out := resolve(t, `{"user":{"name":"Jens","email":null}}`, false)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens","email":null,"phone":null}}}`, out)
+	})
+	t.Run("absent, null and present fields", func(t *testing.T) {
+		out := resolve(t, `{"user":{"name":"Jens","email":null}}`, true)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens","email":null,"phone":null}},"extensions":{"fieldStates":{"user":"present","user.email":"null","user.name":"present","user.phone":"undefined"}}}`, out)
+	})
+	t.Run("null parent", func(t *testing.T) {
+		out := resolve(t, `{"user":null}`, true)
+		assert.Equal(t, `{"data":{"user":null},"extensions":{"fieldStates":{"user":"null"}}}`, out)
+	})
+}
+
+func TestResolvable_AuthorizationDecisions(t *testing.T) {
+	authorizer := createTestAuthorizer(nil, func(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error) {
+		if coordinate.FieldName == "email" {
+			return &AuthorizationDeny{Reason: "missing scope"}, nil
+		}
+		return nil, nil
+	})
+	authorized := func(name, parentTypeName, dataSourceID string) *Field {
+		return &Field{
+			Name:  []byte(name),
+			Value: &String{Path: []string{name}, Nullable: true},
+			Info: &FieldInfo{
+				Name:                 name,
+				ExactParentTypeName:  parentTypeName,
+				Source:               TypeFieldSource{IDs: []string{dataSourceID}},
+				HasAuthorizationRule: true,
+			},
+		}
+	}
+	res := NewResolvable()
+	ctx := NewContext(context.Background())
+	ctx.SetAuthorizer(authorizer)
+	err := res.Init(ctx, []byte(`{"users":[{"name":"Jens","email":"jens@example.com"},{"name":"Nick","email":"nick@example.com"}],"product":{"title":"Table","upc":"1"}}`), ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("users"),
+				Value: &Array{
+					Path: []string{"users"},
+					Item: &Object{
+						Fields: []*Field{authorized("name", "User", "users"), authorized("email", "User", "users")},
+					},
+				},
+			},
+			{
+				Name: []byte("product"),
+				Value: &Object{
+					Path:   []string{"product"},
+					Fields: []*Field{authorized("upc", "Product", "products"), authorized("title", "Product", "products")},
+				},
+			},
+		},
+	}
+	out := &bytes.Buffer{}
+	err = res.Resolve(context.Background(), object, nil, out)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []AuthorizationDecision{
+		{Coordinate: GraphCoordinate{TypeName: "User", FieldName: "name"}, DataSourceID: "users", Decision: AuthorizationDecisionAllow},
+		{Coordinate: GraphCoordinate{TypeName: "User", FieldName: "email"}, DataSourceID: "users", Decision: AuthorizationDecisionDeny, Reason: "missing scope"},
+		{Coordinate: GraphCoordinate{TypeName: "Product", FieldName: "upc"}, DataSourceID: "products", Decision: AuthorizationDecisionAllow},
+		{Coordinate: GraphCoordinate{TypeName: "Product", FieldName: "title"}, DataSourceID: "products", Decision: AuthorizationDecisionAllow},
+	}, res.AuthorizationDecisions())
+
+	res.Reset()
+	assert.Empty(t, res.AuthorizationDecisions())
+}
+
+func TestResolvable_NonNullFallback(t *testing.T) {
+	object := &Object{
+		Fields: []*Field{
+			{
+				Name: []byte("user"),
+				Value: &Object{
+					Path: []string{"user"},
+					Fields: []*Field{
+						{Name: []byte("name"), Value: &String{Path: []string{"name"}}},
+						{Name: []byte("email"), Value: &String{Path: []string{"email"}, Nullable: true}},
+					},
+				},
+			},
+		},
+	}
+	resolve := func(t *testing.T, data string, fallback func(path string, node Node) ([]byte, bool)) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.NonNullFallback = fallback
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+	var paths []string
+	fallback := func(path string, node Node) ([]byte, bool) {
+		paths = append(paths, path)
+		switch node.NodeKind() {
+		case NodeKindObject:
+			return []byte(`{"name":"unknown"}`), true
+		case NodeKindString:
+			return []byte(`""`), true
+		}
+		return nil, false
+	}
+
+	t.Run("without fallback", func(t *testing.T) {
+		out := resolve(t, `{"user":null}`, nil)
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user'.","path":["user"]}],"data":null}`, out)
+	})
+	t.Run("fallback object", func(t *testing.T) {
+		paths = nil
+		out := resolve(t, `{"user":null}`, fallback)
+		assert.Equal(t, `{"data":{"user":{"name":"unknown","email":null}}}`, out)
+		assert.Equal(t, []string{"user"}, paths)
+	})
+	t.Run("fallback leaf", func(t *testing.T) {
+		paths = nil
+		out := resolve(t, `{"user":{"email":"jens@example.com"}}`, fallback)
+		assert.Equal(t, `{"data":{"user":{"name":"","email":"jens@example.com"}}}`, out)
+		assert.Equal(t, []string{"user.name"}, paths)
+	})
+	t.Run("no fallback supplied", func(t *testing.T) {
+		out := resolve(t, `{"user":{"name":null}}`, func(path string, node Node) ([]byte, bool) {
+			return nil, false
+		})
+		assert.Equal(t, `{"errors":[{"message":"Cannot return null for non-nullable field 'Query.user.name'.","path":["user","name"]}],"data":null}`, out)
+	})
+}
+
+func TestResolvable_ComplexityScore(t *testing.T) {
+	weighted := func(name string, complexity int, value Node) *Field {
+		return &Field{
+			Name:  []byte(name),
+			Value: value,
+			Info:  &FieldInfo{Name: name, Complexity: complexity},
+		}
+	}
+	object := &Object{
+		Fields: []*Field{
+			weighted("users", 10, &Array{
+				Path: []string{"users"},
+				Item: &Object{
+					Nullable: true,
+					Fields: []*Field{
+						weighted("name", 1, &String{Path: []string{"name"}}),
+						weighted("posts", 5, &Array{
+							Path: []string{"posts"},
+							Item: &Object{
+								Fields: []*Field{
+									weighted("title", 2, &String{Path: []string{"title"}}),
+									{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+								},
+							},
+						}),
+					},
+				},
+			}),
+		},
+	}
+	resolve := func(t *testing.T, data string) int {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		score := res.ComplexityScore()
+		res.Reset()
+		assert.Equal(t, 0, res.ComplexityScore())
+		return score
+	}
+
+	t.Run("empty list", func(t *testing.T) {
+		assert.Equal(t, 10, resolve(t, `{"users":[]}`))
+	})
+	t.Run("nested lists", func(t *testing.T) {
+		// users: 10, 2 users with name and posts: 2*(1+5), 3 posts with title: 3*2
+		data := `{"users":[{"name":"Jens","posts":[{"id":"1","title":"a"},{"id":"2","title":"b"}]},{"name":"Nick","posts":[{"id":"3","title":"c"}]}]}`
+		assert.Equal(t, 10+2*(1+5)+3*2, resolve(t, data))
+	})
+	t.Run("null items", func(t *testing.T) {
+		data := `{"users":[null,{"name":"Nick","posts":[{"id":"3","title":"c"}]}]}`
+		assert.Equal(t, 10+(1+5)+2, resolve(t, data))
+	})
+}
+
+func TestResolvable_IncludeCacheStatus(t *testing.T) {
+	cached := func(name string, cacheHit bool, value Node) *Field {
+		return &Field{
+			Name:  []byte(name),
+			Value: value,
+			Info:  &FieldInfo{Name: name, CacheHit: cacheHit},
+		}
+	}
+	object := &Object{
+		Fields: []*Field{
+			cached("users", true, &Array{
+				Path: []string{"users"},
+				Item: &Object{
+					Fields: []*Field{
+						cached("name", true, &String{Path: []string{"name"}}),
+						cached("stock", false, &Integer{Path: []string{"stock"}}),
+						{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+					},
+				},
+			}),
+		},
+	}
+	resolve := func(t *testing.T, includeCacheStatus bool) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.IncludeCacheStatus = includeCacheStatus
+		err := res.Init(ctx, []byte(`{"users":[{"id":"1","name":"Jens","stock":1},{"id":"2","name":"Nick","stock":2}]}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"users":[{"name":"Jens","stock":1,"id":"1"},{"name":"Nick","stock":2,"id":"2"}]}}`, resolve(t, false))
+	})
+	t.Run("cache hits and misses", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"users":[{"name":"Jens","stock":1,"id":"1"},{"name":"Nick","stock":2,"id":"2"}]},"extensions":{"cacheStatus":{"users":"hit","users.0.name":"hit","users.0.stock":"miss","users.1.name":"hit","users.1.stock":"miss"}}}`, resolve(t, true))
+	})
+}
+
+func TestResolvable_NullMode(t *testing.T) {
+	resolve := func(t *testing.T, nullMode NullMode) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.NullMode = nullMode
+		err := res.Init(ctx, []byte(`{"user":{"id":"1","name":null},"post":null}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("user"),
+					Value: &Object{
+						Path: []string{"user"},
+						Fields: []*Field{
+							{Name: []byte("id"), Value: &String{Path: []string{"id"}}},
+							{Name: []byte("name"), Value: &String{Path: []string{"name"}, Nullable: true}},
+							{Name: []byte("email"), Value: &String{Path: []string{"email"}, Nullable: true}},
+						},
+					},
+				},
+				{Name: []byte("post"), Value: &Object{Path: []string{"post"}, Nullable: true}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("json null", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"user":{"id":"1","name":null,"email":null},"post":null}}`, resolve(t, NullModeJSONNull))
+	})
+	t.Run("omit key", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"user":{"id":"1"}}}`, resolve(t, NullModeOmitKey))
+	})
+	t.Run("empty string", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"user":{"id":"1","name":"","email":""},"post":""}}`, resolve(t, NullModeEmptyString))
+	})
+}
+
+func TestResolvable_NestedListDepth(t *testing.T) {
+	resolve := func(t *testing.T, data string) string {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(data), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		object := &Object{
+			Fields: []*Field{
+				{
+					Name: []byte("matrix"),
+					Value: &Array{
+						Path:     []string{"matrix"},
+						Nullable: true,
+						Item: &Array{
+							Nullable: true,
+							Item:     &Integer{Nullable: true},
+						},
+					},
+				},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("correct depth", func(t *testing.T) {
+		out := resolve(t, `{"matrix":[[1,2],null,[],[3,null]]}`)
+		assert.Equal(t, `{"data":{"matrix":[[1,2],null,[],[3,null]]}}`, out)
+	})
+	t.Run("too shallow", func(t *testing.T) {
+		out := resolve(t, `{"matrix":[1,2]}`)
+		assert.Equal(t, `{"errors":[{"message":"Expected nested list of depth 2, got depth 1.","path":["matrix"]}],"data":null}`, out)
+	})
+	t.Run("too deep", func(t *testing.T) {
+		out := resolve(t, `{"matrix":[[1],[[2]]]}`)
+		assert.Equal(t, `{"errors":[{"message":"Expected nested list of depth 2, got depth 3.","path":["matrix"]}],"data":null}`, out)
+	})
+}
+
+func TestResolvable_CustomNodeMemoization(t *testing.T) {
+	resolve := func(t *testing.T, res *Resolvable, custom *countingCustomResolve, id string) string {
+		ctx := NewContext(context.Background())
+		err := res.Init(ctx, []byte(`{"users":[{"name":"jens"},{"name":"nick"},{"name":"jens"}],"me":{"name":"jens"}}`), ast.OperationTypeQuery)
+		assert.NoError(t, err)
+		name := &Field{Name: []byte("name"), Value: &CustomNode{CustomResolve: custom, Path: []string{"name"}, ID: id}}
+		object := &Object{
+			Fields: []*Field{
+				{Name: []byte("users"), Value: &Array{Path: []string{"users"}, Item: &Object{Fields: []*Field{name}}}},
+				{Name: []byte("me"), Value: &Object{Path: []string{"me"}, Fields: []*Field{name}}},
+			},
+		}
+		out := &bytes.Buffer{}
+		err = res.Resolve(context.Background(), object, nil, out)
+		assert.NoError(t, err)
+		return out.String()
+	}
+	expected := `{"data":{"users":[{"name":"JENS"},{"name":"NICK"},{"name":"JENS"}],"me":{"name":"JENS"}}}`
+
+	t.Run("without id", func(t *testing.T) {
+		custom := &countingCustomResolve{}
+		assert.Equal(t, expected, resolve(t, NewResolvable(), custom, ""))
+		assert.Equal(t, 8, custom.calls)
+	})
+	t.Run("memoized per value", func(t *testing.T) {
+		custom := &countingCustomResolve{}
+		assert.Equal(t, expected, resolve(t, NewResolvable(), custom, "upper"))
+		assert.Equal(t, 2, custom.calls)
+	})
+	t.Run("cleared on reset", func(t *testing.T) {
+		custom := &countingCustomResolve{}
+		res := NewResolvable()
+		assert.Equal(t, expected, resolve(t, res, custom, "upper"))
+		res.Reset()
+		assert.Equal(t, expected, resolve(t, res, custom, "upper"))
+		assert.Equal(t, 4, custom.calls)
+	})
+	t.Run("parallel root fields", func(t *testing.T) {
+		res := NewResolvable()
+		ctx := NewContext(context.Background())
+		ctx.ParallelRootFields = true
+		ctx.LazyCustomNodes = true
+		// walk the root fields with multiple forks, even on a single CPU
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+		item := &Object{Fields: []*Field{{Name: []byte("name"), Value: &CustomNode{CustomResolve: upperCustomResolve{}, Path: []string{"name"}, ID: "upper"}}}}
+		object := &Object{}
+		data := &strings.Builder{}
+		expected := &strings.Builder{}
+		data.WriteString("{")
+		expected.WriteString(`{"data":{`)
+		for i := 0; i < 16; i++ {
+			name := fmt.Sprintf("field%d", i)
+			object.Fields = append(object.Fields, &Field{Name: []byte(name), Value: &Array{Path: []string{name}, Item: item}})
+			if i != 0 {
+				data.WriteString(",")
+				expected.WriteString(",")
+			}
+			fmt.Fprintf(data, `"%s":[`, name)
+			fmt.Fprintf(expected, `"%s":[`, name)
+			for j := 0; j < 64; j++ {
+				if j != 0 {
+					data.WriteString(",")
+					expected.WriteString(",")
+				}
+				fmt.Fprintf(data, `{"name":"user %d"}`, i*64+j)
+				fmt.Fprintf(expected, `{"name":"USER %d"}`, i*64+j)
+			}
+			data.WriteString("]")
+			expected.WriteString("]")
+		}
+		data.WriteString("}")
+		expected.WriteString("}}")
+		// the second resolve reuses the memo of the first one, so the forks must not share it
+		for i := 0; i < 2; i++ {
+			res.Reset()
+			err := res.Init(ctx, []byte(data.String()), ast.OperationTypeQuery)
+			assert.NoError(t, err)
+			out := &bytes.Buffer{}
+			err = res.Resolve(context.Background(), object, nil, out)
+			assert.NoError(t, err)
+			assert.Equal(t, expected.String(), out.String())
+			// the results memoized by the forks are merged
+			assert.Len(t, res.customResults, 16*64)
+		}
+	})
+	t.Run("equals", func(t *testing.T) {
+		node := &CustomNode{CustomResolve: upperCustomResolve{}, Path: []string{"name"}, ID: "upper"}
+		assert.True(t, node.Equals(&CustomNode{CustomResolve: upperCustomResolve{}, Path: []string{"name"}, ID: "upper"}))
+		// the ID scopes the memoized results, so nodes with different IDs aren't equal
+		assert.False(t, node.Equals(&CustomNode{CustomResolve: upperCustomResolve{}, Path: []string{"name"}, ID: "lower"}))
+		assert.False(t, node.Equals(&CustomNode{CustomResolve: upperCustomResolve{}, Path: []string{"name"}}))
+		assert.False(t, node.Equals(&CustomNode{CustomResolve: upperCustomResolve{}, Path: []string{"name"}, ID: "upper", Nullable: true}))
+		assert.False(t, node.Equals(&CustomNode{CustomResolve: upperCustomResolve{}, Path: []string{"title"}, ID: "upper"}))
+	})
+}
+
+// upperCustomResolve is safe for concurrent use, e.g. with Context.ParallelRootFields
+type upperCustomResolve struct{}
+
+func (upperCustomResolve) Resolve(ctx *Context, value []byte) ([]byte, error) {
+	return []byte(`"` + strings.ToUpper(string(value)) + `"`), nil
+}
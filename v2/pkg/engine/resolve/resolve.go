@@ -238,7 +238,7 @@ func (r *Resolver) executeSubscriptionUpdate(ctx *Context, sub *sub, sharedInput
 	defer r.putTools(t)
 	input := make([]byte, len(sharedInput))
 	copy(input, sharedInput)
-	if err := t.resolvable.InitSubscription(ctx, input, sub.resolve.Trigger.PostProcessing); err != nil {
+	if err := t.resolvable.InitSubscriptionFromDataSource(ctx, sub.resolve.Trigger.DataSourceID, input, sub.resolve.Trigger.PostProcessing); err != nil {
 		buf := pool.BytesBuffer.Get()
 		defer pool.BytesBuffer.Put(buf)
 		sub.mux.Lock()
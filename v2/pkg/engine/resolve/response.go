@@ -20,6 +20,8 @@ type GraphQLSubscriptionTrigger struct {
 	Variables      Variables
 	Source         SubscriptionDataSource
 	PostProcessing PostProcessingConfiguration
+	// DataSourceID is the id of the data source of the subscription, e.g. to prefix its errors, see Context.PrefixSubgraphErrors
+	DataSourceID string
 }
 
 type GraphQLResponse struct {
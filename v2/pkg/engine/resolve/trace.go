@@ -32,6 +32,8 @@ type TraceOptions struct {
 	IncludeTraceOutputInResponseExtensions bool
 	// Debug makes trace IDs of fetches predictable for debugging purposes
 	Debug bool
+	// Format selects how the trace output is rendered into the response extensions
+	Format TraceFormat
 }
 
 func (r *TraceOptions) EnableAll() {
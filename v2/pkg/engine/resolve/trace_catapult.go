@@ -0,0 +1,128 @@
+package resolve
+
+// TraceFormat selects how the trace is rendered into the response extensions
+type TraceFormat int
+
+const (
+	// TraceFormatDefault renders the trace as TraceNode tree
+	TraceFormatDefault TraceFormat = iota
+	// TraceFormatCatapultJSON renders the trace as flat list of events in the Trace Event Format,
+	// which can be loaded into chrome://tracing or Perfetto
+	TraceFormatCatapultJSON
+)
+
+const (
+	catapultPhaseComplete = "X"
+	catapultCategoryPhase = "phase"
+	catapultCategoryFetch = "fetch"
+	catapultPhaseThreadID = 0
+)
+
+// CatapultTraceEvent is a complete event of the Trace Event Format
+// Timestamps and durations are in microseconds since the start of the trace
+type CatapultTraceEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"cat"`
+	Phase     string         `json:"ph"`
+	Timestamp float64        `json:"ts"`
+	Duration  float64        `json:"dur"`
+	ProcessID int            `json:"pid"`
+	ThreadID  int            `json:"tid"`
+	Args      map[string]any `json:"args,omitempty"`
+}
+
+// GetCatapultTrace converts the captured timings of a trace into Trace Event Format events
+// Planning phases are rendered on the first thread, each load of a fetch gets its own thread,
+// so that parallel fetches are rendered side by side
+func GetCatapultTrace(trace *TraceNode) []CatapultTraceEvent {
+	events := make([]CatapultTraceEvent, 0, 8)
+	if trace == nil {
+		return events
+	}
+	if trace.Info != nil {
+		events = appendCatapultPhase(events, "parse", trace.Info.ParseStats)
+		events = appendCatapultPhase(events, "normalize", trace.Info.NormalizeStats)
+		events = appendCatapultPhase(events, "validate", trace.Info.ValidateStats)
+		events = appendCatapultPhase(events, "plan", trace.Info.PlannerStats)
+	}
+	return appendCatapultNode(events, trace)
+}
+
+func appendCatapultPhase(events []CatapultTraceEvent, name string, stats PhaseStats) []CatapultTraceEvent {
+	if stats.DurationNano == 0 && stats.DurationSinceStartNano == 0 {
+		return events
+	}
+	return append(events, CatapultTraceEvent{
+		Name:      name,
+		Category:  catapultCategoryPhase,
+		Phase:     catapultPhaseComplete,
+		Timestamp: nanoToMicro(stats.DurationSinceStartNano),
+		Duration:  nanoToMicro(stats.DurationNano),
+		ThreadID:  catapultPhaseThreadID,
+	})
+}
+
+func appendCatapultNode(events []CatapultTraceEvent, node *TraceNode) []CatapultTraceEvent {
+	if node == nil {
+		return events
+	}
+	events = appendCatapultFetch(events, node.Fetch)
+	for _, field := range node.Fields {
+		if field != nil {
+			events = appendCatapultNode(events, field.Value)
+		}
+	}
+	for _, item := range node.Items {
+		events = appendCatapultNode(events, item)
+	}
+	return events
+}
+
+func appendCatapultFetch(events []CatapultTraceEvent, fetch *TraceFetch) []CatapultTraceEvent {
+	if fetch == nil {
+		return events
+	}
+	if fetch.DataSourceLoadTrace != nil {
+		events = appendCatapultLoad(events, fetch, fetch.DataSourceLoadTrace)
+	}
+	for _, load := range fetch.DataSourceLoadTraces {
+		events = appendCatapultLoad(events, fetch, load)
+	}
+	for _, child := range fetch.Fetches {
+		events = appendCatapultFetch(events, child)
+	}
+	return events
+}
+
+func appendCatapultLoad(events []CatapultTraceEvent, fetch *TraceFetch, load *DataSourceLoadTrace) []CatapultTraceEvent {
+	name := fetch.DataSourceID
+	if name == "" {
+		name = string(fetch.Type)
+	}
+	args := map[string]any{
+		"type":         fetch.Type,
+		"load_skipped": load.LoadSkipped,
+	}
+	if fetch.Path != "" {
+		args["path"] = fetch.Path
+	}
+	if load.LoadError != "" {
+		args["error"] = load.LoadError
+	}
+	if load.SingleFlightUsed {
+		args["single_flight_shared_response"] = load.SingleFlightSharedResponse
+	}
+	return append(events, CatapultTraceEvent{
+		Name:      name,
+		Category:  catapultCategoryFetch,
+		Phase:     catapultPhaseComplete,
+		Timestamp: nanoToMicro(load.DurationSinceStartNano),
+		Duration:  nanoToMicro(load.DurationLoadNano),
+		ThreadID:  len(events) + 1,
+		Args:      args,
+	})
+}
+
+func nanoToMicro(nano int64) float64 {
+	return float64(nano) / 1000
+}